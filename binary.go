@@ -0,0 +1,856 @@
+package httpio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//binaryMaxDepth limits the nesting of values such that malicious input cannot exhaust the stack
+const binaryMaxDepth = 10000
+
+//binaryKind is the kind of item read from a self-describing binary format such as MessagePack and CBOR
+type binaryKind int
+
+const (
+	binaryNil binaryKind = iota
+	binaryBool
+	binaryInt  //negative integers, in 'i'
+	binaryUint //non-negative integers, in 'u'
+	binaryFloat
+	binaryString
+	binaryBytes
+	binaryArray //followed by 'n' items, or items until a break if 'n' is negative
+	binaryMap   //followed by 'n' key-value pairs, or pairs until a break if 'n' is negative
+	binaryBreak
+)
+
+var binaryKindNames = [...]string{"nil", "bool", "integer", "integer", "float", "string", "bytes", "array", "map", "break"}
+
+//binaryItem is a single item read from the input, the data of strings and bytes is never shared
+type binaryItem struct {
+	kind binaryKind
+	b    bool
+	i    int64
+	u    uint64
+	f    float64
+	data []byte
+	n    int
+}
+
+//binaryWriter writes the items of a binary format
+type binaryWriter interface {
+	writeNil()
+	writeBool(b bool)
+	writeInt(i int64)
+	writeUint(u uint64)
+	writeFloat(f float64, bits int)
+	writeString(s string)
+	writeBytes(b []byte)
+	writeArray(n int)
+	writeMap(n int)
+}
+
+//binaryReader reads the items of a binary format, it returns io.EOF only if no item was started
+type binaryReader interface {
+	readItem() (binaryItem, error)
+}
+
+//binaryField is a struct field as it is encoded, named and omitted according to its 'json' tag
+type binaryField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	omitZero  bool
+}
+
+var binaryFieldCache sync.Map
+
+//binaryFields returns the encoded fields of struct type 't', fields promoted from embedded structs are
+//shadowed by fields that are less deeply nested
+func binaryFields(t reflect.Type) []binaryField {
+	if fields, ok := binaryFieldCache.Load(t); ok {
+		return fields.([]binaryField)
+	}
+
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+
+	var fields []binaryField
+	seen := map[string]bool{}
+	for level := []embedded{{t, nil}}; len(level) > 0; {
+		var next []embedded
+		names := map[string]bool{}
+		for _, e := range level {
+			for i := 0; i < e.t.NumField(); i++ {
+				f := e.t.Field(i)
+				tag := f.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+
+				index := append(append([]int{}, e.index...), i)
+				name, opts, _ := strings.Cut(tag, ",")
+				if f.Anonymous && name == "" {
+					ft := f.Type
+					if ft.Kind() == reflect.Pointer {
+						ft = ft.Elem()
+					}
+
+					if ft.Kind() == reflect.Struct {
+						next = append(next, embedded{ft, index})
+						continue
+					}
+				}
+
+				if !f.IsExported() {
+					continue
+				}
+
+				if name == "" {
+					name = f.Name
+				}
+
+				if seen[name] || names[name] {
+					continue
+				}
+
+				names[name] = true
+				fields = append(fields, binaryField{
+					name:      name,
+					index:     index,
+					omitEmpty: hasTagOpt(opts, "omitempty"),
+					omitZero:  hasTagOpt(opts, "omitzero"),
+				})
+			}
+		}
+
+		for name := range names {
+			seen[name] = true
+		}
+
+		level = next
+	}
+
+	binaryFieldCache.Store(t, fields)
+	return fields
+}
+
+func hasTagOpt(opts, opt string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+
+	return false
+}
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	zeroerType          = reflect.TypeOf((*interface{ IsZero() bool })(nil)).Elem()
+)
+
+var binaryBuffers = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+//binaryCodec encodes and decodes Go values using a binary format, values are mapped like encoding/json
+//would: structs become maps keyed by their json names and encoding.TextMarshaler values become strings
+type binaryCodec struct {
+	name string
+}
+
+func (c binaryCodec) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("httpio/"+c.name+": "+format, args...)
+}
+
+//binaryEncoder buffers the encoding of each value such that nothing is written if it cannot be encoded
+type binaryEncoder struct {
+	codec  binaryCodec
+	w      io.Writer
+	writer func(buf *bytes.Buffer) binaryWriter
+}
+
+func (e *binaryEncoder) Encode(v interface{}) error {
+	buf := binaryBuffers.Get().(*bytes.Buffer)
+	defer binaryBuffers.Put(buf)
+	buf.Reset()
+
+	err := e.codec.encode(e.writer(buf), reflect.ValueOf(v), 0)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(buf.Bytes())
+	return err
+}
+
+func (c binaryCodec) encode(w binaryWriter, v reflect.Value, depth int) error {
+	if depth > binaryMaxDepth {
+		return c.errorf("exceeded maximum nesting depth of %d", binaryMaxDepth)
+	}
+
+	if !v.IsValid() || ((v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil()) {
+		w.writeNil()
+		return nil
+	}
+
+	if v.Kind() != reflect.Interface {
+		if m, ok := textMarshaler(v); ok {
+			text, err := m.MarshalText()
+			if err != nil {
+				return err
+			}
+
+			w.writeString(string(text))
+			return nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return c.encode(w, v.Elem(), depth+1)
+	case reflect.Bool:
+		w.writeBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i := v.Int(); i < 0 {
+			w.writeInt(i)
+		} else {
+			w.writeUint(uint64(i))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		w.writeUint(v.Uint())
+	case reflect.Float32:
+		w.writeFloat(v.Float(), 32)
+	case reflect.Float64:
+		w.writeFloat(v.Float(), 64)
+	case reflect.String:
+		w.writeString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			w.writeNil()
+			return nil
+		}
+
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			w.writeBytes(v.Bytes())
+			return nil
+		}
+
+		fallthrough
+	case reflect.Array:
+		w.writeArray(v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if err := c.encode(w, v.Index(i), depth+1); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		return c.encodeMap(w, v, depth)
+	case reflect.Struct:
+		return c.encodeStruct(w, v, depth)
+	default:
+		return c.errorf("cannot encode value of type %s", v.Type())
+	}
+
+	return nil
+}
+
+func textMarshaler(v reflect.Value) (encoding.TextMarshaler, bool) {
+	if v.Type().Implements(textMarshalerType) {
+		return v.Interface().(encoding.TextMarshaler), true
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textMarshalerType) {
+		return v.Addr().Interface().(encoding.TextMarshaler), true
+	}
+
+	return nil, false
+}
+
+func (c binaryCodec) encodeMap(w binaryWriter, v reflect.Value, depth int) error {
+	if v.IsNil() {
+		w.writeNil()
+		return nil
+	}
+
+	type entry struct {
+		key, val reflect.Value
+		text     string
+	}
+
+	//keys are sorted by their text, like encoding/json, such that the output is deterministic
+	entries := make([]entry, 0, v.Len())
+	for iter := v.MapRange(); iter.Next(); {
+		k := iter.Key()
+		if k.Kind() == reflect.Interface && !k.IsNil() {
+			k = k.Elem()
+		}
+
+		e := entry{key: k, val: iter.Value()}
+		switch {
+		case k.Kind() == reflect.String:
+			e.text = k.String()
+		case k.Type().Implements(textMarshalerType):
+			text, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return err
+			}
+
+			e.text = string(text)
+		case k.CanInt():
+			e.text = strconv.FormatInt(k.Int(), 10)
+		case k.CanUint():
+			e.text = strconv.FormatUint(k.Uint(), 10)
+		default:
+			return c.errorf("cannot encode map key of type %s", k.Type())
+		}
+
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].text < entries[j].text })
+	w.writeMap(len(entries))
+	for _, e := range entries {
+		switch {
+		case e.key.Kind() != reflect.String && e.key.CanInt():
+			if err := c.encode(w, e.key, depth+1); err != nil {
+				return err
+			}
+		case e.key.Kind() != reflect.String && e.key.CanUint():
+			w.writeUint(e.key.Uint())
+		default:
+			w.writeString(e.text)
+		}
+
+		if err := c.encode(w, e.val, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c binaryCodec) encodeStruct(w binaryWriter, v reflect.Value, depth int) error {
+	fields := binaryFields(v.Type())
+	present := make([]int, 0, len(fields))
+	values := make([]reflect.Value, len(fields))
+	for i, f := range fields {
+		fv, ok := fieldByIndex(v, f.index, false)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) || (f.omitZero && isZeroValue(fv)) {
+			continue
+		}
+
+		present = append(present, i)
+		values[i] = fv
+	}
+
+	w.writeMap(len(present))
+	for _, i := range present {
+		w.writeString(fields[i].name)
+		if err := c.encode(w, values[i], depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//fieldByIndex returns the (nested) field of struct 'v', embedded pointers are allocated if 'alloc' is
+//true, otherwise a nil embedded pointer causes the field to be reported as absent
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+
+	return false
+}
+
+func isZeroValue(v reflect.Value) bool {
+	if v.Type().Implements(zeroerType) {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return true
+		}
+
+		return v.Interface().(interface{ IsZero() bool }).IsZero()
+	}
+
+	return v.IsZero()
+}
+
+//binaryDecoder decodes consecutive values from one reader
+type binaryDecoder struct {
+	codec binaryCodec
+	r     binaryReader
+}
+
+//byteReader returns 'r' as a reader that can read single bytes, buffering it if necessary
+func byteReader(r io.Reader) interface {
+	io.Reader
+	io.ByteReader
+} {
+	if br, ok := r.(interface {
+		io.Reader
+		io.ByteReader
+	}); ok {
+		return br
+	}
+
+	return bufio.NewReader(r)
+}
+
+func (d *binaryDecoder) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return d.codec.errorf("cannot decode into non-pointer %T", v)
+	}
+
+	it, err := d.r.readItem()
+	if err != nil {
+		return err
+	}
+
+	return d.codec.decode(d.r, it, rv.Elem(), 0)
+}
+
+//next reads the next item of a value that was already started
+func (c binaryCodec) next(r binaryReader) (binaryItem, error) {
+	it, err := r.readItem()
+	if err == io.EOF {
+		return it, io.ErrUnexpectedEOF
+	}
+
+	return it, err
+}
+
+func (c binaryCodec) decode(r binaryReader, it binaryItem, v reflect.Value, depth int) error {
+	if depth > binaryMaxDepth {
+		return c.errorf("exceeded maximum nesting depth of %d", binaryMaxDepth)
+	}
+
+	switch it.kind {
+	case binaryBreak:
+		return c.errorf("unexpected break")
+	case binaryNil:
+		switch v.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+			v.SetZero()
+		}
+
+		return nil //like encoding/json, nil leaves other values as is
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return c.decode(r, it, v.Elem(), depth+1)
+	}
+
+	if it.kind == binaryString && v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(it.data)
+	}
+
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		g, err := c.decodeAny(r, it, depth)
+		if err != nil {
+			return err
+		}
+
+		if g == nil {
+			v.SetZero()
+		} else {
+			v.Set(reflect.ValueOf(g))
+		}
+
+		return nil
+	}
+
+	switch it.kind {
+	case binaryBool:
+		if v.Kind() == reflect.Bool {
+			v.SetBool(it.b)
+			return nil
+		}
+	case binaryInt, binaryUint:
+		if ok, err := c.decodeInt(it, v); ok {
+			return err
+		}
+	case binaryFloat:
+		if v.CanFloat() {
+			if v.OverflowFloat(it.f) {
+				return c.errorf("value %v overflows %s", it.f, v.Type())
+			}
+
+			v.SetFloat(it.f)
+			return nil
+		}
+	case binaryString, binaryBytes:
+		switch {
+		case v.Kind() == reflect.String:
+			v.SetString(string(it.data))
+			return nil
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			v.SetBytes(it.data)
+			return nil
+		}
+	case binaryArray:
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			return c.decodeArray(r, it, v, depth)
+		}
+	case binaryMap:
+		switch v.Kind() {
+		case reflect.Map:
+			return c.decodeMap(r, it, v, depth)
+		case reflect.Struct:
+			return c.decodeStruct(r, it, v, depth)
+		}
+	}
+
+	return c.errorf("cannot decode %s into value of type %s", binaryKindNames[it.kind], v.Type())
+}
+
+func (c binaryCodec) decodeInt(it binaryItem, v reflect.Value) (bool, error) {
+	switch {
+	case v.CanInt():
+		i := it.i
+		if it.kind == binaryUint {
+			if it.u > math.MaxInt64 {
+				return true, c.errorf("value %d overflows %s", it.u, v.Type())
+			}
+
+			i = int64(it.u)
+		}
+
+		if v.OverflowInt(i) {
+			return true, c.errorf("value %d overflows %s", i, v.Type())
+		}
+
+		v.SetInt(i)
+	case v.CanUint():
+		if it.kind == binaryInt || v.OverflowUint(it.u) {
+			return true, c.errorf("value %s overflows %s", binaryIntText(it), v.Type())
+		}
+
+		v.SetUint(it.u)
+	case v.CanFloat():
+		if it.kind == binaryInt {
+			v.SetFloat(float64(it.i))
+		} else {
+			v.SetFloat(float64(it.u))
+		}
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func binaryIntText(it binaryItem) string {
+	if it.kind == binaryInt {
+		return strconv.FormatInt(it.i, 10)
+	}
+
+	return strconv.FormatUint(it.u, 10)
+}
+
+//items calls 'fn' for the 'n' items that follow, or for the items until a break if 'n' is negative
+func (c binaryCodec) items(r binaryReader, n int, fn func(i int, it binaryItem) error) error {
+	for i := 0; n < 0 || i < n; i++ {
+		it, err := c.next(r)
+		if err != nil {
+			return err
+		}
+
+		if n < 0 && it.kind == binaryBreak {
+			return nil
+		}
+
+		err = fn(i, it)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c binaryCodec) decodeArray(r binaryReader, it binaryItem, v reflect.Value, depth int) error {
+	if v.Kind() == reflect.Array {
+		err := c.items(r, it.n, func(i int, el binaryItem) error {
+			if i >= v.Len() {
+				_, err := c.decodeAny(r, el, depth+1) //skip, like encoding/json
+				return err
+			}
+
+			return c.decode(r, el, v.Index(i), depth+1)
+		})
+		if err != nil {
+			return err
+		}
+
+		for i := max(it.n, 0); i < v.Len(); i++ {
+			v.Index(i).SetZero()
+		}
+
+		return nil
+	}
+
+	//the size is not trusted for allocation, the input may claim more items than it holds
+	s := reflect.MakeSlice(v.Type(), 0, min(max(it.n, 0), 1024))
+	err := c.items(r, it.n, func(i int, el binaryItem) error {
+		s = reflect.Append(s, reflect.Zero(v.Type().Elem()))
+		return c.decode(r, el, s.Index(i), depth+1)
+	})
+	if err != nil {
+		return err
+	}
+
+	v.Set(s)
+	return nil
+}
+
+func (c binaryCodec) decodeMap(r binaryReader, it binaryItem, v reflect.Value, depth int) error {
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+
+	kt, vt := v.Type().Key(), v.Type().Elem()
+	var key reflect.Value
+	return c.items(r, pairs(it.n), func(i int, el binaryItem) error {
+		if i%2 == 0 {
+			key = reflect.New(kt).Elem()
+			return c.decode(r, el, key, depth+1)
+		}
+
+		val := reflect.New(vt).Elem()
+		err := c.decode(r, el, val, depth+1)
+		if err != nil {
+			return err
+		}
+
+		v.SetMapIndex(key, val)
+		return nil
+	})
+}
+
+//pairs returns the number of items of a map with 'n' pairs
+func pairs(n int) int {
+	if n < 0 {
+		return n
+	}
+
+	return n * 2
+}
+
+func (c binaryCodec) decodeStruct(r binaryReader, it binaryItem, v reflect.Value, depth int) error {
+	fields := binaryFields(v.Type())
+	var field *binaryField
+	return c.items(r, pairs(it.n), func(i int, el binaryItem) error {
+		if i%2 == 0 {
+			if el.kind != binaryString && el.kind != binaryBytes {
+				return c.errorf("cannot decode %s key into field of %s", binaryKindNames[el.kind], v.Type())
+			}
+
+			field = findField(fields, string(el.data))
+			return nil
+		}
+
+		if field == nil {
+			_, err := c.decodeAny(r, el, depth+1) //unknown fields are skipped
+			return err
+		}
+
+		fv, ok := fieldByIndex(v, field.index, true)
+		if !ok {
+			return c.errorf("cannot set field '%s' of %s, it is promoted through an unexported pointer", field.name, v.Type())
+		}
+
+		err := c.decode(r, el, fv, depth+1)
+		if err != nil {
+			var cerr *fieldErr
+			if errors.As(err, &cerr) {
+				cerr.path = field.name + "." + cerr.path
+				return err
+			}
+
+			return &fieldErr{field.name, err}
+		}
+
+		return nil
+	})
+}
+
+//fieldErr reports which field failed to decode
+type fieldErr struct {
+	path string
+	err  error
+}
+
+func (e *fieldErr) Error() string { return e.err.Error() + " (field '" + e.path + "')" }
+
+func (e *fieldErr) Unwrap() error { return e.err }
+
+//findField matches a key to a field like encoding/json does: exactly or else case-insensitively
+func findField(fields []binaryField, key string) *binaryField {
+	var fold *binaryField
+	for i := range fields {
+		if fields[i].name == key {
+			return &fields[i]
+		}
+
+		if fold == nil && strings.EqualFold(fields[i].name, key) {
+			fold = &fields[i]
+		}
+	}
+
+	return fold
+}
+
+//decodeAny decodes an item into the Go value that represents it best, maps with only string keys become
+//map[string]interface{}, integers become int64 unless they only fit an uint64
+func (c binaryCodec) decodeAny(r binaryReader, it binaryItem, depth int) (interface{}, error) {
+	if depth > binaryMaxDepth {
+		return nil, c.errorf("exceeded maximum nesting depth of %d", binaryMaxDepth)
+	}
+
+	switch it.kind {
+	case binaryBool:
+		return it.b, nil
+	case binaryInt:
+		return it.i, nil
+	case binaryUint:
+		if it.u > math.MaxInt64 {
+			return it.u, nil
+		}
+
+		return int64(it.u), nil
+	case binaryFloat:
+		return it.f, nil
+	case binaryString:
+		return string(it.data), nil
+	case binaryBytes:
+		return it.data, nil
+	case binaryArray:
+		arr := make([]interface{}, 0, min(max(it.n, 0), 1024))
+		err := c.items(r, it.n, func(i int, el binaryItem) error {
+			v, err := c.decodeAny(r, el, depth+1)
+			arr = append(arr, v)
+			return err
+		})
+
+		return arr, err
+	case binaryMap:
+		var keys, vals []interface{}
+		strKeys := true
+		err := c.items(r, pairs(it.n), func(i int, el binaryItem) error {
+			v, err := c.decodeAny(r, el, depth+1)
+			if err != nil {
+				return err
+			}
+
+			if i%2 == 1 {
+				vals = append(vals, v)
+				return nil
+			}
+
+			switch v.(type) {
+			case string:
+			case []interface{}, map[string]interface{}, map[interface{}]interface{}, []byte:
+				return c.errorf("cannot decode %s map key into interface{}", binaryKindNames[el.kind])
+			default:
+				strKeys = false
+			}
+
+			keys = append(keys, v)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if strKeys {
+			m := make(map[string]interface{}, len(keys))
+			for i, k := range keys {
+				m[k.(string)] = vals[i]
+			}
+
+			return m, nil
+		}
+
+		m := make(map[interface{}]interface{}, len(keys))
+		for i, k := range keys {
+			m[k] = vals[i]
+		}
+
+		return m, nil
+	case binaryBreak:
+		return nil, c.errorf("unexpected break")
+	}
+
+	return nil, nil
+}
+
+//readBinaryData reads 'n' bytes, the size is not trusted for allocation as the input may claim more data
+//than it holds
+func readBinaryData(r io.Reader, n uint64) ([]byte, error) {
+	const chunk = 64 * 1024
+	if n <= chunk {
+		data := make([]byte, n)
+		_, err := io.ReadFull(r, data)
+		return data, unexpectedEOF(err)
+	}
+
+	if n > math.MaxInt64 {
+		return nil, fmt.Errorf("data of %d bytes is too large", n)
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, chunk))
+	_, err := io.CopyN(buf, r, int64(n))
+	return buf.Bytes(), unexpectedEOF(err)
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
@@ -0,0 +1,179 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+type binaryTestBase struct {
+	ID      string `json:"id"`
+	Comment string `json:"comment"`
+}
+
+type binaryTestItem struct {
+	binaryTestBase
+	Comment  string          `json:"comment,omitempty"`
+	Name     string          `json:"name"`
+	Age      int             `json:"age"`
+	Score    float64         `json:"score"`
+	Data     []byte          `json:"data"`
+	Tags     []string        `json:"tags,omitempty"`
+	Labels   map[string]int  `json:"labels"`
+	Parent   *binaryTestItem `json:"parent,omitempty"`
+	Created  time.Time       `json:"created"`
+	Deleted  time.Time       `json:"deleted,omitzero"`
+	Any      interface{}     `json:"any"`
+	Secret   string          `json:"-"`
+	NoTag    bool
+	Children []*binaryTestItem `json:"children,omitempty"`
+}
+
+type binaryCodec interface {
+	httpio.EncoderFactory
+	httpio.DecoderFactory
+}
+
+func TestBinaryCodecs(t *testing.T) {
+	in := &binaryTestItem{
+		binaryTestBase: binaryTestBase{ID: "a1", Comment: "shadowed"},
+		Comment:        "hi",
+		Name:           "foo",
+		Age:            -42,
+		Score:          1.5,
+		Data:           []byte{0, 1, 2},
+		Labels:         map[string]int{"b": 2, "a": 1},
+		Parent:         &binaryTestItem{Name: "bar", Labels: map[string]int{}},
+		Created:        time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Any:            map[string]interface{}{"x": []interface{}{int64(1), "y", nil, true, 2.5}},
+		Secret:         "not encoded",
+		NoTag:          true,
+	}
+
+	for _, c := range []binaryCodec{&httpio.MsgPack{}, &httpio.CBOR{}} {
+		t.Run(c.MimeType(), func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			enc := c.Encoder(buf)
+			if err := enc.Encode(in); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := enc.Encode(map[int]string{2: "b", 1: "a"}); err != nil {
+				t.Fatal(err)
+			}
+
+			var out binaryTestItem
+			dec := c.Decoder(buf)
+			if err := dec.Decode(&out); err != nil {
+				t.Fatal(err)
+			}
+
+			exp := *in
+			exp.Secret = ""
+			exp.binaryTestBase.Comment = "" //shadowed, like encoding/json
+			if !reflect.DeepEqual(&out, &exp) {
+				t.Fatalf("expected %+v, got: %+v", &exp, &out)
+			}
+
+			var m map[int]string
+			if err := dec.Decode(&m); err != nil || !reflect.DeepEqual(m, map[int]string{1: "a", 2: "b"}) {
+				t.Fatalf("unexpected second value: %v %v", m, err)
+			}
+
+			if err := dec.Decode(&m); err != io.EOF {
+				t.Fatalf("expected EOF, got: %v", err)
+			}
+
+			var generic interface{}
+			buf.Reset()
+			_ = c.Encoder(buf).Encode(binaryTestBase{ID: "a1"})
+			if err := c.Decoder(buf).Decode(&generic); err != nil ||
+				!reflect.DeepEqual(generic, map[string]interface{}{"id": "a1", "comment": ""}) {
+				t.Fatalf("unexpected generic value: %#v %v", generic, err)
+			}
+		})
+	}
+}
+
+func TestBinaryErrors(t *testing.T) {
+	for _, c := range []binaryCodec{&httpio.MsgPack{}, &httpio.CBOR{}} {
+		t.Run(c.MimeType(), func(t *testing.T) {
+			name := strings.TrimPrefix(c.MimeType(), "application/")
+
+			buf := bytes.NewBuffer(nil)
+			_ = c.Encoder(buf).Encode(map[string]interface{}{"parent": map[string]interface{}{"age": "old"}})
+			err := c.Decoder(bytes.NewReader(buf.Bytes())).Decode(&binaryTestItem{})
+			exp := "httpio/" + name + ": cannot decode string into value of type int (field 'parent.age')"
+			if err == nil || err.Error() != exp {
+				t.Fatalf("expected error '%s', got: %v", exp, err)
+			}
+
+			err = c.Decoder(bytes.NewReader(buf.Bytes()[:buf.Len()-2])).Decode(&binaryTestItem{})
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Fatalf("expected unexpected EOF, got: %v", err)
+			}
+
+			buf.Reset()
+			_ = c.Encoder(buf).Encode(300)
+			var small int8
+			err = c.Decoder(buf).Decode(&small)
+			if exp = "httpio/" + name + ": value 300 overflows int8"; err == nil || err.Error() != exp {
+				t.Fatalf("expected error '%s', got: %v", exp, err)
+			}
+
+			buf.Reset()
+			err = c.Encoder(buf).Encode(map[string]interface{}{"fn": func() {}})
+			if exp = "httpio/" + name + ": cannot encode value of type func()"; err == nil || err.Error() != exp || buf.Len() != 0 {
+				t.Fatalf("expected error '%s' and no output, got: %v %q", exp, err, buf.Bytes())
+			}
+
+			err = c.Decoder(buf).Decode(binaryTestItem{})
+			if exp = "httpio/" + name + ": cannot decode into non-pointer httpio_test.binaryTestItem"; err == nil || err.Error() != exp {
+				t.Fatalf("expected error '%s', got: %v", exp, err)
+			}
+		})
+	}
+}
+
+func TestBinaryHTTP(t *testing.T) {
+	for _, c := range []binaryCodec{&httpio.MsgPack{}, &httpio.CBOR{}} {
+		t.Run(c.MimeType(), func(t *testing.T) {
+			e := httpio.NewEgress(&httpio.JSON{}, c)
+			i := httpio.NewIngress(e, &httpio.JSON{}, c)
+			var contentType string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType = r.Header.Get("Content-Type")
+				in := &binaryTestItem{}
+				if render, ok := i.Handle(w, r, in); ok {
+					in.Name += "!"
+					render(in, nil)
+				}
+			}))
+
+			defer srv.Close()
+			client, err := httpio.NewClient(srv.Client(), srv.URL, c, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			out := &binaryTestItem{}
+			meta, err := client.Do(context.Background(), http.MethodPost, "/", http.Header{"Accept": {c.MimeType()}}, &binaryTestItem{Name: "foo"}, out)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if contentType != c.MimeType() || meta.Header.Get("Content-Type") != c.MimeType() || out.Name != "foo!" {
+				t.Fatalf("unexpected exchange: %s %s %+v", contentType, meta.Header.Get("Content-Type"), out)
+			}
+		})
+	}
+}
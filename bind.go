@@ -0,0 +1,158 @@
+package httpio
+
+import (
+	"encoding"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+//ParamTags are the struct tags that bind request parameters into the input, in order of precedence
+var ParamTags = []string{"path", "query", "header"}
+
+//IsParamField reports whether the struct field is bound from the request's parameters or headers rather
+//than decoded from the body
+func IsParamField(f reflect.StructField) bool {
+	for _, tag := range ParamTags {
+		if f.Tag.Get(tag) != "" {
+			return true
+		}
+	}
+
+	return f.Tag.Get("sf") != "" //bound by the StructuredHeaders transware
+}
+
+//BindParams is an Ingress transware that binds path values, query parameters and headers into fields of
+//the input that are tagged with their name, e.g: `path:"id"`, `query:"page"` or `header:"X-Request-Id"`.
+//Path values are only available when the request was routed by a http.ServeMux pattern. Values that
+//cannot be converted to the field's type are reported as decode errors.
+func BindParams(next Transformer) Transformer {
+	return TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+		err := bindParams(a, r)
+		if err != nil {
+			return err
+		}
+
+		return next.Transform(a, r, w)
+	})
+}
+
+func bindParams(a interface{}, r *http.Request) error {
+	v := reflect.ValueOf(a)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if !v.Field(i).CanSet() {
+			continue
+		}
+
+		for _, tag := range ParamTags {
+			name := f.Tag.Get(tag)
+			if name == "" {
+				continue
+			}
+
+			var vals []string
+			switch tag {
+			case "path":
+				if pv := r.PathValue(name); pv != "" {
+					vals = []string{pv}
+				}
+			case "query":
+				vals = r.URL.Query()[name]
+			case "header":
+				vals = r.Header.Values(name)
+			}
+
+			if len(vals) < 1 {
+				continue
+			}
+
+			err := setField(v.Field(i), vals)
+			if err != nil {
+				return decodeErr{fmt.Errorf("httpio/bind: invalid %s parameter '%s': %v", tag, name, err)}
+			}
+
+			break //the first tag with a value takes precedence
+		}
+	}
+
+	return nil
+}
+
+//setField converts the string value(s) to the type of field 'fv', slices take all values and other
+//types only the first
+func setField(fv reflect.Value, vals []string) error {
+	if fv.Kind() == reflect.Ptr {
+		nv := reflect.New(fv.Type().Elem())
+		err := setField(nv.Elem(), vals)
+		if err != nil {
+			return err
+		}
+
+		fv.Set(nv)
+		return nil
+	}
+
+	if tu, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return tu.UnmarshalText([]byte(vals[0]))
+	}
+
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		s := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			err := setField(s.Index(i), []string{val})
+			if err != nil {
+				return err
+			}
+		}
+
+		fv.Set(s)
+		return nil
+	}
+
+	s := vals[0]
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Slice:
+		fv.SetBytes([]byte(s))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		fv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+
+	return nil
+}
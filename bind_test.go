@@ -0,0 +1,100 @@
+package httpio_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+type bindTestInput struct {
+	ID      int64     `path:"id"`
+	Page    *uint     `query:"page"`
+	Tags    []string  `query:"tag"`
+	Exact   bool      `query:"exact"`
+	Ratio   float32   `query:"ratio"`
+	Since   time.Time `query:"since"`
+	Request string    `header:"X-Request-Id"`
+	Ref     string    `query:"ref" header:"X-Ref"`
+	Name    string    `json:"name"`
+}
+
+func TestBindParams(t *testing.T) {
+	page := uint(3)
+	for _, c := range []struct {
+		Name     string
+		Target   string
+		Header   http.Header
+		ExpInput *bindTestInput
+		ExpErr   string
+	}{
+		{
+			Name:     "no params",
+			Target:   "/items/",
+			ExpInput: &bindTestInput{},
+		},
+		{
+			Name:   "all params",
+			Target: "/items/42?page=3&tag=a&tag=b&exact=true&ratio=0.5&since=2020-01-02T03:04:05Z",
+			Header: http.Header{"X-Request-Id": {"abc"}},
+			ExpInput: &bindTestInput{
+				ID: 42, Page: &page, Tags: []string{"a", "b"}, Exact: true, Ratio: 0.5,
+				Since: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), Request: "abc",
+			},
+		},
+		{
+			Name:     "query takes precedence over header",
+			Target:   "/items/1?ref=q",
+			Header:   http.Header{"X-Ref": {"h"}},
+			ExpInput: &bindTestInput{ID: 1, Ref: "q"},
+		},
+		{
+			Name:   "invalid path value",
+			Target: "/items/abc",
+			ExpErr: "httpio/bind: invalid path parameter 'id': strconv.ParseInt: parsing \"abc\": invalid syntax",
+		},
+		{
+			Name:   "overflow",
+			Target: "/items/1?page=-1",
+			ExpErr: "httpio/bind: invalid query parameter 'page': strconv.ParseUint: parsing \"-1\": invalid syntax",
+		},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			var err error
+			in := &bindTestInput{}
+			mux := http.NewServeMux()
+			mux.HandleFunc("/items/{id...}", func(w http.ResponseWriter, r *http.Request) {
+				chain := httpio.Chain(httpio.TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+					return nil
+				}), httpio.BindParams)
+				err = chain.Transform(in, r, w)
+			})
+
+			r, _ := http.NewRequest(http.MethodGet, c.Target, nil)
+			r.Header = c.Header
+			if r.Header == nil {
+				r.Header = http.Header{}
+			}
+
+			mux.ServeHTTP(httptest.NewRecorder(), r)
+			if c.ExpErr != "" {
+				if err == nil || err.Error() != c.ExpErr || !httpio.IsDecodeErr(err) {
+					t.Fatalf("expected decode error '%s', got: %v", c.ExpErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(in, c.ExpInput) {
+				t.Fatalf("expected input %+v, got: %+v", c.ExpInput, in)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package httpio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+var (
+	//MediaTypeCBOR identifies CBOR content
+	MediaTypeCBOR = "application/cbor"
+)
+
+//CBOR allows encoding and decoding into the Concise Binary Object Representation (RFC 8949). Values are
+//mapped as encoding/json would map them: structs are encoded as maps keyed by the names in their 'json'
+//tags, []byte as byte strings and encoding.TextMarshaler implementations (such as time.Time) as text
+//strings. Tags are ignored when decoding, the tagged item is decoded as is
+type CBOR struct{}
+
+//MimeType will report the EncodingMimeType
+func (e *CBOR) MimeType() string { return MediaTypeCBOR }
+
+//ContentType is sent without a charset as the format is binary
+func (e *CBOR) ContentType() string { return MediaTypeCBOR }
+
+//Encoder will create encoders
+func (e *CBOR) Encoder(w io.Writer) Encoder {
+	return &binaryEncoder{binaryCodec{"cbor"}, w, func(buf *bytes.Buffer) binaryWriter { return cborWriter{buf} }}
+}
+
+//Decoder will create decoders
+func (e *CBOR) Decoder(r io.Reader) Decoder {
+	return &binaryDecoder{binaryCodec{"cbor"}, &cborReader{byteReader(r)}}
+}
+
+//major types of data items
+const (
+	cborUint byte = iota << 5
+	cborNegInt
+	cborBytes
+	cborText
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+)
+
+type cborWriter struct{ *bytes.Buffer }
+
+//writeHead writes the initial byte of major type 'major' with argument 'n' in its shortest form
+func (w cborWriter) writeHead(major byte, n uint64) {
+	switch {
+	case n < 24:
+		w.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		w.Write([]byte{major | 24, byte(n)})
+	case n <= math.MaxUint16:
+		w.WriteByte(major | 25)
+		w.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n <= math.MaxUint32:
+		w.WriteByte(major | 26)
+		w.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		w.WriteByte(major | 27)
+		w.Write(binary.BigEndian.AppendUint64(nil, n))
+	}
+}
+
+func (w cborWriter) writeNil() { w.WriteByte(cborSimple | 22) }
+
+func (w cborWriter) writeBool(b bool) {
+	if b {
+		w.WriteByte(cborSimple | 21)
+	} else {
+		w.WriteByte(cborSimple | 20)
+	}
+}
+
+func (w cborWriter) writeInt(i int64) {
+	if i >= 0 {
+		w.writeHead(cborUint, uint64(i))
+		return
+	}
+
+	w.writeHead(cborNegInt, uint64(-1-i))
+}
+
+func (w cborWriter) writeUint(u uint64) { w.writeHead(cborUint, u) }
+
+func (w cborWriter) writeFloat(f float64, bits int) {
+	if bits == 32 {
+		w.WriteByte(cborSimple | 26)
+		w.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(float32(f))))
+		return
+	}
+
+	w.WriteByte(cborSimple | 27)
+	w.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+}
+
+func (w cborWriter) writeString(s string) {
+	w.writeHead(cborText, uint64(len(s)))
+	w.WriteString(s)
+}
+
+func (w cborWriter) writeBytes(b []byte) {
+	w.writeHead(cborBytes, uint64(len(b)))
+	w.Write(b)
+}
+
+func (w cborWriter) writeArray(n int) { w.writeHead(cborArray, uint64(n)) }
+
+func (w cborWriter) writeMap(n int) { w.writeHead(cborMap, uint64(n)) }
+
+type cborReader struct {
+	r interface {
+		io.Reader
+		io.ByteReader
+	}
+}
+
+//head reads the initial byte and argument of the next data item, indefinite is true if its additional
+//information signals an indefinite length (or a break for major type 7)
+func (r *cborReader) head() (major, info byte, arg uint64, indefinite bool, err error) {
+	b, err := r.r.ReadByte()
+	if err != nil {
+		return 0, 0, 0, false, err
+	}
+
+	major, info = b&0xe0, b&0x1f
+	switch {
+	case info < 24:
+		return major, info, uint64(info), false, nil
+	case info <= 27:
+		var buf [8]byte
+		size := 1 << (info - 24)
+		_, err = io.ReadFull(r.r, buf[8-size:])
+		return major, info, binary.BigEndian.Uint64(buf[:]), false, unexpectedEOF(err)
+	case info == 31:
+		return major, info, 0, true, nil
+	}
+
+	return 0, 0, 0, false, errCBOR("invalid additional information %d", info)
+}
+
+func (r *cborReader) readItem() (it binaryItem, err error) {
+	major, info, arg, indefinite, err := r.head()
+	for err == nil && major == cborTag {
+		major, info, arg, indefinite, err = r.head() //tags only annotate the item that follows
+		err = unexpectedEOF(err)
+	}
+
+	if err != nil {
+		return it, err
+	}
+
+	if indefinite {
+		switch major {
+		case cborBytes, cborText:
+			return r.chunks(major)
+		case cborArray:
+			return binaryItem{kind: binaryArray, n: -1}, nil
+		case cborMap:
+			return binaryItem{kind: binaryMap, n: -1}, nil
+		case cborSimple:
+			return binaryItem{kind: binaryBreak}, nil
+		}
+
+		return it, errCBOR("invalid indefinite length for major type %d", major>>5)
+	}
+
+	switch major {
+	case cborUint:
+		return binaryItem{kind: binaryUint, u: arg}, nil
+	case cborNegInt:
+		if arg > math.MaxInt64 {
+			return it, errCBOR("negative integer -1-%d overflows int64", arg)
+		}
+
+		return binaryItem{kind: binaryInt, i: -1 - int64(arg)}, nil
+	case cborBytes:
+		data, err := readBinaryData(r.r, arg)
+		return binaryItem{kind: binaryBytes, data: data}, err
+	case cborText:
+		data, err := readBinaryData(r.r, arg)
+		return binaryItem{kind: binaryString, data: data}, err
+	case cborArray, cborMap:
+		kind := binaryArray
+		if major == cborMap {
+			kind = binaryMap
+		}
+
+		if arg > math.MaxInt32 {
+			return it, errCBOR("%s of %d items is too large", binaryKindNames[kind], arg)
+		}
+
+		return binaryItem{kind: kind, n: int(arg)}, nil
+	}
+
+	switch info {
+	case 20, 21:
+		return binaryItem{kind: binaryBool, b: info == 21}, nil
+	case 22, 23: //null and undefined
+		return binaryItem{kind: binaryNil}, nil
+	case 25:
+		return binaryItem{kind: binaryFloat, f: float16(uint16(arg))}, nil
+	case 26:
+		return binaryItem{kind: binaryFloat, f: float64(math.Float32frombits(uint32(arg)))}, nil
+	case 27:
+		return binaryItem{kind: binaryFloat, f: math.Float64frombits(arg)}, nil
+	}
+
+	return it, errCBOR("unsupported simple value %d", arg)
+}
+
+//chunks reads an indefinite length string as the concatenation of its definite length chunks
+func (r *cborReader) chunks(major byte) (binaryItem, error) {
+	kind := binaryBytes
+	if major == cborText {
+		kind = binaryString
+	}
+
+	var data []byte
+	for {
+		cmajor, _, arg, indefinite, err := r.head()
+		if err != nil {
+			return binaryItem{}, unexpectedEOF(err)
+		}
+
+		if indefinite && cmajor == cborSimple {
+			return binaryItem{kind: kind, data: data}, nil
+		}
+
+		if indefinite || cmajor != major {
+			return binaryItem{}, errCBOR("invalid chunk of indefinite length %s", binaryKindNames[kind])
+		}
+
+		chunk, err := readBinaryData(r.r, arg)
+		if err != nil {
+			return binaryItem{}, err
+		}
+
+		data = append(data, chunk...)
+	}
+}
+
+//float16 converts the bits of a IEEE 754 half-precision float
+func float16(h uint16) float64 {
+	exp, mant := int(h>>10)&0x1f, float64(h&0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1f:
+		f = math.Inf(1)
+		if mant != 0 {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+
+	if h&0x8000 != 0 {
+		return -f
+	}
+
+	return f
+}
+
+func errCBOR(format string, args ...interface{}) error {
+	return binaryCodec{"cbor"}.errorf(format, args...)
+}
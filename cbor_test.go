@@ -0,0 +1,96 @@
+package httpio_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+func TestCBOR(t *testing.T) {
+	//examples from RFC 8949, Appendix A
+	for _, c := range []struct {
+		Value  interface{}
+		Hex    string
+		Decode bool //only decode, the value is not encoded into the same bytes
+	}{
+		{Value: int64(0), Hex: "00"},
+		{Value: int64(23), Hex: "17"},
+		{Value: int64(24), Hex: "1818"},
+		{Value: int64(100), Hex: "1864"},
+		{Value: int64(1000), Hex: "1903e8"},
+		{Value: int64(1000000), Hex: "1a000f4240"},
+		{Value: int64(1000000000000), Hex: "1b000000e8d4a51000"},
+		{Value: uint64(math.MaxUint64), Hex: "1bffffffffffffffff"},
+		{Value: int64(-1), Hex: "20"},
+		{Value: int64(-100), Hex: "3863"},
+		{Value: int64(-1000), Hex: "3903e7"},
+		{Value: 1.1, Hex: "fb3ff199999999999a"},
+		{Value: 1.5, Hex: "f93e00", Decode: true},
+		{Value: 65504.0, Hex: "f97bff", Decode: true},
+		{Value: 5.960464477539063e-8, Hex: "f90001", Decode: true},
+		{Value: -4.0, Hex: "f9c400", Decode: true},
+		{Value: math.Inf(1), Hex: "f97c00", Decode: true},
+		{Value: 100000.0, Hex: "fa47c35000", Decode: true},
+		{Value: false, Hex: "f4"},
+		{Value: true, Hex: "f5"},
+		{Value: nil, Hex: "f6"},
+		{Value: nil, Hex: "f7", Decode: true},
+		{Value: "2013-03-21T20:04:00Z", Hex: "c074323031332d30332d32315432303a30343a30305a", Decode: true},
+		{Value: []byte{1, 2, 3, 4}, Hex: "4401020304"},
+		{Value: "", Hex: "60"},
+		{Value: "IETF", Hex: "6449455446"},
+		{Value: "ü", Hex: "62c3bc"},
+		{Value: []interface{}{}, Hex: "80"},
+		{Value: []interface{}{int64(1), []interface{}{int64(2), int64(3)}}, Hex: "8201820203"},
+		{Value: map[string]interface{}{"a": int64(1), "b": []interface{}{int64(2), int64(3)}}, Hex: "a26161016162820203"},
+		{Value: map[interface{}]interface{}{int64(1): int64(2), int64(3): int64(4)}, Hex: "a201020304"},
+		{Value: "streaming", Hex: "7f657374726561646d696e67ff", Decode: true},
+		{Value: []byte{1, 2, 3, 4, 5}, Hex: "5f42010243030405ff", Decode: true},
+		{Value: []interface{}{int64(1), []interface{}{int64(2), int64(3)}}, Hex: "9f01820203ff", Decode: true},
+		{Value: map[string]interface{}{"a": int64(1), "b": []interface{}{int64(2), int64(3)}}, Hex: "bf61610161629f0203ffff", Decode: true},
+	} {
+		t.Run(c.Hex, func(t *testing.T) {
+			data, _ := hex.DecodeString(c.Hex)
+			if !c.Decode {
+				buf := bytes.NewBuffer(nil)
+				err := (&httpio.CBOR{}).Encoder(buf).Encode(c.Value)
+				if err != nil || hex.EncodeToString(buf.Bytes()) != c.Hex {
+					t.Fatalf("expected %s, got: %x %v", c.Hex, buf.Bytes(), err)
+				}
+			}
+
+			var v interface{}
+			err := (&httpio.CBOR{}).Decoder(bytes.NewReader(data)).Decode(&v)
+			if err != nil || !reflect.DeepEqual(v, c.Value) {
+				t.Fatalf("expected %#v, got: %#v %v", c.Value, v, err)
+			}
+		})
+	}
+
+	var tm time.Time
+	data, _ := hex.DecodeString("c074323031332d30332d32315432303a30343a30305a")
+	err := (&httpio.CBOR{}).Decoder(bytes.NewReader(data)).Decode(&tm)
+	if err != nil || !tm.Equal(time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected tagged time: %v %v", tm, err)
+	}
+
+	for hexIn, exp := range map[string]string{
+		"1c":                 "httpio/cbor: invalid additional information 28",
+		"5f6161ff":           "httpio/cbor: invalid chunk of indefinite length bytes",
+		"f820":               "httpio/cbor: unsupported simple value 32",
+		"ff":                 "httpio/cbor: unexpected break",
+		"3bffffffffffffffff": "httpio/cbor: negative integer -1-18446744073709551615 overflows int64",
+	} {
+		data, _ := hex.DecodeString(hexIn)
+		var v interface{}
+		err := (&httpio.CBOR{}).Decoder(bytes.NewReader(data)).Decode(&v)
+		if err == nil || err.Error() != exp {
+			t.Fatalf("expected error '%s' for %s, got: %v", exp, hexIn, err)
+		}
+	}
+}
@@ -25,5 +25,5 @@ func Chain(base Transformer, others ...Transware) Transformer {
 		return base
 	}
 
-	return others[0](Chain(base, others[1:cap(others)]...))
+	return others[0](Chain(base, others[1:]...))
 }
@@ -0,0 +1,31 @@
+package httpio_test
+
+import (
+	"net/http"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+func TestChain(t *testing.T) {
+	var order []string
+	ware := func(name string) httpio.Transware {
+		return func(next httpio.Transformer) httpio.Transformer {
+			return httpio.TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+				order = append(order, name)
+				return next.Transform(a, r, w)
+			})
+		}
+	}
+
+	wares := []httpio.Transware{ware("a"), ware("b"), ware("stale")}
+	base := httpio.TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+		order = append(order, "base")
+		return nil
+	})
+
+	err := httpio.Chain(base, wares[:2]...).Transform(nil, nil, nil) //capacity beyond the length is ignored
+	if err != nil || len(order) != 3 || order[0] != "a" || order[1] != "b" || order[2] != "base" {
+		t.Fatalf("expected a, b and base, got: %v %v", order, err)
+	}
+}
@@ -3,14 +3,19 @@ package httpio
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"mime"
 	"net/http"
 	"net/url"
+
+	"github.com/advanderveer/go-httpio/header"
 )
 
 //ErrReceiver is used by the client to determine if the response holds an error value and specify the
-//struct to decode it into. If the reponse holds an error this function should return a non-nil value
+//struct to decode it into. If the reponse holds an error this function should return a non-nil value.
+//The body of non-2xx responses is buffered before the receiver is called so it can be peeked into.
 type ErrReceiver func(ctx context.Context, resp *http.Response) error
 
 //Client is a client that uses encoding stacks to facilitate communication
@@ -19,8 +24,22 @@ type Client struct {
 	base   *url.URL
 	encs   EncoderList
 	decs   DecoderList
+	index  mediaIndex
 
+	//ErrReceiver determines which responses hold an error, if it is nil the Errors registry is used
 	ErrReceiver ErrReceiver
+	Errors      *ErrRegistry
+}
+
+//receiveErr calls the ErrReceiver, or the Errors registry if none is set, as they are at the time of the request
+func (c *Client) receiveErr(ctx context.Context, resp *http.Response) error {
+	if c.ErrReceiver != nil {
+		return c.ErrReceiver(ctx, resp)
+	} else if c.Errors != nil {
+		return c.Errors.Receive(ctx, resp)
+	}
+
+	return nil
 }
 
 type stdClientErr struct {
@@ -39,17 +58,10 @@ func NewClient(hclient *http.Client, base string, def EncoderFactory, defd Decod
 		client: hclient,
 		encs:   encs,
 		decs:   decs,
-
-		//@TODO move this to some Std implementation
-		ErrReceiver: func(ctx context.Context, resp *http.Response) error {
-			if resp.Header.Get("X-Has-Handling-Error") != "" {
-				errOut := &stdClientErr{} //we will decode into the general Err struct instead
-				return errOut
-			}
-
-			return nil
-		},
+		index:  decs.index(),
+		Errors: NewErrRegistry(),
 	}
+
 	c.base, err = url.Parse(base)
 	if err != nil {
 		return nil, err
@@ -60,25 +72,39 @@ func NewClient(hclient *http.Client, base string, def EncoderFactory, defd Decod
 
 //Request output 'out' using method 'm' on path 'p' using headers 'hdr' and input 'in' encoded as
 //the default encodinbg scheme from the stack. The "Content-Type" header will be set regardless of
-//what is provided as an argument
+//what is provided as an argument. Inputs that implement PatchDocument are sent as JSON with their own
+//media type instead
 func (c *Client) Request(ctx context.Context, m, p string, hdr http.Header, in, out interface{}) (err error) {
+	_, err = c.Do(ctx, m, p, hdr, in, out)
+	return err
+}
+
+//Do works like Request but also returns the response metadata such as the status code, headers and
+//trailers. The metadata is returned whenever a response was received, also if it held an error.
+func (c *Client) Do(ctx context.Context, m, p string, hdr http.Header, in, out interface{}) (meta *ResponseMeta, err error) {
 	def := c.encs.Default()
 
 	body := bytes.NewBuffer(nil)
-	enc := def.Encoder(body)
-	err = enc.Encode(in)
+	ct := def.MimeType()
+	if pd, ok := in.(PatchDocument); ok {
+		ct = pd.PatchMediaType()
+		err = json.NewEncoder(body).Encode(in)
+	} else {
+		err = def.Encoder(body).Encode(in)
+	}
+
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	ref, err := url.Parse(p)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	req, err := http.NewRequest(m, c.base.ResolveReference(ref).String(), body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if hdr != nil {
@@ -86,35 +112,113 @@ func (c *Client) Request(ctx context.Context, m, p string, hdr http.Header, in,
 	}
 
 	req = req.WithContext(ctx)
-	req.Header.Set("Content-Type", def.MimeType())
+	req.Header.Set("Content-Type", ct)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return err
-	}
-
-	mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
-	decf := c.decs.Find(mt)
-	if decf == nil {
-		return fmt.Errorf("httpio/client: no encoder for media type '%s'", mt)
+		return nil, err
 	}
 
-	dec := decf.Decoder(resp.Body)
 	defer resp.Body.Close()
-	errOut := c.ErrReceiver(ctx, resp)
-	if errOut != nil {
-		err = dec.Decode(errOut)
+	meta = &ResponseMeta{StatusCode: resp.StatusCode, Header: resp.Header, Trailer: resp.Trailer, resp: resp}
+	if !isSuccess(resp.StatusCode) {
+		_, err = bufferErrBody(resp)
 		if err != nil {
-			return err
+			return meta, err
 		}
+	}
+
+	errOut := c.receiveErr(ctx, resp)
+	if errOut != nil {
+		return meta, c.decodeErr(resp, errOut)
+	}
+
+	if resp.StatusCode == http.StatusNoContent || m == http.MethodHead {
+		return meta, nil //nothing to decode, by definition
+	}
 
-		return errOut
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	idx, ok := c.index.find(mt)
+	if !ok {
+		return meta, fmt.Errorf("httpio/client: no encoder for media type '%s'", mt)
 	}
 
+	dec := c.decs[idx].Decoder(resp.Body)
 	err = dec.Decode(out)
+	if err != nil {
+		return meta, err
+	}
+
+	//trailers are only available once the body is read until EOF
+	_, err = io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return meta, err
+	}
+
+	return meta, nil
+}
+
+//decodeErr decodes the error response into 'errOut', if that is not possible the returned error will
+//only describe the response status
+func (c *Client) decodeErr(resp *http.Response, errOut error) error {
+	buf, err := bufferErrBody(resp)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	rerr := &ResponseError{StatusCode: resp.StatusCode, Header: resp.Header, Body: buf.data}
+	if len(buf.data) < 1 {
+		return rerr
+	}
+
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	idx, ok := c.index.find(mt) //problem details are decoded as json through their suffix
+	if !ok {
+		return rerr
+	}
+
+	err = c.decs[idx].Decoder(bytes.NewReader(buf.data)).Decode(errOut)
+	if err == nil {
+		rerr.Err = errOut
+	}
+
+	return rerr
 }
+
+//ResponseMeta exposes the metadata of a response that the output was decoded from
+type ResponseMeta struct {
+	StatusCode int
+	Header     http.Header
+	Trailer    http.Header //only populated once the body has been read completely
+
+	resp *http.Response
+}
+
+//ETag returns the entity tag of the response, as is, including quotes and weakness indicator
+func (m *ResponseMeta) ETag() string { return m.Header.Get("ETag") }
+
+//Location returns the Location header resolved against the request url, http.ErrNoLocation is
+//returned when the header is not present
+func (m *ResponseMeta) Location() (*url.URL, error) { return m.resp.Location() }
+
+//Link returns the target of the first link in the Link header with relation type 'rel', resolved
+//against the request url
+func (m *ResponseMeta) Link(rel string) (u *url.URL, ok bool) {
+	for _, l := range header.ParseLink(m.Header, "Link") {
+		if !l.HasRel(rel) {
+			continue
+		}
+
+		u, err := m.resp.Request.URL.Parse(l.URL)
+		if err != nil {
+			return nil, false
+		}
+
+		return u, true
+	}
+
+	return nil, false
+}
+
+//Cookies parses and returns the cookies set by the response
+func (m *ResponseMeta) Cookies() []*http.Cookie { return m.resp.Cookies() }
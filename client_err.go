@@ -0,0 +1,153 @@
+package httpio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+var (
+	//MaxErrBodySize limits how much of an error response body is buffered by the client
+	MaxErrBodySize int64 = 64 * 1024
+
+	//MediaTypeProblemJSON identifies RFC 7807 problem details encoded as JSON
+	MediaTypeProblemJSON = "application/problem+json"
+)
+
+//ResponseError is returned by the client when a response is considered to hold an error. It carries
+//the status, headers and (a snippet of) the raw body next to the error value that was decoded from it
+type ResponseError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte //at most MaxErrBodySize bytes of the raw body
+	Err        error  //decoded error value, nil if the body could not be decoded
+}
+
+//Error returns the message of the decoded error or else describes the response status
+func (e *ResponseError) Error() string {
+	if e.Err != nil && e.Err.Error() != "" {
+		return e.Err.Error()
+	}
+
+	msg := fmt.Sprintf("httpio/client: unexpected response status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if snip := strings.TrimSpace(string(e.Body)); snip != "" {
+		if len(snip) > 128 {
+			snip = snip[:128] + "..."
+		}
+
+		msg += ": " + snip
+	}
+
+	return msg
+}
+
+//Unwrap returns the decoded error value such that errors.As can be used to retrieve it
+func (e *ResponseError) Unwrap() error { return e.Err }
+
+//ErrRegistry maps status codes and RFC 7807 problem type URIs to the error types the client decodes
+//error responses into. Its Receive method implements the ErrReceiver and considers every non-2xx
+//response (or one with the 'X-Has-Handling-Error' header) to hold an error.
+type ErrRegistry struct {
+	status   map[int]reflect.Type
+	problems map[string]reflect.Type
+	def      reflect.Type
+}
+
+//NewErrRegistry creates a registry that decodes unregistered errors into a struct with a 'message' field
+func NewErrRegistry() *ErrRegistry {
+	return &ErrRegistry{
+		status:   map[int]reflect.Type{},
+		problems: map[string]reflect.Type{},
+		def:      reflect.TypeOf(&stdClientErr{}),
+	}
+}
+
+func errType(proto error) reflect.Type {
+	t := reflect.TypeOf(proto)
+	if t == nil || t.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("httpio/client: error type must be a pointer, got: %T", proto))
+	}
+
+	return t
+}
+
+//Status registers the type of 'proto' to be decoded into for responses with status 'code'
+func (reg *ErrRegistry) Status(code int, proto error) { reg.status[code] = errType(proto) }
+
+//Problem registers the type of 'proto' to be decoded into for problem details with type URI 'typ'
+func (reg *ErrRegistry) Problem(typ string, proto error) { reg.problems[typ] = errType(proto) }
+
+//Default sets the type of 'proto' to be decoded into for errors that match no other registration
+func (reg *ErrRegistry) Default(proto error) { reg.def = errType(proto) }
+
+//Receive implements the ErrReceiver. Problem types take precedence over status codes
+func (reg *ErrRegistry) Receive(ctx context.Context, resp *http.Response) error {
+	if isSuccess(resp.StatusCode) && resp.Header.Get("X-Has-Handling-Error") == "" {
+		return nil
+	}
+
+	t, ok := reg.problems[problemType(resp)]
+	if !ok {
+		t, ok = reg.status[resp.StatusCode]
+	}
+
+	if !ok {
+		t = reg.def
+	}
+
+	return reflect.New(t.Elem()).Interface().(error)
+}
+
+//problemType peeks into a buffered JSON body for the 'type' member of RFC 7807 problem details
+func problemType(resp *http.Response) string {
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mt != MediaTypeProblemJSON && mt != MediaTypeJSON {
+		return ""
+	}
+
+	buf, ok := resp.Body.(*errBody)
+	if !ok {
+		return ""
+	}
+
+	prob := struct {
+		Type string `json:"type"`
+	}{}
+
+	_ = json.Unmarshal(buf.data, &prob)
+	return prob.Type
+}
+
+//errBody is the buffered body of an error response, it can be peeked into by ErrReceivers. Reading it
+//still yields the whole body, also beyond what was buffered
+type errBody struct {
+	io.Reader
+	data []byte
+}
+
+func (b *errBody) Close() error { return nil }
+
+func isSuccess(code int) bool { return code >= 200 && code < 300 }
+
+//bufferErrBody reads (at most MaxErrBodySize of) the response body and replaces it with one that reads
+//the buffered part before the rest
+func bufferErrBody(resp *http.Response) (*errBody, error) {
+	if buf, ok := resp.Body.(*errBody); ok {
+		return buf, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxErrBodySize))
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &errBody{io.MultiReader(bytes.NewReader(data), resp.Body), data}
+	resp.Body = buf
+	return buf, nil
+}
@@ -0,0 +1,228 @@
+package httpio_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+type notFoundErr struct {
+	Resource string `json:"resource"`
+}
+
+func (e *notFoundErr) Error() string { return "not found: " + e.Resource }
+
+type outOfCreditErr struct {
+	Type    string `json:"type"`
+	Balance int    `json:"balance"`
+}
+
+func (e *outOfCreditErr) Error() string { return fmt.Sprintf("out of credit, balance: %d", e.Balance) }
+
+func TestClientErrors(t *testing.T) {
+	for _, c := range []struct {
+		Name      string
+		Handler   http.HandlerFunc
+		ExpErr    string
+		ExpStatus int
+		ExpBody   string
+		Check     func(t *testing.T, err error)
+	}{
+		{
+			Name: "proxy error without handling header",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				w.WriteHeader(http.StatusBadGateway)
+				fmt.Fprint(w, "<h1>bad gateway</h1>")
+			},
+			ExpErr:    "httpio/client: unexpected response status 502 Bad Gateway: <h1>bad gateway</h1>",
+			ExpStatus: http.StatusBadGateway,
+			ExpBody:   "<h1>bad gateway</h1>",
+		},
+		{
+			Name: "non-decodable json error body",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"message": `)
+			},
+			ExpErr:    `httpio/client: unexpected response status 500 Internal Server Error: {"message":`,
+			ExpStatus: http.StatusInternalServerError,
+			ExpBody:   `{"message": `,
+		},
+		{
+			Name: "error without body",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			},
+			ExpErr:    "httpio/client: unexpected response status 403 Forbidden",
+			ExpStatus: http.StatusForbidden,
+		},
+		{
+			Name: "registered status code",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"resource": "account"}`)
+			},
+			ExpErr:    "not found: account",
+			ExpStatus: http.StatusNotFound,
+			ExpBody:   `{"resource": "account"}`,
+			Check: func(t *testing.T, err error) {
+				var nferr *notFoundErr
+				if !errors.As(err, &nferr) || nferr.Resource != "account" {
+					t.Fatalf("expected not found error, got: %#v", err)
+				}
+			},
+		},
+		{
+			Name: "registered problem type takes precedence",
+			Handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"type": "https://example.com/out-of-credit", "balance": 30}`)
+			},
+			ExpErr:    "out of credit, balance: 30",
+			ExpStatus: http.StatusNotFound,
+			ExpBody:   `{"type": "https://example.com/out-of-credit", "balance": 30}`,
+			Check: func(t *testing.T, err error) {
+				var ocerr *outOfCreditErr
+				if !errors.As(err, &ocerr) || ocerr.Balance != 30 {
+					t.Fatalf("expected out of credit error, got: %#v", err)
+				}
+			},
+		},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			ts := httptest.NewServer(c.Handler)
+			defer ts.Close()
+
+			client, err := httpio.NewClient(ts.Client(), ts.URL, &httpio.JSON{}, &httpio.JSON{})
+			if err != nil {
+				t.Fatal("failed to create client:", err)
+			}
+
+			client.Errors.Status(http.StatusNotFound, &notFoundErr{})
+			client.Errors.Problem("https://example.com/out-of-credit", &outOfCreditErr{})
+
+			out := &testOutput{}
+			err = client.Request(context.Background(), http.MethodGet, "", nil, nil, out)
+			if fmt.Sprint(err) != c.ExpErr {
+				t.Fatalf("expected err '%v', got: '%v'", c.ExpErr, err)
+			}
+
+			var rerr *httpio.ResponseError
+			if !errors.As(err, &rerr) {
+				t.Fatalf("expected a response error, got: %#v", err)
+			}
+
+			if rerr.StatusCode != c.ExpStatus {
+				t.Fatalf("expected status %d, got: %d", c.ExpStatus, rerr.StatusCode)
+			}
+
+			if string(rerr.Body) != c.ExpBody {
+				t.Fatalf("expected body '%s', got: '%s'", c.ExpBody, rerr.Body)
+			}
+
+			if c.Check != nil {
+				c.Check(t, err)
+			}
+		})
+	}
+}
+
+func TestClientErrReceiver(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"resource": "account"}`)
+	}))
+	defer ts.Close()
+
+	client, err := httpio.NewClient(ts.Client(), ts.URL, &httpio.JSON{}, &httpio.JSON{})
+	if err != nil {
+		t.Fatal("failed to create client:", err)
+	}
+
+	client.Errors = httpio.NewErrRegistry()
+	client.Errors.Status(http.StatusNotFound, &notFoundErr{})
+	err = client.Request(context.Background(), http.MethodGet, "", nil, nil, nil)
+	if nferr := (*notFoundErr)(nil); !errors.As(err, &nferr) {
+		t.Fatalf("expected the replaced registry to be used, got: %#v", err)
+	}
+
+	defer func(n int64) { httpio.MaxErrBodySize = n }(httpio.MaxErrBodySize)
+	httpio.MaxErrBodySize = 8 //less than the body
+
+	client.ErrReceiver = func(ctx context.Context, resp *http.Response) error { return nil }
+	out := &notFoundErr{}
+	err = client.Request(context.Background(), http.MethodGet, "", nil, nil, out)
+	if err != nil || out.Resource != "account" {
+		t.Fatalf("expected the whole body to be decoded, got: %+v %v", out, err)
+	}
+}
+
+func TestClientResponseMeta(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Match") == `"v1"` {
+			w.Header().Set("ETag", `"v2"`)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Trailer", "X-Checksum")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Location", "/accounts/1")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"result": "created"}`)
+		w.Header().Set("X-Checksum", "c0ffee")
+	}))
+	defer ts.Close()
+
+	client, err := httpio.NewClient(ts.Client(), ts.URL, &httpio.JSON{}, &httpio.JSON{})
+	if err != nil {
+		t.Fatal("failed to create client:", err)
+	}
+
+	out := &testOutput{}
+	meta, err := client.Do(context.Background(), http.MethodPost, "/accounts", nil, nil, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Result != "created" || meta.StatusCode != http.StatusCreated || meta.ETag() != `"v1"` {
+		t.Fatalf("unexpected output or meta, got: %v, %#v", out, meta)
+	}
+
+	if loc, err := meta.Location(); err != nil || loc.String() != ts.URL+"/accounts/1" {
+		t.Fatalf("expected absolute location, got: %v (%v)", loc, err)
+	}
+
+	if cs := meta.Cookies(); len(cs) != 1 || cs[0].Value != "abc" {
+		t.Fatalf("expected session cookie, got: %v", cs)
+	}
+
+	if meta.Trailer.Get("X-Checksum") != "c0ffee" {
+		t.Fatalf("expected trailer to be read, got: %v", meta.Trailer)
+	}
+
+	meta, err = client.Do(context.Background(), http.MethodPut, "/accounts/1", http.Header{"If-Match": {`"v1"`}}, nil, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if meta.StatusCode != http.StatusNoContent || meta.ETag() != `"v2"` {
+		t.Fatalf("expected no content with new etag, got: %#v", meta)
+	}
+	meta, err = client.Do(context.Background(), http.MethodHead, "/accounts/1", nil, nil, out)
+	if err != nil || meta.ETag() != `"v1"` {
+		t.Fatalf("expected head response to be received without a body, got: %#v %v", meta, err)
+	}
+}
@@ -0,0 +1,427 @@
+package httpio
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	//MediaTypeCSV identifies comma separated values
+	MediaTypeCSV = "text/csv"
+
+	//MediaTypeTSV identifies tab separated values
+	MediaTypeTSV = "text/tab-separated-values"
+)
+
+//CSV allows rows to be encoded as and decoded from comma separated values. Slices, arrays and iterators
+//(iter.Seq) of structs are encoded as one row per element, preceded by a header row. A single struct is
+//encoded as one row and [][]string as raw records without header. Columns are named by the 'csv' tag of
+//a field, or else its 'json' tag or name. Fields of nested structs become columns of their own that are
+//named by joining the names with a dot, fields of embedded structs are promoted. Fields that refer back
+//to a struct they are nested in are a single column. Times are formatted using TimeLayout, nil pointers
+//are left empty and values that do not fit in a single cell (slices, maps, recursive structs) are encoded
+//as JSON. Decoding binds the rows into a pointer to a slice of structs by matching the header, columns
+//that match no field are ignored. Errors without exported fields are encoded as a single 'message' column
+type CSV struct {
+	//Comma is the field delimiter, it defaults to ','
+	Comma rune
+
+	//NoHeader leaves out the header row when encoding, when decoding the columns are then expected in
+	//the order of the fields
+	NoHeader bool
+
+	//TimeLayout is used to format and parse time.Time values, it defaults to time.RFC3339
+	TimeLayout string
+}
+
+//MimeType will report the EncodingMimeType
+func (e *CSV) MimeType() string { return MediaTypeCSV }
+
+//Encoder will create encoders
+func (e *CSV) Encoder(w io.Writer) Encoder { return &csvEncoder{e, e.comma(','), w} }
+
+//Decoder will create decoders
+func (e *CSV) Decoder(r io.Reader) Decoder { return &csvDecoder{e, e.comma(','), r} }
+
+func (e *CSV) comma(def rune) rune {
+	if e.Comma != 0 {
+		return e.Comma
+	}
+
+	return def
+}
+
+func (e *CSV) timeLayout() string {
+	if e.TimeLayout != "" {
+		return e.TimeLayout
+	}
+
+	return time.RFC3339
+}
+
+//TSV works like CSV but separates the values by tabs by default
+type TSV struct{ CSV }
+
+//MimeType will report the EncodingMimeType
+func (e *TSV) MimeType() string { return MediaTypeTSV }
+
+//Encoder will create encoders
+func (e *TSV) Encoder(w io.Writer) Encoder { return &csvEncoder{&e.CSV, e.comma('\t'), w} }
+
+//Decoder will create decoders
+func (e *TSV) Decoder(r io.Reader) Decoder { return &csvDecoder{&e.CSV, e.comma('\t'), r} }
+
+//csvColumn is a cell of a row, found through the field 'index' of the (nested) structs
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+//csvColumns returns the columns of struct type 't', 'path' holds the structs it is nested in such that
+//recursive types end in a single cell
+func csvColumns(t reflect.Type, prefix string, index []int, path map[reflect.Type]bool) (cols []csvColumn) {
+	if path == nil {
+		path = map[reflect.Type]bool{}
+	}
+
+	path[t] = true
+	defer delete(path, t)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("csv"), ",")
+		if name == "" {
+			name, _, _ = strings.Cut(f.Tag.Get("json"), ",")
+		}
+
+		if name == "-" {
+			continue
+		}
+
+		fidx := append(append([]int{}, index...), i)
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		nested := ft.Kind() == reflect.Struct && ft != timeType && !path[ft] &&
+			!ft.Implements(textMarshalerType) && !reflect.PointerTo(ft).Implements(textMarshalerType)
+		if f.Anonymous && name == "" && nested {
+			cols = append(cols, csvColumns(ft, prefix, fidx, path)...)
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		if nested {
+			cols = append(cols, csvColumns(ft, prefix+name+".", fidx, path)...)
+			continue
+		}
+
+		cols = append(cols, csvColumn{prefix + name, fidx})
+	}
+
+	return cols
+}
+
+//csvRowType returns the struct type of the rows in a value of type 't'
+func csvRowType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t, t.Kind() == reflect.Struct
+}
+
+type csvEncoder struct {
+	cfg   *CSV
+	comma rune
+	w     io.Writer
+}
+
+func (e *csvEncoder) Encode(v interface{}) error {
+	cw := csv.NewWriter(e.w)
+	cw.Comma = e.comma
+	if records, ok := v.([][]string); ok {
+		return cw.WriteAll(records)
+	}
+
+	if err, ok := v.(error); ok && opaqueErr(reflect.ValueOf(err)) {
+		records := [][]string{{"message"}, {err.Error()}}
+		if e.cfg.NoHeader {
+			records = records[1:]
+		}
+
+		return cw.WriteAll(records)
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	var (
+		rt   reflect.Type
+		ok   bool
+		rows func(row func(reflect.Value) error) error
+	)
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		rt, ok = csvRowType(rv.Type().Elem())
+		rows = func(row func(reflect.Value) error) error {
+			for i := 0; i < rv.Len(); i++ {
+				if err := row(rv.Index(i)); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	case reflect.Func:
+		if ft := rv.Type(); ft.NumIn() == 1 && ft.NumOut() == 0 && ft.In(0).Kind() == reflect.Func &&
+			ft.In(0).NumIn() == 1 && ft.In(0).NumOut() == 1 && ft.In(0).Out(0).Kind() == reflect.Bool {
+			rt, ok = csvRowType(ft.In(0).In(0))
+			rows = func(row func(reflect.Value) error) (err error) {
+				yield := reflect.MakeFunc(ft.In(0), func(args []reflect.Value) []reflect.Value {
+					err = row(args[0])
+					if err == nil {
+						cw.Flush() //stream each row as it is produced
+						err = cw.Error()
+					}
+
+					return []reflect.Value{reflect.ValueOf(err == nil)}
+				})
+
+				rv.Call([]reflect.Value{yield})
+				return err
+			}
+		}
+	case reflect.Struct:
+		rt, ok = rv.Type(), true
+		rows = func(row func(reflect.Value) error) error { return row(rv) }
+	}
+
+	if !ok {
+		return fmt.Errorf("httpio/csv: cannot encode %T, expected (an iterator of) structs", v)
+	}
+
+	cols := csvColumns(rt, "", nil, nil)
+	if !e.cfg.NoHeader {
+		header := make([]string, len(cols))
+		for i, col := range cols {
+			header[i] = col.name
+		}
+
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+	}
+
+	record := make([]string, len(cols))
+	err := rows(func(row reflect.Value) (err error) {
+		for row.Kind() == reflect.Pointer && !row.IsNil() {
+			row = row.Elem()
+		}
+
+		for i, col := range cols {
+			record[i] = ""
+			if row.Kind() != reflect.Struct {
+				continue //nil rows are left empty
+			}
+
+			if fv, ok := fieldByIndex(row, col.index, false); ok {
+				record[i], err = e.cell(fv)
+				if err != nil {
+					return fmt.Errorf("httpio/csv: column '%s': %v", col.name, err)
+				}
+			}
+		}
+
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func (e *csvEncoder) cell(v reflect.Value) (string, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(e.cfg.timeLayout()), nil
+	}
+
+	if m, ok := textMarshaler(v); ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+	}
+
+	data, err := json.Marshal(v.Interface())
+	return string(data), err
+}
+
+type csvDecoder struct {
+	cfg   *CSV
+	comma rune
+	r     io.Reader
+}
+
+func (d *csvDecoder) Decode(v interface{}) error {
+	cr := csv.NewReader(d.r)
+	cr.Comma = d.comma
+	if records, ok := v.(*[][]string); ok {
+		all, err := cr.ReadAll()
+		*records = all
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("httpio/csv: cannot decode into %T, expected a pointer to a slice", v)
+	}
+
+	st := rv.Elem().Type()
+	rt, ok := csvRowType(st.Elem())
+	if !ok {
+		return fmt.Errorf("httpio/csv: cannot decode into %T, expected a pointer to a slice of structs", v)
+	}
+
+	all := csvColumns(rt, "", nil, nil)
+	cols := all
+	if !d.cfg.NoHeader {
+		header, err := cr.Read()
+		if err == io.EOF {
+			rv.Elem().Set(reflect.MakeSlice(st, 0, 0))
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		cols = make([]csvColumn, len(header))
+		for i, name := range header {
+			for _, col := range all {
+				if col.name == name {
+					cols[i] = col
+				}
+			}
+		}
+	}
+
+	rows := reflect.MakeSlice(st, 0, 0)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		row := reflect.New(rt)
+		for i, s := range record {
+			if i >= len(cols) || cols[i].index == nil || s == "" {
+				continue
+			}
+
+			fv, _ := fieldByIndex(row.Elem(), cols[i].index, true)
+			err = d.cell(fv, s)
+			if err != nil {
+				line, _ := cr.FieldPos(i)
+				return fmt.Errorf("httpio/csv: line %d, column '%s': %v", line, cols[i].name, err)
+			}
+		}
+
+		if st.Elem().Kind() == reflect.Pointer {
+			rows = reflect.Append(rows, row)
+		} else {
+			rows = reflect.Append(rows, row.Elem())
+		}
+	}
+
+	rv.Elem().Set(rows)
+	return nil
+}
+
+func (d *csvDecoder) cell(fv reflect.Value, s string) error {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+
+		return d.cell(fv.Elem(), s)
+	}
+
+	if fv.Type() == timeType {
+		t, err := time.Parse(d.cfg.timeLayout(), s)
+		if err != nil {
+			return err
+		}
+
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if _, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return setField(fv, []string{s})
+	}
+
+	switch fv.Kind() {
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			data, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return err
+			}
+
+			fv.SetBytes(data)
+			return nil
+		}
+
+		fallthrough
+	case reflect.Map, reflect.Struct, reflect.Array, reflect.Interface:
+		return json.Unmarshal([]byte(s), fv.Addr().Interface())
+	}
+
+	return setField(fv, []string{s})
+}
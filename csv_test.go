@@ -0,0 +1,159 @@
+package httpio_test
+
+import (
+	"bytes"
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+type csvTestAddress struct {
+	City string `json:"city"`
+	Zip  string `csv:"postal_code" json:"zip"`
+}
+
+type csvTestMeta struct {
+	Source string `json:"source"`
+}
+
+type csvTestRow struct {
+	csvTestMeta
+	ID       int             `json:"id"`
+	Name     string          `json:"name,omitempty"`
+	Price    float64         `csv:"price_eur"`
+	Active   *bool           `json:"active"`
+	Created  time.Time       `json:"created"`
+	Address  csvTestAddress  `json:"address"`
+	Billing  *csvTestAddress `json:"billing"`
+	Tags     []string        `json:"tags"`
+	Internal string          `csv:"-"`
+}
+
+type csvTestNode struct {
+	Name   string       `json:"name"`
+	Parent *csvTestNode `json:"parent"`
+}
+
+func csvTestRows() []csvTestRow {
+	yes := true
+	return []csvTestRow{
+		{
+			csvTestMeta: csvTestMeta{Source: "api"},
+			ID:          1, Name: "Widget, large", Price: 9.5, Active: &yes,
+			Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Address: csvTestAddress{City: "Amsterdam", Zip: "1000AA"},
+			Tags:    []string{"a", "b"}, Internal: "x",
+		},
+		{ID: 2, Name: `Say "hi"`, Billing: &csvTestAddress{City: "Utrecht"}},
+	}
+}
+
+const csvTestOutput = `source,id,name,price_eur,active,created,address.city,address.postal_code,billing.city,billing.postal_code,tags
+api,1,"Widget, large",9.5,true,2024-01-02T03:04:05Z,Amsterdam,1000AA,,,"[""a"",""b""]"
+,2,"Say ""hi""",0,,0001-01-01T00:00:00Z,,,Utrecht,,null
+`
+
+func TestCSVEncoding(t *testing.T) {
+	rows := csvTestRows()
+	var seq iter.Seq[*csvTestRow] = func(yield func(*csvTestRow) bool) {
+		for i := range rows {
+			if !yield(&rows[i]) {
+				return
+			}
+		}
+	}
+
+	for _, c := range []struct {
+		Name  string
+		Enc   httpio.EncoderFactory
+		Value interface{}
+		Exp   string
+	}{
+		{"slice", &httpio.CSV{}, rows, csvTestOutput},
+		{"slice of pointers", &httpio.CSV{}, []*csvTestRow{&rows[0], &rows[1]}, csvTestOutput},
+		{"iterator", &httpio.CSV{}, seq, csvTestOutput},
+		{"empty", &httpio.CSV{}, []csvTestRow{}, strings.SplitAfter(csvTestOutput, "\n")[0]},
+		{"single struct", &httpio.CSV{NoHeader: true}, csvTestAddress{"Paris", "75001"}, "Paris,75001\n"},
+		{"records", &httpio.CSV{}, [][]string{{"a", "b"}, {"1", "2"}}, "a,b\n1,2\n"},
+		{"recursive", &httpio.CSV{}, []csvTestNode{{"a", nil}, {"b", &csvTestNode{"a", nil}}},
+			"name,parent\na,\nb,\"{\"\"name\"\":\"\"a\"\",\"\"parent\"\":null}\"\n"},
+		{"error", &httpio.CSV{}, errors.New("oops, failed"), "message\n\"oops, failed\"\n"},
+		{"tsv", &httpio.TSV{}, []csvTestAddress{{"Paris", "75001"}}, "city\tpostal_code\nParis\t75001\n"},
+		{"time layout", &httpio.CSV{TimeLayout: time.DateOnly, NoHeader: true}, rows[:1], "api,1,\"Widget, large\",9.5,true,2024-01-02,Amsterdam,1000AA,,,\"[\"\"a\"\",\"\"b\"\"]\"\n"},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			err := c.Enc.Encoder(buf).Encode(c.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.String() != c.Exp {
+				t.Fatalf("expected:\n%s\ngot:\n%s", c.Exp, buf.String())
+			}
+		})
+	}
+
+	err := (&httpio.CSV{}).Encoder(bytes.NewBuffer(nil)).Encode([]string{"a"})
+	if exp := "httpio/csv: cannot encode []string, expected (an iterator of) structs"; err == nil || err.Error() != exp {
+		t.Fatalf("expected error '%s', got: %v", exp, err)
+	}
+}
+
+func TestCSVDecoding(t *testing.T) {
+	var rows []csvTestRow
+	err := (&httpio.CSV{}).Decoder(strings.NewReader(csvTestOutput)).Decode(&rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := csvTestRows()
+	exp[0].Internal = ""
+	if !reflect.DeepEqual(rows, exp) {
+		t.Fatalf("expected %+v, got: %+v", exp, rows)
+	}
+
+	var nodes []csvTestNode
+	err = (&httpio.CSV{}).Decoder(strings.NewReader("name,parent\nb,\"{\"\"name\"\":\"\"a\"\"}\"\n")).Decode(&nodes)
+	if err != nil || len(nodes) != 1 || nodes[0].Parent == nil || nodes[0].Parent.Name != "a" {
+		t.Fatalf("unexpected nodes: %+v %v", nodes, err)
+	}
+
+	var ptrs []*csvTestAddress
+	err = (&httpio.TSV{}).Decoder(strings.NewReader("postal_code\tunknown\tcity\n75001\tx\tParis\n")).Decode(&ptrs)
+	if err != nil || len(ptrs) != 1 || *ptrs[0] != (csvTestAddress{"Paris", "75001"}) {
+		t.Fatalf("unexpected rows: %v %v", ptrs, err)
+	}
+
+	err = (&httpio.CSV{}).Decoder(strings.NewReader("id,name\n1,a\nx,b\n")).Decode(&rows)
+	if exp := `httpio/csv: line 3, column 'id': strconv.ParseInt: parsing "x": invalid syntax`; err == nil || err.Error() != exp {
+		t.Fatalf("expected error '%s', got: %v", exp, err)
+	}
+
+	err = (&httpio.CSV{}).Decoder(strings.NewReader("")).Decode(&csvTestRow{})
+	if exp := "httpio/csv: cannot decode into *httpio_test.csvTestRow, expected a pointer to a slice"; err == nil || err.Error() != exp {
+		t.Fatalf("expected error '%s', got: %v", exp, err)
+	}
+}
+
+func TestCSVNegotiation(t *testing.T) {
+	e := httpio.NewEgress(&httpio.JSON{}, &httpio.CSV{}, &httpio.TSV{})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "text/csv")
+	w := httptest.NewRecorder()
+	err := e.Render(csvTestRows(), w, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if w.Body.String() != csvTestOutput || w.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Fatalf("unexpected response: %s %q", w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
@@ -34,13 +34,18 @@ func (s DecoderList) Supported() (supported []string) {
 	return supported
 }
 
-//Find an encoding mechanism by its mime type: O(N). Returns nil if none is found
+//Find an encoding mechanism by its mime type, aliases, structured syntax suffixes and wildcards also
+//match (see MediaTypeAliases): O(N). Returns nil if none is found
 func (s DecoderList) Find(mime string) DecoderFactory {
-	for _, e := range s {
-		if e.MimeType() == mime {
-			return e
-		}
+	i, ok := listFind(mime, len(s), func(i int) mediaTyper { return s[i] })
+	if !ok {
+		return nil
 	}
 
-	return nil
+	return s[i]
+}
+
+//index allows the factories to be found in O(1)
+func (s DecoderList) index() mediaIndex {
+	return newMediaIndex(len(s), func(i int) mediaTyper { return s[i] })
 }
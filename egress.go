@@ -2,6 +2,7 @@ package httpio
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 )
@@ -9,7 +10,9 @@ import (
 type contextValue string
 
 var (
-	contextValueStatusCode = contextValue("status_code")
+	contextValueStatusCode    = contextValue("status_code")
+	contextValueMediaType     = contextValue("media_type")
+	contextValueDefaultStatus = contextValue("default_status")
 )
 
 //StatusValue returns a specific status stored in the (request) context, returns 0 if its not specified
@@ -23,20 +26,35 @@ func WithStatus(ctx context.Context, code int) context.Context {
 	return context.WithValue(ctx, contextValueStatusCode, code)
 }
 
+//MediaTypeValue returns the media type that the egress negotiated for the response that is being rendered,
+//transwares can use it to shape outputs for the format they are encoded in. Returns "" outside of Render
+func MediaTypeValue(ctx context.Context) (mt string) {
+	mt, _ = ctx.Value(contextValueMediaType).(string)
+	return
+}
+
+//StatusCoder can be implemented by errors to choose the status they are rendered with when none is set
+//with WithStatus, it is also found in the errors they wrap
+type StatusCoder interface {
+	StatusCode() int
+}
+
 //RenderFunc is bound to an request but renders once called
 type RenderFunc func(interface{}, error)
 
 //Egress takes care of encoding outgoing responses
 type Egress struct {
 	encoders EncoderList
+	index    mediaIndex
 	wares    []Transware
+	status   int
 }
 
 //NewEgress uses the provided encoder factories to setup encoding
 func NewEgress(def EncoderFactory, others ...EncoderFactory) *Egress {
 	list := EncoderList{def}
 	list = append(list, others...)
-	return &Egress{list, nil}
+	return &Egress{encoders: list, index: list.index()}
 }
 
 //MustRender will render 'out' onto 'w' if this fails it will attemp to render the error. If this
@@ -51,20 +69,57 @@ func (e *Egress) MustRender(out interface{}, w http.ResponseWriter, r *http.Requ
 	}
 }
 
-func (e *Egress) encode(a interface{}, r *http.Request, w http.ResponseWriter) error {
+//statusOf returns the status that 'a' is rendered with: the one set with WithStatus, else that of the
+//StatusCoder for errors or the status of the egress (see RouteStatus) for outputs, 200 otherwise
+func statusOf(a interface{}, r *http.Request) int {
 	status := StatusValue(r.Context())
+	if err, isErr := a.(error); status == 0 && isErr {
+		var sc StatusCoder
+		if errors.As(err, &sc) {
+			status = sc.StatusCode()
+		}
+	} else if status == 0 {
+		status, _ = r.Context().Value(contextValueDefaultStatus).(int)
+	}
+
 	if status == 0 {
 		status = http.StatusOK
 	}
 
-	mt := negotiateContentType(r.Header, e.encoders.Supported(), e.encoders.Default().MimeType())
-	encf := e.encoders.Find(mt)
-	if encf == nil {
+	return status
+}
+
+func (e *Egress) encode(a interface{}, r *http.Request, w http.ResponseWriter) error {
+	status := statusOf(a, r)
+	if c, ok := rawContent(a); ok {
+		return serveContent(c, status, w, r) //sent as is, regardless of what is accepted
+	}
+
+	mt := MediaTypeValue(r.Context())
+	if mt == "" {
+		mt = e.negotiate(r)
+	}
+
+	idx, ok := e.index.find(mt)
+	if !ok {
 		return fmt.Errorf("httpio/egress: no encoder for media type '%s'", mt)
 	}
 
-	enc := encf.Encoder(w)
-	w.Header().Set("Content-Type", fmt.Sprintf("%s; charset=utf-8", mt))
+	encf := e.encoders[idx]
+
+	var enc Encoder
+	if rencf, ok := encf.(RequestEncoderFactory); ok {
+		enc = rencf.RequestEncoder(w, r)
+	} else {
+		enc = encf.Encoder(w)
+	}
+
+	ct := fmt.Sprintf("%s; charset=utf-8", mt)
+	if cter, ok := encf.(ContentTyper); ok {
+		ct = cter.ContentType()
+	}
+
+	w.Header().Set("Content-Type", ct)
 	w.WriteHeader(status)
 	err := enc.Encode(a)
 	if err != nil {
@@ -74,6 +129,23 @@ func (e *Egress) encode(a interface{}, r *http.Request, w http.ResponseWriter) e
 	return nil
 }
 
+func (e *Egress) negotiate(r *http.Request) string {
+	return negotiateContentType(r.Header, e.encoders.Supported(), e.encoders.Default().MimeType(), e.resolve)
+}
+
+//resolve returns the media type of the encoder that handles accepted media type 'mt', e.g: through an
+//alias. It returns "" if there is none
+func (e *Egress) resolve(mt string) string {
+	if idx, ok := e.index.find(mt); ok {
+		return e.encoders[idx].MimeType()
+	}
+
+	return ""
+}
+
+//Encoders returns the encoder factories of the egress stack in order of preference
+func (e *Egress) Encoders() EncoderList { return e.encoders }
+
 //Use will append the transware(s) to the egress render chain
 func (e *Egress) Use(wares ...Transware) {
 	e.wares = append(e.wares, wares...)
@@ -81,6 +153,12 @@ func (e *Egress) Use(wares ...Transware) {
 
 //Render will take value 'v' and encode it onto response 'w' in context of request 'r'
 func (e *Egress) Render(out interface{}, w http.ResponseWriter, r *http.Request) (err error) {
+	ctx := context.WithValue(r.Context(), contextValueMediaType, e.negotiate(r))
+	if e.status != 0 {
+		ctx = context.WithValue(ctx, contextValueDefaultStatus, e.status)
+	}
+
+	r = r.WithContext(ctx)
 	chain := Chain(TransFunc(e.encode), e.wares...)
 	err = chain.Transform(out, r, w)
 	if err != nil {
@@ -2,6 +2,7 @@ package httpio
 
 import (
 	"io"
+	"net/http"
 )
 
 //Encoder allows for values to be encoded
@@ -15,6 +16,19 @@ type EncoderFactory interface {
 	Encoder(w io.Writer) Encoder
 }
 
+//RequestEncoderFactory can be implemented by encoder factories that encode differently depending on the
+//request that is responded to, e.g. to pretty print on request
+type RequestEncoderFactory interface {
+	EncoderFactory
+	RequestEncoder(w io.Writer, r *http.Request) Encoder
+}
+
+//ContentTyper can be implemented by encoder factories to provide the full Content-Type header of responses,
+//by default the media type is sent with a utf-8 charset which is wrong for binary formats
+type ContentTyper interface {
+	ContentType() string
+}
+
 //EncoderList offers encoder factories
 type EncoderList []EncoderFactory
 
@@ -34,14 +48,18 @@ func (s EncoderList) Supported() (supported []string) {
 	return supported
 }
 
-//Find an encoding mechanism by its mime type: O(N). Returns
-//nil if none is found
+//Find an encoding mechanism by its mime type, aliases and structured syntax suffixes also match (see
+//MediaTypeAliases): O(N). Returns nil if none is found
 func (s EncoderList) Find(mime string) EncoderFactory {
-	for _, e := range s {
-		if e.MimeType() == mime {
-			return e
-		}
+	i, ok := listFind(mime, len(s), func(i int) mediaTyper { return s[i] })
+	if !ok {
+		return nil
 	}
 
-	return nil
+	return s[i]
+}
+
+//index allows the factories to be found in O(1)
+func (s EncoderList) index() mediaIndex {
+	return newMediaIndex(len(s), func(i int) mediaTyper { return s[i] })
 }
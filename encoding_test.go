@@ -2,7 +2,6 @@ package httpio_test
 
 import (
 	"bytes"
-	"fmt"
 	"testing"
 
 	httpio "github.com/advanderveer/go-httpio"
@@ -71,7 +70,7 @@ func TestEncodingForm(t *testing.T) {
 	})
 }
 
-func TestEncodingFormNoEncoder(t *testing.T) {
+func TestEncodingFormDefaultEncoder(t *testing.T) {
 	var e httpio.EncoderFactory
 	e = httpio.NewFormEncoding(nil)
 	if e.MimeType() != "application/x-www-form-urlencoded" {
@@ -86,13 +85,13 @@ func TestEncodingFormNoEncoder(t *testing.T) {
 			Bar string `schema:"bar"`
 		}{"bar", "foo"}
 		err := enc.Encode(v)
-		if fmt.Sprint(err) != "no form encoder configured" {
-			t.Fatal("expected error that indicated that no form encoder was configured")
+		if err != nil || buf.String() != `bar=foo&foo=bar` {
+			t.Fatalf("expected the built-in codec to encode, got: %s %v", buf.String(), err)
 		}
 	})
 }
 
-func TestEncodingFormNoDecoder(t *testing.T) {
+func TestEncodingFormDefaultDecoder(t *testing.T) {
 	var e httpio.DecoderFactory
 	e = httpio.NewFormDecoding(nil)
 	if e.MimeType() != "application/x-www-form-urlencoded" {
@@ -107,8 +106,8 @@ func TestEncodingFormNoDecoder(t *testing.T) {
 			Bar string
 		}{}
 		err := dec.Decode(&v)
-		if fmt.Sprint(err) != "no form decoder configured" {
-			t.Fatal("expected error that indicated that no form decoder was configured")
+		if err != nil || v.Foo != "bar" || v.Bar != "foo" {
+			t.Fatalf("expected the built-in codec to decode, got: %+v %v", v, err)
 		}
 	})
 }
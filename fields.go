@@ -0,0 +1,479 @@
+package httpio
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+//FieldsParam is the query parameter that SelectFields reads the requested fields from
+var FieldsParam = "fields"
+
+//FieldsError is returned by SelectFields when a requested field does not exist in the output, it is
+//rendered with status 400 Bad Request
+type FieldsError struct {
+	Field string
+}
+
+//Error names the field that does not exist
+func (e *FieldsError) Error() string {
+	return fmt.Sprintf("httpio/fields: unknown field '%s'", e.Field)
+}
+
+//StatusCode implements the StatusCoder
+func (e *FieldsError) StatusCode() int { return http.StatusBadRequest }
+
+//SelectFields is an Egress transware that projects outputs to the fields that are requested with the
+//'fields' query parameter, e.g: "?fields=id,name,owner.email". Fields are named as in JSON and nested
+//fields are separated by dots, selections apply to every item of slices and iterators. The projection
+//copies the struct tags of the selected fields so it is encoded alike in every format. It should be
+//installed after PageLinks such that it sees the items of pages, errors and raw content are left as is
+func SelectFields(next Transformer) Transformer {
+	return TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+		if _, isErr := a.(error); isErr || a == nil {
+			return next.Transform(a, r, w)
+		}
+
+		if _, ok := rawContent(a); ok {
+			return next.Transform(a, r, w)
+		}
+
+		sel, err := parseFields(r.URL.Query()[FieldsParam])
+		if err != nil {
+			return err
+		} else if sel == nil {
+			return next.Transform(a, r, w)
+		}
+
+		_, project, err := projectType(reflect.TypeOf(a), sel, "", true)
+		if err != nil {
+			return err
+		}
+
+		pv, err := project(reflect.ValueOf(a))
+		if err != nil {
+			return err
+		}
+
+		return next.Transform(pv.Interface(), r, w)
+	})
+}
+
+//fieldSet holds the selected fields by name, a nil set selects the field as a whole
+type fieldSet map[string]fieldSet
+
+//parseFields parses comma separated lists of dotted field paths, it returns nil if none are listed
+func parseFields(vals []string) (sel fieldSet, err error) {
+	for _, val := range vals {
+		for _, p := range strings.Split(val, ",") {
+			if p = strings.TrimSpace(p); p == "" {
+				continue
+			}
+
+			if sel == nil {
+				sel = fieldSet{}
+			}
+
+			set := sel
+			names := strings.Split(p, ".")
+			for i, name := range names {
+				if name == "" {
+					return nil, &FieldsError{p}
+				}
+
+				sub, ok := set[name]
+				if ok && sub == nil {
+					break //already selected as a whole
+				}
+
+				if i == len(names)-1 {
+					set[name] = nil //the field as a whole overrules nested selections
+					break
+				}
+
+				if !ok {
+					sub = fieldSet{}
+					set[name] = sub
+				}
+
+				set = sub
+			}
+		}
+	}
+
+	return sel, nil
+}
+
+//String returns the selection in a canonical form, the sorted dotted paths as they are requested: e.g.
+//"id,owner.email". Names cannot hold dots or commas so the form is unambiguous
+func (s fieldSet) String() string {
+	var paths []string
+	var walk func(set fieldSet, prefix string)
+	walk = func(set fieldSet, prefix string) {
+		for name, sub := range set {
+			if sub == nil {
+				paths = append(paths, prefix+name)
+				continue
+			}
+
+			walk(sub, prefix+name+".")
+		}
+	}
+
+	walk(s, "")
+	sort.Strings(paths)
+	return strings.Join(paths, ",")
+}
+
+//projection copies value 'v' into a value of the projected type
+type projection func(v reflect.Value) (reflect.Value, error)
+
+//projectionKey identifies a projected type, the path is part of it as it is used in errors
+type projectionKey struct {
+	t         reflect.Type
+	sel, path string
+	top       bool
+}
+
+type projected struct {
+	t       reflect.Type
+	project projection
+}
+
+//maxProjections bounds the cache, selections of map keys are not validated so their variety is unbounded
+const maxProjections = 1024
+
+var (
+	projections    sync.Map
+	numProjections atomic.Int64
+)
+
+var (
+	anyType     = reflect.TypeOf((*interface{})(nil)).Elem()
+	xmlNameType = reflect.TypeOf(xml.Name{})
+)
+
+//projectType returns the type that values of type 't' are projected to and the function that copies them,
+//'top' is true for the output itself and its items such that named structs keep their XML element name
+func projectType(t reflect.Type, sel fieldSet, path string, top bool) (reflect.Type, projection, error) {
+	key := projectionKey{t, sel.String(), path, top}
+	if p, ok := projections.Load(key); ok {
+		return p.(projected).t, p.(projected).project, nil
+	}
+
+	pt, project, err := newProjection(t, sel, path, top)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if numProjections.Load() < maxProjections {
+		if _, loaded := projections.LoadOrStore(key, projected{pt, project}); !loaded {
+			numProjections.Add(1)
+		}
+	}
+
+	return pt, project, nil
+}
+
+//newProjection builds the projection of type 't', see projectType
+func newProjection(t reflect.Type, sel fieldSet, path string, top bool) (reflect.Type, projection, error) {
+	if t.Implements(textMarshalerType) || t.Implements(jsonMarshalerType) || t == timeType {
+		return nil, nil, unknownField(sel, path)
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		et, project, err := projectType(t.Elem(), sel, path, top)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return reflect.PointerTo(et), func(v reflect.Value) (reflect.Value, error) {
+			if v.IsNil() {
+				return reflect.Zero(reflect.PointerTo(et)), nil
+			}
+
+			ev, err := project(v.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			pv := reflect.New(et)
+			pv.Elem().Set(ev)
+			return pv, nil
+		}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nil, nil, unknownField(sel, path)
+		}
+
+		et, project, err := projectType(t.Elem(), sel, path, top)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return reflect.SliceOf(et), func(v reflect.Value) (reflect.Value, error) {
+			if v.Kind() == reflect.Slice && v.IsNil() {
+				return reflect.Zero(reflect.SliceOf(et)), nil
+			}
+
+			sv := reflect.MakeSlice(reflect.SliceOf(et), v.Len(), v.Len())
+			for i := 0; i < v.Len(); i++ {
+				ev, err := project(v.Index(i))
+				if err != nil {
+					return reflect.Value{}, err
+				}
+
+				sv.Index(i).Set(ev)
+			}
+
+			return sv, nil
+		}, nil
+	case reflect.Interface:
+		return anyType, func(v reflect.Value) (reflect.Value, error) {
+			if v.IsNil() {
+				return reflect.Zero(anyType), nil
+			}
+
+			_, project, err := projectType(v.Elem().Type(), sel, path, top)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			ev, err := project(v.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			iv := reflect.New(anyType).Elem()
+			iv.Set(ev)
+			return iv, nil
+		}, nil
+	case reflect.Func:
+		return projectSeq(t, sel, path, top)
+	case reflect.Map:
+		return projectMap(t, sel, path)
+	case reflect.Struct:
+		return projectStruct(t, sel, path, top)
+	default:
+		return nil, nil, unknownField(sel, path)
+	}
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+//unknownField returns the error for selecting fields of a value that has none, it names the first
+func unknownField(sel fieldSet, path string) error {
+	names := make([]string, 0, len(sel))
+	for name := range sel {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return &FieldsError{path + names[0]}
+}
+
+//projectSeq projects iterators of items, e.g: iter.Seq
+func projectSeq(t reflect.Type, sel fieldSet, path string, top bool) (reflect.Type, projection, error) {
+	if t.NumIn() != 1 || t.NumOut() != 0 || t.In(0).Kind() != reflect.Func || t.In(0).NumIn() != 1 ||
+		t.In(0).NumOut() != 1 || t.In(0).Out(0).Kind() != reflect.Bool {
+		return nil, nil, unknownField(sel, path)
+	}
+
+	et, project, err := projectType(t.In(0).In(0), sel, path, top)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	yieldType := reflect.FuncOf([]reflect.Type{et}, []reflect.Type{t.In(0).Out(0)}, false)
+	seqType := reflect.FuncOf([]reflect.Type{yieldType}, nil, false)
+	return seqType, func(v reflect.Value) (reflect.Value, error) {
+		return reflect.MakeFunc(seqType, func(args []reflect.Value) []reflect.Value {
+			v.Call([]reflect.Value{reflect.MakeFunc(t.In(0), func(items []reflect.Value) []reflect.Value {
+				ev, err := project(items[0])
+				if err != nil {
+					return []reflect.Value{reflect.ValueOf(false)} //only dynamic values can fail, they end the items
+				}
+
+				return args[0].Call([]reflect.Value{ev})
+			})})
+
+			return nil
+		}), nil
+	}, nil
+}
+
+//projectMap keeps the selected keys of maps with string keys
+func projectMap(t reflect.Type, sel fieldSet, path string) (reflect.Type, projection, error) {
+	if t.Key().Kind() != reflect.String {
+		return nil, nil, unknownField(sel, path)
+	}
+
+	mt, nested := t, map[string]projection{}
+	for name, sub := range sel {
+		if sub == nil {
+			continue
+		}
+
+		_, project, err := projectType(t.Elem(), sub, path+name+".", false)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		mt, nested[name] = reflect.MapOf(t.Key(), anyType), project
+	}
+
+	return mt, func(v reflect.Value) (reflect.Value, error) {
+		if v.IsNil() {
+			return reflect.Zero(mt), nil
+		}
+
+		mv := reflect.MakeMapWithSize(mt, len(sel))
+		for name := range sel {
+			key := reflect.ValueOf(name).Convert(t.Key())
+			ev := v.MapIndex(key)
+			if !ev.IsValid() {
+				continue
+			}
+
+			if project, ok := nested[name]; ok {
+				pv, err := project(ev)
+				if err != nil {
+					return reflect.Value{}, err
+				}
+
+				ev = pv
+			}
+
+			mv.SetMapIndex(key, ev)
+		}
+
+		return mv, nil
+	}, nil
+}
+
+//nameTags returns the tag of field 'f' with the 'json' and 'xml' names set explicitly, the JSON name is
+//used for CSV too
+func nameTags(f reflect.StructField, jsonName string) reflect.StructTag {
+	tag := setTag(f.Tag, "json", jsonName)
+	if name, _, _ := strings.Cut(f.Tag.Get("xml"), ","); name == "" {
+		tag = setTag(tag, "xml", f.Name)
+	}
+
+	return tag
+}
+
+//setTag sets the name of tag 'key' to 'name' while keeping its options
+func setTag(tag reflect.StructTag, key, name string) reflect.StructTag {
+	val, ok := tag.Lookup(key)
+	if _, opts, hasOpts := strings.Cut(val, ","); hasOpts {
+		name += "," + opts
+	}
+
+	entry := key + ":" + strconv.Quote(name)
+	if !ok {
+		return reflect.StructTag(strings.TrimSpace(string(tag) + " " + entry))
+	}
+
+	old := key + ":" + strconv.Quote(val)
+	return reflect.StructTag(strings.Replace(string(tag), old, entry, 1))
+}
+
+//projectStruct builds a struct type with the selected fields, promoted fields become fields of their own
+func projectStruct(t reflect.Type, sel fieldSet, path string, top bool) (reflect.Type, projection, error) {
+	type selected struct {
+		index   []int
+		project projection
+	}
+
+	var (
+		fields []reflect.StructField
+		copies []selected
+		names  = map[string]bool{}
+		found  = map[string]bool{}
+	)
+
+	if f, ok := t.FieldByName("XMLName"); ok && f.Type == xmlNameType {
+		fields, copies = append(fields, reflect.StructField{Name: f.Name, Type: f.Type, Tag: f.Tag}), append(copies, selected{index: f.Index})
+		names[f.Name] = true
+	} else if top && t.Name() != "" {
+		fields = append(fields, reflect.StructField{Name: "XMLName", Type: xmlNameType, Tag: reflect.StructTag(`xml:"` + t.Name() + `" json:"-" csv:"-"`)})
+		copies = append(copies, selected{})
+		names["XMLName"] = true
+	}
+
+	bfs := append([]binaryField{}, binaryFields(t)...)
+	sort.Slice(bfs, func(i, j int) bool { return slices.Compare(bfs[i].index, bfs[j].index) < 0 }) //in the order of json
+	for _, bf := range bfs {
+		sub, ok := sel[bf.name]
+		if !ok {
+			continue
+		}
+
+		found[bf.name] = true
+		f := t.FieldByIndex(bf.index)
+		field := reflect.StructField{Name: f.Name, Type: f.Type, Tag: f.Tag}
+		for i := 1; names[field.Name]; i++ {
+			field.Name = fmt.Sprintf("%s%d", f.Name, i) //promoted fields may share their Go name
+		}
+
+		if field.Name != f.Name {
+			field.Tag = nameTags(f, bf.name) //such that it is still encoded by its original name
+		}
+
+		var project projection
+		if sub != nil {
+			ft, fp, err := projectType(f.Type, sub, path+bf.name+".", false)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			field.Type, project = ft, fp
+		}
+
+		names[field.Name] = true
+		fields, copies = append(fields, field), append(copies, selected{bf.index, project})
+	}
+
+	for name := range sel {
+		if !found[name] {
+			return nil, nil, &FieldsError{path + name}
+		}
+	}
+
+	st := reflect.StructOf(fields)
+	return st, func(v reflect.Value) (reflect.Value, error) {
+		sv := reflect.New(st).Elem()
+		for i, c := range copies {
+			if c.index == nil {
+				continue
+			}
+
+			fv, ok := fieldByIndex(v, c.index, false)
+			if !ok || !fv.CanInterface() {
+				continue
+			}
+
+			if c.project != nil {
+				pv, err := c.project(fv)
+				if err != nil {
+					return reflect.Value{}, err
+				}
+
+				fv = pv
+			}
+
+			sv.Field(i).Set(fv)
+		}
+
+		return sv, nil
+	}, nil
+}
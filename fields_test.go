@@ -0,0 +1,100 @@
+package httpio_test
+
+import (
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+type fieldsTestOwner struct {
+	Email string `json:"email" xml:"email"`
+	Name  string `json:"name" xml:"name"`
+}
+
+type fieldsTestMeta struct {
+	Created time.Time `json:"created" xml:"created"`
+}
+
+type fieldsTestItem struct {
+	fieldsTestMeta
+	ID    int                    `json:"id" xml:"id"`
+	Name  string                 `json:"name" xml:"name"`
+	Owner *fieldsTestOwner       `json:"owner,omitempty" xml:"owner"`
+	Extra map[string]interface{} `json:"extra,omitempty" xml:"-" csv:"-"`
+}
+
+type fieldsTestLabel struct {
+	Name string `json:"label"`
+}
+
+type fieldsTestLabeled struct {
+	fieldsTestLabel
+	Name string
+}
+
+func fieldsTestItems() []fieldsTestItem {
+	return []fieldsTestItem{
+		{
+			fieldsTestMeta: fieldsTestMeta{time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+			ID:             1, Name: "a", Owner: &fieldsTestOwner{"a@example.com", "alice"},
+			Extra: map[string]interface{}{"color": "red", "size": 2},
+		},
+		{ID: 2, Name: "b"},
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	items := fieldsTestItems()
+	var seq iter.Seq[fieldsTestItem] = func(yield func(fieldsTestItem) bool) {
+		for _, it := range items {
+			if !yield(it) {
+				return
+			}
+		}
+	}
+
+	e := httpio.NewEgress(&httpio.JSON{}, &httpio.XML{}, &httpio.CSV{})
+	e.Use(httpio.SelectFields)
+	for _, c := range []struct {
+		Name    string
+		Query   string
+		Accept  string
+		Output  interface{}
+		ExpCode int
+		ExpBody string
+	}{
+		{"no selection", "", "application/json", items[1], http.StatusOK, `{"created":"0001-01-01T00:00:00Z","id":2,"name":"b"}` + "\n"},
+		{"nested", "?fields=id,owner.email", "application/json", &items[0], http.StatusOK, `{"id":1,"owner":{"email":"a@example.com"}}` + "\n"},
+		{"slice", "?fields=name&fields=created", "application/json", items, http.StatusOK,
+			`[{"created":"2024-01-02T00:00:00Z","name":"a"},{"created":"0001-01-01T00:00:00Z","name":"b"}]` + "\n"},
+		{"whole overrules nested", "?fields=owner.email,owner", "application/json", items[0], http.StatusOK,
+			`{"owner":{"email":"a@example.com","name":"alice"}}` + "\n"},
+		{"map keys", "?fields=extra.color,extra.shape", "application/json", items[0], http.StatusOK, `{"extra":{"color":"red"}}` + "\n"},
+		{"xml", "?fields=id,owner.email", "application/xml", items[0], http.StatusOK,
+			`<fieldsTestItem><id>1</id><owner><email>a@example.com</email></owner></fieldsTestItem>`},
+		{"csv", "?fields=id,owner.email", "text/csv", items, http.StatusOK, "id,owner.email\n1,a@example.com\n2,\n"},
+		{"csv iterator", "?fields=name", "text/csv", seq, http.StatusOK, "name\na\nb\n"},
+		{"promoted go names", "?fields=label,Name", "application/json", fieldsTestLabeled{fieldsTestLabel{"l"}, "n"}, http.StatusOK,
+			`{"label":"l","Name":"n"}` + "\n"},
+		{"parentheses are no nesting", "?fields=id,owner(email)", "application/json", &items[0], http.StatusBadRequest,
+			`{"Field":"owner(email)"}` + "\n"},
+		{"unknown field", "?fields=id,owner.phone", "application/json", items, http.StatusBadRequest,
+			`{"Field":"owner.phone"}` + "\n"},
+		{"scalar field", "?fields=id.value", "application/json", items[0], http.StatusBadRequest, `{"Field":"id.value"}` + "\n"},
+		{"empty segment", "?fields=owner..email", "application/json", items[0], http.StatusBadRequest, `{"Field":"owner..email"}` + "\n"},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/"+c.Query, nil)
+			r.Header.Set("Accept", c.Accept)
+			w := httptest.NewRecorder()
+			e.MustRender(c.Output, w, r)
+			if w.Code != c.ExpCode || w.Body.String() != c.ExpBody {
+				t.Fatalf("expected %d %s, got: %d %s", c.ExpCode, c.ExpBody, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -1,16 +1,22 @@
 package httpio
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/url"
+	"sort"
+	"strings"
 )
 
 var (
 	//MediaTypeForm identifies form content
 	MediaTypeForm = "application/x-www-form-urlencoded"
+
+	//FormMaxSize limits the size of form bodies that are decoded, it matches the limit of
+	//http.Request.ParseForm
+	FormMaxSize int64 = 10 << 20
 )
 
 //FormDecodeProvider can be implemented to provide decoding of form maps into structs
@@ -25,56 +31,122 @@ type FormEncodeProvider interface {
 	Encode(src interface{}, dst map[string][]string) error
 }
 
-//FormEncoder uses the form encoding provider to implement the Encoding interface
+//FormEncoder uses the form encoding provider, or else the FormCodec, to implement the Encoding interface
 type FormEncoder struct {
 	enc FormEncodeProvider
 	w   io.Writer
 }
 
-//Encode the value v into the encoder writer
+//Encode the value v into the encoder writer, the pairs are sorted by key
 func (e *FormEncoder) Encode(v interface{}) error {
-	if e.enc == nil {
-		return errors.New("no form encoder configured")
+	enc := e.enc
+	if enc == nil {
+		enc = FormCodec{}
 	}
 
 	vals := url.Values{}
-	err := e.enc.Encode(v, vals)
+	err := enc.Encode(v, vals)
 	if err != nil {
 		return err
 	}
 
-	_, err = fmt.Fprintf(e.w, vals.Encode())
-	return err
+	keys := make([]string, 0, len(vals))
+	for key := range vals {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	bw := bufio.NewWriter(e.w)
+	sep := false
+	for _, key := range keys {
+		for _, val := range vals[key] {
+			if sep {
+				bw.WriteByte('&')
+			}
+
+			sep = true
+
+			bw.WriteString(url.QueryEscape(key))
+			bw.WriteByte('=')
+			bw.WriteString(url.QueryEscape(val))
+		}
+	}
+
+	return bw.Flush()
 }
 
-//FormDecoder uses the form encoding provider to implement the Encoding interface
+//FormDecoder uses the form decoding provider, or else the FormCodec, to implement the Encoding interface
 type FormDecoder struct {
 	dec FormDecodeProvider
 	r   io.Reader
 }
 
-//Decode into v from the reader
+//Decode into v from the reader. The body is parsed pair by pair and may not exceed FormMaxSize, errors
+//name the field that failed but leave out the values as they may hold sensitive input
 func (e *FormDecoder) Decode(v interface{}) error {
-	if e.dec == nil {
-		return errors.New("no form decoder configured")
-	}
-
-	data, err := ioutil.ReadAll(e.r)
+	vals, err := parseForm(e.r)
 	if err != nil {
 		return err
 	}
 
-	vals, err := url.ParseQuery(string(data))
-	if err != nil {
-		return err
+	dec := e.dec
+	if dec == nil {
+		dec = FormCodec{}
 	}
 
-	err = e.dec.Decode(v, vals)
+	err = dec.Decode(v, vals)
 	if err != nil {
-		return fmt.Errorf("failed to decode into %v from %v: %v", v, vals, err)
+		var ferr *FormFieldError
+		if errors.As(err, &ferr) {
+			return err
+		}
+
+		return fmt.Errorf("httpio/form: failed to decode into %T: %w", v, err)
 	}
 
-	return err
+	return nil
+}
+
+//parseForm reads url encoded pairs from 'r' until EOF, like url.ParseQuery but without buffering the
+//whole body
+func parseForm(r io.Reader) (url.Values, error) {
+	vals := url.Values{}
+	br := bufio.NewReader(io.LimitReader(r, FormMaxSize+1))
+	for n := int64(0); ; {
+		pair, rerr := br.ReadString('&')
+		if rerr != nil && rerr != io.EOF {
+			return nil, rerr
+		}
+
+		n += int64(len(pair))
+		if n > FormMaxSize {
+			return nil, fmt.Errorf("httpio/form: body exceeds %d bytes", FormMaxSize)
+		}
+
+		pair = strings.TrimSuffix(pair, "&")
+		if pair != "" {
+			key, val, _ := strings.Cut(pair, "=")
+			if strings.Contains(pair, ";") {
+				return nil, errors.New("httpio/form: invalid semicolon separator")
+			}
+
+			key, err := url.QueryUnescape(key)
+			if err != nil {
+				return nil, fmt.Errorf("httpio/form: invalid escaping of key: %v", err)
+			}
+
+			val, err = url.QueryUnescape(val)
+			if err != nil {
+				return nil, &FormFieldError{key, errors.New("invalid escaping")}
+			}
+
+			vals[key] = append(vals[key], val)
+		}
+
+		if rerr == io.EOF {
+			return vals, nil
+		}
+	}
 }
 
 type formEncoderFactory struct {
@@ -97,12 +169,14 @@ func (e *formDecoderFactory) MimeType() string { return MediaTypeForm }
 //Decoder will create decoders
 func (e *formDecoderFactory) Decoder(r io.Reader) Decoder { return &FormDecoder{e.dec, r} }
 
-//NewFormEncoding creates the factory using a provider, often third party library
+//NewFormEncoding creates the factory using a provider, often third party library. If 'p' is nil the
+//built-in FormCodec is used
 func NewFormEncoding(p FormEncodeProvider) EncoderFactory {
 	return &formEncoderFactory{p}
 }
 
-//NewFormDecoding creates the factory using a provider, often third party library
+//NewFormDecoding creates the factory using a provider, often third party library. If 'p' is nil the
+//built-in FormCodec is used
 func NewFormDecoding(p FormDecodeProvider) DecoderFactory {
 	return &formDecoderFactory{p}
 }
@@ -0,0 +1,348 @@
+package httpio
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//FormMaxIndex limits the index of slice elements in form keys, e.g: "items.3.name", such that a single
+//key cannot cause a large slice to be allocated
+var FormMaxIndex = 1000
+
+//FormCodec encodes and decodes structs and maps to and from form values using reflection, it is used
+//when no third-party provider is configured. Fields are named by their 'form' tag, their 'schema' tag or
+//else by their name, while decoding names also match case-insensitively. Nested structs and maps use
+//dotted keys, e.g: "address.city", slices of structs are indexed, e.g: "items.0.name", and other slices
+//take all values of their key. Values implementing encoding.TextMarshaler, such as time.Time, are encoded
+//as text. Keys that match no field are ignored while decoding
+type FormCodec struct{}
+
+//FormFieldError is returned when a form value cannot be decoded into its field. The message names the
+//field but leaves out the value, which may be sensitive input such as a password
+type FormFieldError struct {
+	Field string
+	Err   error
+}
+
+//Error names the field that could not be decoded
+func (e *FormFieldError) Error() string {
+	return fmt.Sprintf("httpio/form: invalid value for field '%s'", e.Field)
+}
+
+//Unwrap returns the cause, which may mention the value
+func (e *FormFieldError) Unwrap() error { return e.Err }
+
+//formField is a struct field as it is encoded, named and omitted according to its tags
+type formField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+var formFieldCache sync.Map
+
+//formFields returns the fields of struct type 't', fields of embedded structs are promoted
+func formFields(t reflect.Type) []formField {
+	if fields, ok := formFieldCache.Load(t); ok {
+		return fields.([]formField)
+	}
+
+	fields := formStructFields(t, nil)
+	formFieldCache.Store(t, fields)
+	return fields
+}
+
+func formStructFields(t reflect.Type, index []int) (fields []formField) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("form")
+		if tag == "" {
+			tag = f.Tag.Get("schema")
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+
+		fidx := append(append([]int{}, index...), i)
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct && !formText(ft) {
+				fields = append(fields, formStructFields(ft, fidx)...)
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fields = append(fields, formField{name, fidx, hasTagOpt(opts, "omitempty")})
+	}
+
+	return fields
+}
+
+//formText reports whether values of type 't' are encoded as text rather than field by field
+func formText(t reflect.Type) bool {
+	return t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+//formNested reports whether values of type 't' are decoded from keys with more parts, e.g: "address.city"
+func formNested(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return !formText(t)
+	case reflect.Map:
+		return true
+	case reflect.Slice:
+		et := t.Elem()
+		for et.Kind() == reflect.Pointer {
+			et = et.Elem()
+		}
+
+		return et.Kind() == reflect.Struct && !formText(et) //indexed, e.g: "items.0.name"
+	}
+
+	return false
+}
+
+//formKey joins the key of a nested value to the key of its parent
+func formKey(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return parent + "." + name
+}
+
+//Encode the struct or map 'src' into the form values 'dst'
+func (c FormCodec) Encode(src interface{}, dst map[string][]string) error {
+	v := reflect.ValueOf(src)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if (v.Kind() != reflect.Struct || formText(v.Type())) && v.Kind() != reflect.Map {
+		return fmt.Errorf("httpio/form: cannot encode %T, expected a struct or map", src)
+	}
+
+	return c.encode(v, "", dst)
+}
+
+func (c FormCodec) encode(v reflect.Value, key string, dst map[string][]string) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	if tm, ok := textMarshaler(v); ok {
+		text, err := tm.MarshalText()
+		if err != nil {
+			return fmt.Errorf("httpio/form: failed to encode '%s': %v", key, err)
+		}
+
+		dst[key] = append(dst[key], string(text))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for _, f := range formFields(v.Type()) {
+			fv, ok := fieldByIndex(v, f.index, false)
+			if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+				continue
+			}
+
+			err := c.encode(fv, formKey(key, f.name), dst)
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for iter := v.MapRange(); iter.Next(); {
+			err := c.encode(iter.Value(), formKey(key, fmt.Sprint(iter.Key().Interface())), dst)
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			dst[key] = append(dst[key], string(v.Bytes()))
+			return nil
+		}
+
+		et := v.Type().Elem()
+		for et.Kind() == reflect.Pointer {
+			et = et.Elem()
+		}
+
+		indexed := et.Kind() == reflect.Struct && !formText(et)
+		for i := 0; i < v.Len(); i++ {
+			ekey := key
+			if indexed {
+				ekey = formKey(key, strconv.Itoa(i))
+			}
+
+			err := c.encode(v.Index(i), ekey, dst)
+			if err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		dst[key] = append(dst[key], v.String())
+	case reflect.Bool:
+		dst[key] = append(dst[key], strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		dst[key] = append(dst[key], strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		dst[key] = append(dst[key], strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		dst[key] = append(dst[key], strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()))
+	default:
+		return fmt.Errorf("httpio/form: cannot encode '%s' of type %s", key, v.Type())
+	}
+
+	return nil
+}
+
+//Decode the form values 'src' into the struct or map that 'dst' points to
+func (c FormCodec) Decode(dst interface{}, src map[string][]string) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() ||
+		((v.Elem().Kind() != reflect.Struct || formText(v.Elem().Type())) && v.Elem().Kind() != reflect.Map) {
+		return fmt.Errorf("httpio/form: cannot decode into %T, expected a pointer to a struct or map", dst)
+	}
+
+	keys := make([]string, 0, len(src))
+	for key := range src {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys) //such that errors are reported deterministically
+	for _, key := range keys {
+		if len(src[key]) < 1 {
+			continue
+		}
+
+		err := c.decode(v.Elem(), strings.Split(key, "."), src[key])
+		if err != nil {
+			return &FormFieldError{key, err}
+		}
+	}
+
+	return nil
+}
+
+func (c FormCodec) decode(v reflect.Value, path []string, vals []string) error {
+	if len(path) < 1 {
+		if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+			if len(vals) == 1 {
+				v.Set(reflect.ValueOf(vals[0]))
+			} else {
+				v.Set(reflect.ValueOf(append([]string{}, vals...)))
+			}
+
+			return nil
+		}
+
+		return setField(v, vals)
+	}
+
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		f := formFind(formFields(v.Type()), path[0])
+		if f == nil {
+			return nil
+		}
+
+		fv, _ := fieldByIndex(v, f.index, true)
+		return c.decode(fv, path[1:], vals)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map key type %s", v.Type().Key())
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+
+		rest := path[1:]
+		if !formNested(v.Type().Elem()) {
+			rest = nil //values of other types take the rest of the key, dots included
+		}
+
+		key := reflect.ValueOf(strings.Join(path[:len(path)-len(rest)], ".")).Convert(v.Type().Key())
+		ev := reflect.New(v.Type().Elem()).Elem()
+		if cur := v.MapIndex(key); cur.IsValid() {
+			ev.Set(cur)
+		}
+
+		err := c.decode(ev, rest, vals)
+		if err != nil {
+			return err
+		}
+
+		v.SetMapIndex(key, ev)
+	case reflect.Slice:
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 || i > FormMaxIndex {
+			return fmt.Errorf("invalid index '%s'", path[0])
+		}
+
+		if i >= v.Len() {
+			grown := reflect.MakeSlice(v.Type(), i+1, i+1)
+			reflect.Copy(grown, v)
+			v.Set(grown)
+		}
+
+		return c.decode(v.Index(i), path[1:], vals)
+	}
+
+	return nil
+}
+
+//formFind returns the field named 'name', or else one with a name that matches case-insensitively
+func formFind(fields []formField, name string) *formField {
+	for i := range fields {
+		if fields[i].name == name {
+			return &fields[i]
+		}
+	}
+
+	for i := range fields {
+		if strings.EqualFold(fields[i].name, name) {
+			return &fields[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package httpio_test
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+type formTestAddress struct {
+	City string `form:"city"`
+	Zip  string `schema:"zip"`
+}
+
+type formTestLine struct {
+	SKU string `form:"sku"`
+	Qty int    `form:"qty"`
+}
+
+type formTestMeta struct {
+	Source string `form:"source"`
+}
+
+type formTestOrder struct {
+	formTestMeta
+	Name     string           `form:"name"`
+	Note     string           `form:"note,omitempty"`
+	Tags     []string         `form:"tags"`
+	Count    *int             `form:"count"`
+	Paid     bool             `form:"paid"`
+	Price    float64          `form:"price"`
+	Created  time.Time        `form:"created"`
+	IP       net.IP           `form:"ip"`
+	Address  formTestAddress  `form:"address"`
+	Billing  *formTestAddress `form:"billing"`
+	Lines    []formTestLine   `form:"lines"`
+	Password string           `form:"-"`
+}
+
+func formTestValue() *formTestOrder {
+	n := 3
+	return &formTestOrder{
+		formTestMeta: formTestMeta{Source: "web"},
+		Name:         "Alice & Bob",
+		Tags:         []string{"a", "b"},
+		Count:        &n,
+		Paid:         true,
+		Price:        9.5,
+		Created:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		IP:           net.IPv4(10, 0, 0, 1),
+		Address:      formTestAddress{City: "Amsterdam", Zip: "1000AA"},
+		Lines:        []formTestLine{{"x1", 1}, {"x2", 2}},
+	}
+}
+
+const formTestEncoded = "address.city=Amsterdam&address.zip=1000AA&count=3&created=2024-01-02T03%3A04%3A05Z&ip=10.0.0.1&" +
+	"lines.0.qty=1&lines.0.sku=x1&lines.1.qty=2&lines.1.sku=x2&name=Alice+%26+Bob&paid=true&price=9.5&source=web&tags=a&tags=b"
+
+func TestFormCodec(t *testing.T) {
+	vals := url.Values{}
+	err := httpio.FormCodec{}.Encode(formTestValue(), vals)
+	if err != nil || vals.Encode() != formTestEncoded {
+		t.Fatalf("expected:\n%s\ngot:\n%s %v", formTestEncoded, vals.Encode(), err)
+	}
+
+	vals, _ = url.ParseQuery(formTestEncoded + "&password=secret&unknown=1&ADDRESS.CITY=Amsterdam")
+	v := &formTestOrder{}
+	err = httpio.FormCodec{}.Decode(v, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := formTestValue(); !reflect.DeepEqual(v, exp) {
+		t.Fatalf("expected %+v, got: %+v", exp, v)
+	}
+
+	m := map[string]interface{}{}
+	err = httpio.FormCodec{}.Decode(&m, url.Values{"a.b": {"1"}, "c": {"2", "3"}})
+	if exp := map[string]interface{}{"a.b": "1", "c": []string{"2", "3"}}; err != nil || !reflect.DeepEqual(m, exp) {
+		t.Fatalf("expected %v, got: %v %v", exp, m, err)
+	}
+
+	nested := map[string]formTestAddress{}
+	err = httpio.FormCodec{}.Decode(&nested, url.Values{"home.city": {"Paris"}, "home.zip": {"75001"}})
+	if exp := map[string]formTestAddress{"home": {"Paris", "75001"}}; err != nil || !reflect.DeepEqual(nested, exp) {
+		t.Fatalf("expected %v, got: %v %v", exp, nested, err)
+	}
+}
+
+func TestFormCodecErrors(t *testing.T) {
+	for _, c := range []struct {
+		Name     string
+		Input    string
+		Dst      interface{}
+		ExpErr   string
+		ExpCause string
+	}{
+		{"invalid number", "count=x", &formTestOrder{}, "httpio/form: invalid value for field 'count'", `strconv.ParseInt: parsing "x": invalid syntax`},
+		{"invalid time", "created=yesterday", &formTestOrder{}, "httpio/form: invalid value for field 'created'", `parsing time "yesterday" as "2006-01-02T15:04:05Z07:00": cannot parse "yesterday" as "2006"`},
+		{"index too large", "lines.1001.sku=x", &formTestOrder{}, "httpio/form: invalid value for field 'lines.1001.sku'", "invalid index '1001'"},
+		{"nested struct value", "address=x", &formTestOrder{}, "httpio/form: invalid value for field 'address'", "unsupported field type httpio_test.formTestAddress"},
+		{"not a pointer", "name=x", formTestOrder{}, "httpio/form: cannot decode into httpio_test.formTestOrder, expected a pointer to a struct or map", ""},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			vals, _ := url.ParseQuery(c.Input)
+			err := httpio.FormCodec{}.Decode(c.Dst, vals)
+			if err == nil || err.Error() != c.ExpErr {
+				t.Fatalf("expected error '%s', got: %v", c.ExpErr, err)
+			}
+
+			var ferr *httpio.FormFieldError
+			if c.ExpCause != "" && (!errors.As(err, &ferr) || ferr.Err.Error() != c.ExpCause) {
+				t.Fatalf("expected cause '%s', got: %v", c.ExpCause, ferr)
+			}
+		})
+	}
+
+	err := httpio.FormCodec{}.Encode([]string{"a"}, url.Values{})
+	if exp := "httpio/form: cannot encode []string, expected a struct or map"; err == nil || err.Error() != exp {
+		t.Fatalf("expected error '%s', got: %v", exp, err)
+	}
+
+	err = httpio.FormCodec{}.Encode(struct{ C chan int }{}, url.Values{})
+	if exp := "httpio/form: cannot encode 'C' of type chan int"; err == nil || err.Error() != exp {
+		t.Fatalf("expected error '%s', got: %v", exp, err)
+	}
+}
@@ -0,0 +1,62 @@
+package httpio_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+type formTestLogin struct {
+	User     string `form:"user"`
+	Password int    `form:"password"`
+}
+
+func TestFormEncoder(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	err := httpio.NewFormEncoding(nil).Encoder(buf).Encode(map[string]interface{}{"q": "100% %s", "a": []string{"1", "2"}})
+	if exp := "a=1&a=2&q=100%25+%25s"; err != nil || buf.String() != exp {
+		t.Fatalf("expected %q, got: %q %v", exp, buf.String(), err)
+	}
+}
+
+func TestFormDecoder(t *testing.T) {
+	v := &formTestLogin{}
+	err := httpio.NewFormDecoding(nil).Decoder(strings.NewReader("user=a%26b&&password=42&")).Decode(v)
+	if err != nil || v.User != "a&b" || v.Password != 42 {
+		t.Fatalf("unexpected decoded value: %+v %v", v, err)
+	}
+
+	for _, c := range []struct {
+		Name   string
+		Input  string
+		ExpErr string
+	}{
+		{"value left out", "user=a&password=hunter2", "httpio/form: invalid value for field 'password'"},
+		{"invalid escaping", "user=a&password=%zz", "httpio/form: invalid value for field 'password'"},
+		{"semicolon", "user=a;password=1", "httpio/form: invalid semicolon separator"},
+		{"too large", "user=" + strings.Repeat("a", 100), "httpio/form: body exceeds 64 bytes"},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			defer func(max int64) { httpio.FormMaxSize = max }(httpio.FormMaxSize)
+			httpio.FormMaxSize = 64
+
+			err := httpio.NewFormDecoding(nil).Decoder(strings.NewReader(c.Input)).Decode(&formTestLogin{})
+			if err == nil || err.Error() != c.ExpErr {
+				t.Fatalf("expected error '%s', got: %v", c.ExpErr, err)
+			}
+		})
+	}
+
+	perr := errors.New("provider failed")
+	err = httpio.NewFormDecoding(formTestProvider{perr}).Decoder(strings.NewReader("user=a")).Decode(v)
+	if exp := "httpio/form: failed to decode into *httpio_test.formTestLogin: provider failed"; !errors.Is(err, perr) || err.Error() != exp {
+		t.Fatalf("expected error '%s', got: %v", exp, err)
+	}
+}
+
+type formTestProvider struct{ err error }
+
+func (p formTestProvider) Decode(dst interface{}, src map[string][]string) error { return p.err }
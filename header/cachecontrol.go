@@ -0,0 +1,97 @@
+package header
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheControl holds the directives of a Cache-Control header (RFC 7234) by
+// their lowercase name. Directives without an argument have an empty value.
+type CacheControl map[string]string
+
+// ParseCacheControl parses all values of the Cache-Control header. Only the
+// first occurrence of a directive is used, parsing stops at the first
+// directive that cannot be parsed.
+func ParseCacheControl(header http.Header, key string) CacheControl {
+	cc := CacheControl{}
+loop:
+	for _, s := range header[http.CanonicalHeaderKey(key)] {
+		for {
+			var name, value string
+			name, value, s = expectPair(s)
+			if name == "" {
+				continue loop
+			}
+			if _, ok := cc[name]; !ok {
+				cc[name] = value
+			}
+			if !strings.HasPrefix(s, ",") {
+				continue loop
+			}
+			s = s[1:]
+		}
+	}
+	return cc
+}
+
+// Has reports whether the directive is present.
+func (cc CacheControl) Has(directive string) bool {
+	_, ok := cc[strings.ToLower(directive)]
+	return ok
+}
+
+// Duration returns the delta-seconds argument of a directive such as max-age.
+// It returns false if the directive is not present or has no valid argument.
+// Arguments that overflow are capped at 2^31 seconds.
+func (cc CacheControl) Duration(directive string) (time.Duration, bool) {
+	v, ok := cc[strings.ToLower(directive)]
+	if !ok || v == "" {
+		return 0, false
+	}
+	for i := 0; i < len(v); i++ {
+		if v[i] < '0' || v[i] > '9' {
+			return 0, false
+		}
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n > 1<<31 {
+		n = 1 << 31
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// Fields returns the field names argument of a directive, such as no-cache
+// or private, in canonical form.
+func (cc CacheControl) Fields(directive string) (fields []string) {
+	for _, f := range strings.Split(cc[strings.ToLower(directive)], ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, http.CanonicalHeaderKey(f))
+		}
+	}
+	return fields
+}
+
+// SetDuration sets the delta-seconds argument of a directive, truncated to
+// whole seconds.
+func (cc CacheControl) SetDuration(directive string, d time.Duration) {
+	cc[strings.ToLower(directive)] = strconv.FormatInt(int64(d/time.Second), 10)
+}
+
+// String formats the directives in lexical order as the value of a
+// Cache-Control header.
+func (cc CacheControl) String() string {
+	names := make([]string, 0, len(cc))
+	for name := range cc {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		if v := cc[name]; v != "" {
+			names[i] += "=" + tokenOrQuote(v)
+		}
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,55 @@
+package header
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var parseCacheControlTests = []struct {
+	s        []string
+	expected CacheControl
+}{
+	{[]string{`no-cache`}, CacheControl{"no-cache": ""}},
+	{[]string{`max-age=3600, Must-Revalidate`}, CacheControl{"max-age": "3600", "must-revalidate": ""}},
+	{[]string{`private="Set-Cookie, X-Foo" , max-age="60"`}, CacheControl{"private": "Set-Cookie, X-Foo", "max-age": "60"}},
+	{[]string{`max-age=60`, `max-age=0, s-maxage=10`}, CacheControl{"max-age": "60", "s-maxage": "10"}},
+	{[]string{`public,,no-transform`}, CacheControl{"public": ""}},
+	{[]string{``}, CacheControl{}},
+}
+
+func TestParseCacheControl(t *testing.T) {
+	for _, tt := range parseCacheControlTests {
+		header := http.Header{"Cache-Control": tt.s}
+		actual := ParseCacheControl(header, "Cache-Control")
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("ParseCacheControl(h, %q)=%v, want %v", tt.s, actual, tt.expected)
+		}
+	}
+}
+
+func TestCacheControlDirectives(t *testing.T) {
+	cc := ParseCacheControl(http.Header{"Cache-Control": {`max-age=60, max-stale, no-cache="set-cookie,x-foo", min-fresh=abc, s-maxage=99999999999`}}, "Cache-Control")
+	if d, ok := cc.Duration("Max-Age"); !ok || d != time.Minute {
+		t.Errorf("Duration(max-age)=%v, %v, want 1m0s, true", d, ok)
+	}
+	if _, ok := cc.Duration("max-stale"); ok || !cc.Has("max-stale") {
+		t.Errorf("expected max-stale to be present without a duration")
+	}
+	if _, ok := cc.Duration("min-fresh"); ok {
+		t.Errorf("expected invalid min-fresh to have no duration")
+	}
+	if d, _ := cc.Duration("s-maxage"); d != (1<<31)*time.Second {
+		t.Errorf("expected overflowing s-maxage to be capped, got: %v", d)
+	}
+	if f := cc.Fields("no-cache"); !reflect.DeepEqual(f, []string{"Set-Cookie", "X-Foo"}) {
+		t.Errorf("Fields(no-cache)=%v, want [Set-Cookie X-Foo]", f)
+	}
+
+	cc = CacheControl{"public": "", "private": "Set-Cookie"}
+	cc.SetDuration("max-age", 90*time.Second+time.Millisecond)
+	if s := cc.String(); s != `max-age=90, private=Set-Cookie, public` {
+		t.Errorf("unexpected formatting, got: %s", s)
+	}
+}
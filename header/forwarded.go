@@ -0,0 +1,84 @@
+package header
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Forwarded describes a single forwarded-element of a Forwarded header as
+// defined by RFC 7239. Each element is added by a proxy, the first element
+// describes the request as it was received by the first proxy. Extension
+// parameters are stored in Params with lowercase keys.
+type Forwarded struct {
+	For    string
+	By     string
+	Host   string
+	Proto  string
+	Params map[string]string
+}
+
+// ParseForwarded parses all values of the Forwarded header in order. Parsing
+// stops at the first element that cannot be parsed. Note that elements can
+// be supplied by clients, only trust those added by known proxies.
+func ParseForwarded(header http.Header, key string) (elems []Forwarded) {
+	for _, s := range header[http.CanonicalHeaderKey(key)] {
+		for {
+			elem := Forwarded{Params: make(map[string]string)}
+			seen := map[string]bool{}
+			for {
+				var pkey, pvalue string
+				pkey, pvalue, s = expectPair(s)
+				if pkey == "" || seen[pkey] {
+					return
+				}
+				seen[pkey] = true
+				switch pkey {
+				case "for":
+					elem.For = pvalue
+				case "by":
+					elem.By = pvalue
+				case "host":
+					elem.Host = pvalue
+				case "proto":
+					elem.Proto = strings.ToLower(pvalue)
+				default:
+					elem.Params[pkey] = pvalue
+				}
+				if !strings.HasPrefix(s, ";") {
+					break
+				}
+				s = s[1:]
+			}
+			if s != "" && s[0] != ',' {
+				return
+			}
+			elems = append(elems, elem)
+			if s == "" {
+				break
+			}
+			s = s[1:]
+		}
+	}
+	return
+}
+
+// String formats the element as it would appear in a Forwarded header, values
+// that are not tokens such as IPv6 addresses are quoted.
+func (f Forwarded) String() string {
+	var pairs []string
+	for _, p := range [][2]string{{"for", f.For}, {"by", f.By}, {"host", f.Host}, {"proto", f.Proto}} {
+		if p[1] != "" {
+			pairs = append(pairs, p[0]+"="+tokenOrQuote(p[1]))
+		}
+	}
+	keys := make([]string, 0, len(f.Params))
+	for k := range f.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+tokenOrQuote(f.Params[k]))
+	}
+	return strings.Join(pairs, ";")
+}
@@ -0,0 +1,50 @@
+package header
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+var parseForwardedTests = []struct {
+	s        []string
+	expected []Forwarded
+}{
+	{[]string{`for="_gazonk"`}, []Forwarded{{For: "_gazonk", Params: map[string]string{}}}},
+	{[]string{`For="[2001:db8:cafe::17]:4711"`}, []Forwarded{{For: "[2001:db8:cafe::17]:4711", Params: map[string]string{}}}},
+	{[]string{`for=192.0.2.60;proto=HTTP;by=203.0.113.43;host=example.com`}, []Forwarded{
+		{For: "192.0.2.60", By: "203.0.113.43", Host: "example.com", Proto: "http", Params: map[string]string{}},
+	}},
+	{[]string{`for=192.0.2.43, for=198.51.100.17;secret=x`}, []Forwarded{
+		{For: "192.0.2.43", Params: map[string]string{}},
+		{For: "198.51.100.17", Params: map[string]string{"secret": "x"}},
+	}},
+	{[]string{`for=192.0.2.43`, `for=198.51.100.17`}, []Forwarded{
+		{For: "192.0.2.43", Params: map[string]string{}},
+		{For: "198.51.100.17", Params: map[string]string{}},
+	}},
+
+	// bad cases
+	{[]string{`for=a;for=b`}, nil},
+	{[]string{`for=a, junk junk`}, []Forwarded{{For: "a", Params: map[string]string{}}}},
+}
+
+func TestParseForwarded(t *testing.T) {
+	for _, tt := range parseForwardedTests {
+		header := http.Header{"Forwarded": tt.s}
+		actual := ParseForwarded(header, "Forwarded")
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("ParseForwarded(h, %q)=%v, want %v", tt.s, actual, tt.expected)
+		}
+	}
+}
+
+func TestFormatForwarded(t *testing.T) {
+	f := Forwarded{For: "[2001:db8:cafe::17]:4711", Proto: "https", Host: "example.com", Params: map[string]string{"x": "y"}}
+	if s := f.String(); s != `for="[2001:db8:cafe::17]:4711";host=example.com;proto=https;x=y` {
+		t.Fatalf("unexpected formatting, got: %s", s)
+	}
+	if actual := ParseForwarded(http.Header{"Forwarded": {f.String()}}, "Forwarded"); !reflect.DeepEqual(actual, []Forwarded{f}) {
+		t.Fatalf("expected element to survive a roundtrip, got: %v", actual)
+	}
+}
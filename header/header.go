@@ -296,3 +296,33 @@ func expectTokenOrQuoted(s string) (value string, rest string) {
 	}
 	return "", ""
 }
+
+// expectPair parses a "key [= value]" pair where the value is a token or a
+// quoted-string. The key is returned in lowercase and is empty if the pair
+// cannot be parsed.
+func expectPair(s string) (key, value, rest string) {
+	key, s = expectToken(skipSpace(s))
+	if key == "" {
+		return "", "", s
+	}
+	key = strings.ToLower(key)
+	s = skipSpace(s)
+	if !strings.HasPrefix(s, "=") {
+		return key, "", s
+	}
+	value, s = expectTokenOrQuoted(skipSpace(s[1:]))
+	return key, value, skipSpace(s)
+}
+
+// quote returns s as a quoted-string, escaping quotes and backslashes.
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// tokenOrQuote returns s as is if it is a token and quoted otherwise.
+func tokenOrQuote(s string) string {
+	if t, rest := expectToken(s); t != "" && rest == "" {
+		return s
+	}
+	return quote(s)
+}
@@ -0,0 +1,185 @@
+package header
+
+import (
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Link describes a single link-value of a Link header as defined by RFC 8288.
+// The Rel holds the relation types as a space separated list and the Anchor
+// overrides the context of the link. Other target attributes are stored in
+// Params with lowercase keys. Extended values (RFC 8187) such as "title*" are
+// stored decoded.
+type Link struct {
+	URL    string
+	Rel    string
+	Anchor string
+	Params map[string]string
+}
+
+// Rels returns the relation types of the link in lowercase.
+func (l Link) Rels() []string {
+	return strings.Fields(strings.ToLower(l.Rel))
+}
+
+// HasRel reports whether the link has relation type rel, case-insensitively.
+func (l Link) HasRel(rel string) bool {
+	for _, r := range l.Rels() {
+		if r == strings.ToLower(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats the link as it would appear in a Link header. Parameters are
+// written in lexical order after the rel and anchor parameters.
+func (l Link) String() string {
+	b := &strings.Builder{}
+	b.WriteString("<" + l.URL + ">")
+	if l.Rel != "" {
+		b.WriteString("; rel=" + quote(l.Rel))
+	}
+	if l.Anchor != "" {
+		b.WriteString("; anchor=" + quote(l.Anchor))
+	}
+	keys := make([]string, 0, len(l.Params))
+	for k := range l.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if strings.HasSuffix(k, "*") {
+			b.WriteString("; " + k + "=UTF-8''" + encodeExtValue(l.Params[k]))
+			continue
+		}
+		b.WriteString("; " + k + "=" + quote(l.Params[k]))
+	}
+	return b.String()
+}
+
+// FormatLinks formats the links as the comma separated value of a single Link
+// header.
+func FormatLinks(links ...Link) string {
+	vals := make([]string, len(links))
+	for i, l := range links {
+		vals[i] = l.String()
+	}
+	return strings.Join(vals, ", ")
+}
+
+// ParseLink parses all values of the Link header. Each value may hold a comma
+// separated list of links. Links that cannot be parsed are skipped. When a
+// link repeats a parameter, only its first occurrence is used.
+func ParseLink(header http.Header, key string) (links []Link) {
+	for _, s := range header[http.CanonicalHeaderKey(key)] {
+		for {
+			var link Link
+			var ok bool
+			link, s, ok = expectLink(skipSpace(s))
+			if ok {
+				links = append(links, link)
+			}
+			i := strings.IndexByte(s, ',')
+			if i < 0 {
+				break
+			}
+			s = s[i+1:]
+		}
+	}
+	return
+}
+
+func expectLink(s string) (link Link, rest string, ok bool) {
+	if !strings.HasPrefix(s, "<") {
+		return link, s, false
+	}
+	end := strings.IndexByte(s, '>')
+	if end < 0 {
+		return link, "", false
+	}
+	link.URL, s = strings.TrimSpace(s[1:end]), skipSpace(s[end+1:])
+	link.Params = make(map[string]string)
+	seen := map[string]bool{}
+	for strings.HasPrefix(s, ";") {
+		var pkey, pvalue string
+		pkey, s = expectToken(skipSpace(s[1:]))
+		if pkey == "" {
+			return link, s, false
+		}
+		pkey = strings.ToLower(pkey)
+		s = skipSpace(s)
+		if strings.HasPrefix(s, "=") {
+			s = skipSpace(s[1:])
+			if strings.HasSuffix(pkey, "*") {
+				pvalue, s = expectToken(s)
+				var ok bool
+				if pvalue, ok = decodeExtValue(pvalue); !ok {
+					return link, s, false
+				}
+			} else {
+				pvalue, s = expectTokenOrQuoted(s)
+			}
+		}
+		s = skipSpace(s)
+		if seen[pkey] {
+			continue
+		}
+		seen[pkey] = true
+		switch pkey {
+		case "rel":
+			link.Rel = pvalue
+		case "anchor":
+			link.Anchor = pvalue
+		default:
+			link.Params[pkey] = pvalue
+		}
+	}
+	if s != "" && s[0] != ',' {
+		return link, s, false
+	}
+	return link, s, true
+}
+
+// decodeExtValue decodes an RFC 8187 ext-value: charset'language'pct-encoded.
+// Only the UTF-8 and ISO-8859-1 charsets are supported.
+func decodeExtValue(s string) (string, bool) {
+	parts := strings.SplitN(s, "'", 3)
+	if len(parts) != 3 {
+		return "", false
+	}
+	v, err := url.PathUnescape(parts[2])
+	if err != nil {
+		return "", false
+	}
+	switch strings.ToUpper(parts[0]) {
+	case "UTF-8":
+		return v, true
+	case "ISO-8859-1":
+		r := make([]rune, len(v))
+		for i := 0; i < len(v); i++ {
+			r[i] = rune(v[i])
+		}
+		return string(r), true
+	}
+	return "", false
+}
+
+// encodeExtValue percent-encodes all octets of s except RFC 8187 attr-chars.
+func encodeExtValue(s string) string {
+	const hex = "0123456789ABCDEF"
+	b := &strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if octetTypes[c]&isToken != 0 && strings.IndexByte("*'%", c) < 0 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&15])
+	}
+	return b.String()
+}
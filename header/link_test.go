@@ -0,0 +1,108 @@
+package header
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+var parseLinkTests = []struct {
+	s        []string
+	expected []Link
+}{
+	{[]string{`<https://example.com/items?page=2>; rel="next"`}, []Link{{"https://example.com/items?page=2", "next", "", map[string]string{}}}},
+	{[]string{`</items?page=2>; rel=next, </items?page=9>; rel="last"`}, []Link{
+		{"/items?page=2", "next", "", map[string]string{}},
+		{"/items?page=9", "last", "", map[string]string{}},
+	}},
+	{[]string{`</items?page=2>; rel=next`, `</items?page=9>; rel=last`}, []Link{
+		{"/items?page=2", "next", "", map[string]string{}},
+		{"/items?page=9", "last", "", map[string]string{}},
+	}},
+	{[]string{`</items>;rel=prev;title="previous, page"`}, []Link{{"/items", "prev", "", map[string]string{"title": "previous, page"}}}},
+	{[]string{`</items>; REL=next; rel=last; Title=a; title=b`}, []Link{{"/items", "next", "", map[string]string{"title": "a"}}}},
+	{[]string{`< /a,b >; rel="next last" , </c>`}, []Link{
+		{"/a,b", "next last", "", map[string]string{}},
+		{"/c", "", "", map[string]string{}},
+	}},
+	{[]string{`<http://example.org/>; rel="start http://example.net/relation/other"`}, []Link{
+		{"http://example.org/", "start http://example.net/relation/other", "", map[string]string{}},
+	}},
+	{[]string{`</terms>; rel="copyright"; anchor="#foo"`}, []Link{{"/terms", "copyright", "#foo", map[string]string{}}}},
+	{[]string{`</TheBook/chapter2>; rel="previous"; title*=UTF-8'de'letztes%20Kapitel`}, []Link{
+		{"/TheBook/chapter2", "previous", "", map[string]string{"title*": "letztes Kapitel"}},
+	}},
+	{[]string{`</chapter4>; title*=iso-8859-1'en'%A3%20rates`}, []Link{{"/chapter4", "", "", map[string]string{"title*": "£ rates"}}}},
+	{[]string{`</style.css>; rel=preload; as=style; nopush`}, []Link{
+		{"/style.css", "preload", "", map[string]string{"as": "style", "nopush": ""}},
+	}},
+	{[]string{`</a>; title="say \"hi\""`}, []Link{{"/a", "", "", map[string]string{"title": `say "hi"`}}}},
+
+	// bad cases
+	{[]string{`/items; rel=next`}, nil},
+	{[]string{`</items; rel=next`}, nil},
+	{[]string{`</a>; rel=next junk, </b>; rel=prev`}, []Link{{"/b", "prev", "", map[string]string{}}}},
+	{[]string{`</a>; title*=UTF-7'en'foo, </b>`}, []Link{{"/b", "", "", map[string]string{}}}},
+	{[]string{`</a>; =next, </b>`}, []Link{{"/b", "", "", map[string]string{}}}},
+}
+
+func TestParseLink(t *testing.T) {
+	for _, tt := range parseLinkTests {
+		header := http.Header{"Link": tt.s}
+		actual := ParseLink(header, "Link")
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("ParseLink(h, %q)=%v, want %v", tt.s, actual, tt.expected)
+		}
+	}
+}
+
+func TestLinkRels(t *testing.T) {
+	l := Link{URL: "/", Rel: " Next  LAST"}
+	if !reflect.DeepEqual(l.Rels(), []string{"next", "last"}) {
+		t.Errorf("Rels()=%v, want [next last]", l.Rels())
+	}
+	if !l.HasRel("last") || !l.HasRel("NEXT") || l.HasRel("prev") {
+		t.Errorf("HasRel reported unexpected relation types for %q", l.Rel)
+	}
+}
+
+var formatLinkTests = []struct {
+	links    []Link
+	expected string
+}{
+	{[]Link{{URL: "/items?page=2", Rel: "next"}}, `</items?page=2>; rel="next"`},
+	{[]Link{{URL: "/a", Rel: "next"}, {URL: "/b", Rel: "prev last"}}, `</a>; rel="next", </b>; rel="prev last"`},
+	{[]Link{{URL: "/a", Anchor: "#x", Params: map[string]string{"type": "text/html", "title": `a "b" \c`}}}, `</a>; anchor="#x"; title="a \"b\" \\c"; type="text/html"`},
+	{[]Link{{URL: "/a", Params: map[string]string{"title*": "£ rates"}}}, `</a>; title*=UTF-8''%C2%A3%20rates`},
+}
+
+func TestFormatLinks(t *testing.T) {
+	for _, tt := range formatLinkTests {
+		if actual := FormatLinks(tt.links...); actual != tt.expected {
+			t.Errorf("FormatLinks(%v)=%s, want %s", tt.links, actual, tt.expected)
+		}
+	}
+}
+
+func FuzzParseLink(f *testing.F) {
+	for _, tt := range parseLinkTests {
+		for _, s := range tt.s {
+			f.Add(s)
+		}
+	}
+	for _, tt := range formatLinkTests {
+		f.Add(tt.expected)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		links := ParseLink(http.Header{"Link": {s}}, "Link")
+		if len(links) < 1 {
+			return
+		}
+
+		formatted := FormatLinks(links...)
+		if actual := ParseLink(http.Header{"Link": {formatted}}, "Link"); !reflect.DeepEqual(actual, links) {
+			t.Errorf("ParseLink(FormatLinks(%q))=%q, want %q", formatted, actual, links)
+		}
+	})
+}
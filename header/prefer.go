@@ -0,0 +1,122 @@
+package header
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Preference describes a single preference of a Prefer header as defined by
+// RFC 7240, for example: return=minimal or respond-async. The name and the
+// keys of Params are lowercase.
+type Preference struct {
+	Name   string
+	Value  string
+	Params map[string]string
+}
+
+// String formats the preference as it would appear in a Prefer header.
+func (p Preference) String() string {
+	s := p.Name
+	if p.Value != "" {
+		s += "=" + tokenOrQuote(p.Value)
+	}
+	keys := make([]string, 0, len(p.Params))
+	for k := range p.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s += "; " + k
+		if v := p.Params[k]; v != "" {
+			s += "=" + tokenOrQuote(v)
+		}
+	}
+	return s
+}
+
+// Preferences holds the preferences of a Prefer header in order.
+type Preferences []Preference
+
+// ParsePrefer parses all values of the Prefer header. Only the first
+// occurrence of a preference is used, preferences that cannot be parsed are
+// skipped.
+func ParsePrefer(header http.Header, key string) (prefs Preferences) {
+	seen := map[string]bool{}
+	for _, s := range header[http.CanonicalHeaderKey(key)] {
+		for {
+			var pref Preference
+			pref.Name, pref.Value, s = expectPair(s)
+			pref.Params = make(map[string]string)
+			for pref.Name != "" && strings.HasPrefix(s, ";") {
+				var pkey, pvalue string
+				pkey, pvalue, s = expectPair(s[1:])
+				if pkey == "" {
+					continue
+				}
+				if _, ok := pref.Params[pkey]; !ok {
+					pref.Params[pkey] = pvalue
+				}
+			}
+			if pref.Name != "" && !seen[pref.Name] && (s == "" || s[0] == ',') {
+				seen[pref.Name] = true
+				prefs = append(prefs, pref)
+			}
+			i := strings.IndexByte(s, ',')
+			if i < 0 {
+				break
+			}
+			s = s[i+1:]
+		}
+	}
+	return
+}
+
+// Get returns the preference with the given name.
+func (prefs Preferences) Get(name string) (Preference, bool) {
+	for _, p := range prefs {
+		if p.Name == strings.ToLower(name) {
+			return p, true
+		}
+	}
+	return Preference{}, false
+}
+
+// Return returns the value of the return preference: "minimal" or
+// "representation", or an empty string if it was not expressed.
+func (prefs Preferences) Return() string {
+	p, _ := prefs.Get("return")
+	return strings.ToLower(p.Value)
+}
+
+// RespondAsync reports whether the respond-async preference was expressed.
+func (prefs Preferences) RespondAsync() bool {
+	_, ok := prefs.Get("respond-async")
+	return ok
+}
+
+// Wait returns the duration of the wait preference, it returns false if the
+// preference was not expressed or has an invalid value.
+func (prefs Preferences) Wait() (time.Duration, bool) {
+	p, ok := prefs.Get("wait")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(p.Value, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// String formats the preferences as the value of a Prefer or
+// Preference-Applied header.
+func (prefs Preferences) String() string {
+	vals := make([]string, len(prefs))
+	for i, p := range prefs {
+		vals[i] = p.String()
+	}
+	return strings.Join(vals, ", ")
+}
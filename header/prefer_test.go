@@ -0,0 +1,56 @@
+package header
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var parsePreferTests = []struct {
+	s        []string
+	expected Preferences
+}{
+	{[]string{`return=minimal`}, Preferences{{"return", "minimal", map[string]string{}}}},
+	{[]string{`respond-async, WAIT=10`}, Preferences{
+		{"respond-async", "", map[string]string{}},
+		{"wait", "10", map[string]string{}},
+	}},
+	{[]string{`foo; bar="b,az"; qux`, `return = "representation"`}, Preferences{
+		{"foo", "", map[string]string{"bar": "b,az", "qux": ""}},
+		{"return", "representation", map[string]string{}},
+	}},
+	{[]string{`return=minimal, return=representation`}, Preferences{{"return", "minimal", map[string]string{}}}},
+
+	// bad cases
+	{[]string{`=minimal, handling=lenient`}, Preferences{{"handling", "lenient", map[string]string{}}}},
+	{[]string{`return=minimal junk, wait=5`}, Preferences{{"wait", "5", map[string]string{}}}},
+}
+
+func TestParsePrefer(t *testing.T) {
+	for _, tt := range parsePreferTests {
+		header := http.Header{"Prefer": tt.s}
+		actual := ParsePrefer(header, "Prefer")
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("ParsePrefer(h, %q)=%v, want %v", tt.s, actual, tt.expected)
+		}
+	}
+}
+
+func TestPreferences(t *testing.T) {
+	prefs := ParsePrefer(http.Header{"Prefer": {`Return=Minimal, respond-async, wait=100`}}, "Prefer")
+	if prefs.Return() != "minimal" || !prefs.RespondAsync() {
+		t.Errorf("expected minimal return and async response, got: %v", prefs)
+	}
+	if d, ok := prefs.Wait(); !ok || d != 100*time.Second {
+		t.Errorf("Wait()=%v, %v, want 1m40s, true", d, ok)
+	}
+	if _, ok := (Preferences{}).Wait(); ok {
+		t.Errorf("expected no wait preference")
+	}
+
+	prefs = Preferences{{Name: "return", Value: "minimal"}, {Name: "foo", Params: map[string]string{"bar": "a b"}}}
+	if s := prefs.String(); s != `return=minimal, foo; bar="a b"` {
+		t.Errorf("unexpected formatting, got: %s", s)
+	}
+}
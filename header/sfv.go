@@ -0,0 +1,672 @@
+package header
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Token is a Structured Field Values token, a short textual word such as
+// "foo/bar" or "*".
+type Token string
+
+// DisplayString is a Structured Field Values display string (RFC 9651), a
+// unicode string that is percent encoded on the wire.
+type DisplayString string
+
+// Param is a single parameter of an Item or InnerList.
+type Param struct {
+	Key   string
+	Value interface{}
+}
+
+// Params holds the parameters of an Item or InnerList in order.
+type Params []Param
+
+// Get returns the value of the parameter with the given key.
+func (ps Params) Get(key string) (interface{}, bool) {
+	for _, p := range ps {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+	return nil, false
+}
+
+// set overwrites the value of an existing key in place, idx maps keys to their position.
+func (ps Params) set(idx map[string]int, key string, v interface{}) Params {
+	if i, ok := idx[key]; ok {
+		ps[i].Value = v
+		return ps
+	}
+	idx[key] = len(ps)
+	return append(ps, Param{key, v})
+}
+
+// Item is a bare item with parameters. The bare item value is one of: int64,
+// float64 (decimal), string, Token, []byte, bool, time.Time (date) or
+// DisplayString. When formatting, other integer types are accepted as well.
+type Item struct {
+	Value  interface{}
+	Params Params
+}
+
+// InnerList is a list of items that is a member of a List or Dictionary.
+type InnerList struct {
+	Items  []Item
+	Params Params
+}
+
+// Member is either an Item or an InnerList.
+type Member interface {
+	member()
+}
+
+func (Item) member()      {}
+func (InnerList) member() {}
+
+// List is a Structured Field Values list.
+type List []Member
+
+// DictMember is a single member of a Dictionary.
+type DictMember struct {
+	Key   string
+	Value Member
+}
+
+// Dictionary is an ordered Structured Field Values dictionary.
+type Dictionary []DictMember
+
+// Get returns the member with the given key.
+func (d Dictionary) Get(key string) (Member, bool) {
+	for _, m := range d {
+		if m.Key == key {
+			return m.Value, true
+		}
+	}
+	return nil, false
+}
+
+// set overwrites the value of an existing key in place, idx maps keys to their position.
+func (d Dictionary) set(idx map[string]int, key string, v Member) Dictionary {
+	if i, ok := idx[key]; ok {
+		d[i].Value = v
+		return d
+	}
+	idx[key] = len(d)
+	return append(d, DictMember{key, v})
+}
+
+// ErrSFVSyntax is returned when a structured field cannot be parsed.
+var ErrSFVSyntax = errors.New("header: invalid structured field value")
+
+type sfvParser struct{ s string }
+
+func newSFVParser(header http.Header, key string) *sfvParser {
+	p := &sfvParser{strings.Join(header[http.CanonicalHeaderKey(key)], ", ")}
+	p.discard(" ")
+	return p
+}
+
+func (p *sfvParser) discard(chars string) {
+	for len(p.s) > 0 && strings.IndexByte(chars, p.s[0]) >= 0 {
+		p.s = p.s[1:]
+	}
+}
+
+func (p *sfvParser) peek() (byte, bool) {
+	if len(p.s) == 0 {
+		return 0, false
+	}
+	return p.s[0], true
+}
+
+func (p *sfvParser) end() error {
+	p.discard(" ")
+	if p.s != "" {
+		return ErrSFVSyntax
+	}
+	return nil
+}
+
+// ParseSFItem parses the field as a Structured Field Values item, multiple
+// field lines are combined as described in RFC 9651.
+func ParseSFItem(header http.Header, key string) (Item, error) {
+	p := newSFVParser(header, key)
+	it, err := p.item()
+	if err != nil {
+		return Item{}, err
+	}
+	return it, p.end()
+}
+
+// ParseSFList parses the field as a Structured Field Values list. An absent
+// field results in an empty list.
+func ParseSFList(header http.Header, key string) (List, error) {
+	p := newSFVParser(header, key)
+	var l List
+	for p.s != "" {
+		m, err := p.member()
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, m)
+		if err = p.next(); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// ParseSFDictionary parses the field as a Structured Field Values dictionary.
+// An absent field results in an empty dictionary.
+func ParseSFDictionary(header http.Header, key string) (Dictionary, error) {
+	p := newSFVParser(header, key)
+	var d Dictionary
+	idx := map[string]int{}
+	for p.s != "" {
+		k, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		var m Member
+		if c, _ := p.peek(); c == '=' {
+			p.s = p.s[1:]
+			if m, err = p.member(); err != nil {
+				return nil, err
+			}
+		} else {
+			ps, err := p.params()
+			if err != nil {
+				return nil, err
+			}
+			m = Item{true, ps}
+		}
+		d = d.set(idx, k, m)
+		if err = p.next(); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// next consumes the separator between list or dictionary members.
+func (p *sfvParser) next() error {
+	p.discard(" \t")
+	if p.s == "" {
+		return nil
+	}
+	if p.s[0] != ',' {
+		return ErrSFVSyntax
+	}
+	p.s = p.s[1:]
+	p.discard(" \t")
+	if p.s == "" {
+		return ErrSFVSyntax
+	}
+	return nil
+}
+
+func (p *sfvParser) member() (Member, error) {
+	if c, _ := p.peek(); c == '(' {
+		return p.innerList()
+	}
+	return p.item()
+}
+
+func (p *sfvParser) innerList() (InnerList, error) {
+	var il InnerList
+	p.s = p.s[1:]
+	for p.s != "" {
+		p.discard(" ")
+		if c, _ := p.peek(); c == ')' {
+			p.s = p.s[1:]
+			var err error
+			il.Params, err = p.params()
+			return il, err
+		}
+		it, err := p.item()
+		if err != nil {
+			return il, err
+		}
+		il.Items = append(il.Items, it)
+		if c, ok := p.peek(); !ok || (c != ' ' && c != ')') {
+			return il, ErrSFVSyntax
+		}
+	}
+	return il, ErrSFVSyntax
+}
+
+func (p *sfvParser) item() (it Item, err error) {
+	if it.Value, err = p.bareItem(); err != nil {
+		return it, err
+	}
+	it.Params, err = p.params()
+	return it, err
+}
+
+func (p *sfvParser) params() (ps Params, err error) {
+	var idx map[string]int
+	for p.s != "" && p.s[0] == ';' {
+		if idx == nil {
+			idx = map[string]int{}
+		}
+		p.s = p.s[1:]
+		p.discard(" ")
+		var k string
+		if k, err = p.key(); err != nil {
+			return nil, err
+		}
+		var v interface{} = true
+		if c, _ := p.peek(); c == '=' {
+			p.s = p.s[1:]
+			if v, err = p.bareItem(); err != nil {
+				return nil, err
+			}
+		}
+		ps = ps.set(idx, k, v)
+	}
+	return ps, nil
+}
+
+func isLCAlpha(c byte) bool { return c >= 'a' && c <= 'z' }
+func isAlpha(c byte) bool   { return isLCAlpha(c) || (c >= 'A' && c <= 'Z') }
+func isDigit(c byte) bool   { return c >= '0' && c <= '9' }
+
+func (p *sfvParser) key() (string, error) {
+	if c, ok := p.peek(); !ok || (!isLCAlpha(c) && c != '*') {
+		return "", ErrSFVSyntax
+	}
+	i := 1
+	for ; i < len(p.s); i++ {
+		c := p.s[i]
+		if !isLCAlpha(c) && !isDigit(c) && strings.IndexByte("_-.*", c) < 0 {
+			break
+		}
+	}
+	k := p.s[:i]
+	p.s = p.s[i:]
+	return k, nil
+}
+
+func (p *sfvParser) bareItem() (interface{}, error) {
+	c, ok := p.peek()
+	switch {
+	case !ok:
+		return nil, ErrSFVSyntax
+	case c == '-' || isDigit(c):
+		return p.number()
+	case c == '"':
+		return p.string()
+	case c == '*' || isAlpha(c):
+		return p.token(), nil
+	case c == ':':
+		return p.binary()
+	case c == '?':
+		return p.boolean()
+	case c == '@':
+		return p.date()
+	case c == '%':
+		return p.displayString()
+	}
+	return nil, ErrSFVSyntax
+}
+
+func (p *sfvParser) number() (interface{}, error) {
+	i, dot := 0, -1
+	if p.s[0] == '-' {
+		i++
+	}
+	if i >= len(p.s) || !isDigit(p.s[i]) {
+		return nil, ErrSFVSyntax
+	}
+	start := i
+	for ; i < len(p.s); i++ {
+		c := p.s[i]
+		switch {
+		case isDigit(c):
+		case c == '.' && dot < 0:
+			if i-start > 12 {
+				return nil, ErrSFVSyntax
+			}
+			dot = i
+			continue
+		default:
+			goto done
+		}
+		if (dot < 0 && i-start >= 15) || (dot >= 0 && i-start >= 16) {
+			return nil, ErrSFVSyntax
+		}
+	}
+done:
+	num := p.s[:i]
+	p.s = p.s[i:]
+	if dot < 0 {
+		return strconv.ParseInt(num, 10, 64)
+	}
+	if frac := i - dot - 1; frac < 1 || frac > 3 {
+		return nil, ErrSFVSyntax
+	}
+	return strconv.ParseFloat(num, 64)
+}
+
+func (p *sfvParser) string() (string, error) {
+	b := &strings.Builder{}
+	for i := 1; i < len(p.s); i++ {
+		c := p.s[i]
+		switch {
+		case c == '\\':
+			i++
+			if i >= len(p.s) || (p.s[i] != '"' && p.s[i] != '\\') {
+				return "", ErrSFVSyntax
+			}
+			b.WriteByte(p.s[i])
+		case c == '"':
+			p.s = p.s[i+1:]
+			return b.String(), nil
+		case c < 0x20 || c > 0x7e:
+			return "", ErrSFVSyntax
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", ErrSFVSyntax
+}
+
+func (p *sfvParser) token() Token {
+	i := 1
+	for ; i < len(p.s); i++ {
+		if c := p.s[i]; octetTypes[c]&isToken == 0 && c != ':' && c != '/' {
+			break
+		}
+	}
+	t := Token(p.s[:i])
+	p.s = p.s[i:]
+	return t
+}
+
+func (p *sfvParser) binary() ([]byte, error) {
+	end := strings.IndexByte(p.s[1:], ':')
+	if end < 0 {
+		return nil, ErrSFVSyntax
+	}
+	enc := p.s[1 : end+1]
+	for i := 0; i < len(enc); i++ {
+		if c := enc[i]; !isAlpha(c) && !isDigit(c) && c != '+' && c != '/' && c != '=' {
+			return nil, ErrSFVSyntax
+		}
+	}
+	p.s = p.s[end+2:]
+	b, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, ErrSFVSyntax
+	}
+	return b, nil
+}
+
+func (p *sfvParser) boolean() (bool, error) {
+	if len(p.s) < 2 || (p.s[1] != '0' && p.s[1] != '1') {
+		return false, ErrSFVSyntax
+	}
+	v := p.s[1] == '1'
+	p.s = p.s[2:]
+	return v, nil
+}
+
+func (p *sfvParser) date() (time.Time, error) {
+	p.s = p.s[1:]
+	if c, ok := p.peek(); !ok || (c != '-' && !isDigit(c)) {
+		return time.Time{}, ErrSFVSyntax
+	}
+	n, err := p.number()
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, ok := n.(int64)
+	if !ok {
+		return time.Time{}, ErrSFVSyntax
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
+
+func isLCHex(c byte) bool { return isDigit(c) || (c >= 'a' && c <= 'f') }
+
+func (p *sfvParser) displayString() (DisplayString, error) {
+	if len(p.s) < 2 || p.s[1] != '"' {
+		return "", ErrSFVSyntax
+	}
+	var b []byte
+	for i := 2; i < len(p.s); i++ {
+		c := p.s[i]
+		switch {
+		case c < 0x20 || c > 0x7e:
+			return "", ErrSFVSyntax
+		case c == '%':
+			if i+2 >= len(p.s) || !isLCHex(p.s[i+1]) || !isLCHex(p.s[i+2]) {
+				return "", ErrSFVSyntax
+			}
+			n, _ := strconv.ParseUint(p.s[i+1:i+3], 16, 8)
+			b = append(b, byte(n))
+			i += 2
+		case c == '"':
+			if !utf8.Valid(b) {
+				return "", ErrSFVSyntax
+			}
+			p.s = p.s[i+1:]
+			return DisplayString(b), nil
+		default:
+			b = append(b, c)
+		}
+	}
+	return "", ErrSFVSyntax
+}
+
+// FormatSFItem serializes the item, it fails if the item holds a value that
+// cannot be represented.
+func FormatSFItem(it Item) (string, error) {
+	b := &strings.Builder{}
+	err := writeItem(b, it)
+	return b.String(), err
+}
+
+// FormatSFList serializes the list, it fails if any member holds a value that
+// cannot be represented.
+func FormatSFList(l List) (string, error) {
+	b := &strings.Builder{}
+	for i, m := range l {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if err := writeMember(b, m); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// FormatSFDictionary serializes the dictionary, it fails if any member holds a
+// value that cannot be represented.
+func FormatSFDictionary(d Dictionary) (string, error) {
+	b := &strings.Builder{}
+	for i, m := range d {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if err := writeKey(b, m.Key); err != nil {
+			return "", err
+		}
+		if it, ok := m.Value.(Item); ok && it.Value == true {
+			if err := writeParams(b, it.Params); err != nil {
+				return "", err
+			}
+			continue
+		}
+		b.WriteByte('=')
+		if err := writeMember(b, m.Value); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+func writeMember(b *strings.Builder, m Member) error {
+	switch m := m.(type) {
+	case Item:
+		return writeItem(b, m)
+	case InnerList:
+		b.WriteByte('(')
+		for i, it := range m.Items {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			if err := writeItem(b, it); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(')')
+		return writeParams(b, m.Params)
+	}
+	return fmt.Errorf("header: invalid structured field member %T", m)
+}
+
+func writeItem(b *strings.Builder, it Item) error {
+	if err := writeBareItem(b, it.Value); err != nil {
+		return err
+	}
+	return writeParams(b, it.Params)
+}
+
+func writeParams(b *strings.Builder, ps Params) error {
+	for _, p := range ps {
+		b.WriteByte(';')
+		if err := writeKey(b, p.Key); err != nil {
+			return err
+		}
+		if p.Value == true {
+			continue
+		}
+		b.WriteByte('=')
+		if err := writeBareItem(b, p.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeKey(b *strings.Builder, k string) error {
+	p := &sfvParser{k}
+	if _, err := p.key(); err != nil || p.s != "" {
+		return fmt.Errorf("header: invalid structured field key %q", k)
+	}
+	b.WriteString(k)
+	return nil
+}
+
+const maxSFInteger = 999999999999999
+
+func writeBareItem(b *strings.Builder, v interface{}) error {
+	switch v := v.(type) {
+	case int:
+		return writeInteger(b, int64(v))
+	case int8:
+		return writeInteger(b, int64(v))
+	case int16:
+		return writeInteger(b, int64(v))
+	case int32:
+		return writeInteger(b, int64(v))
+	case int64:
+		return writeInteger(b, v)
+	case uint:
+		return writeInteger(b, int64(min(uint64(v), maxSFInteger+1)))
+	case uint8:
+		return writeInteger(b, int64(v))
+	case uint16:
+		return writeInteger(b, int64(v))
+	case uint32:
+		return writeInteger(b, int64(v))
+	case uint64:
+		return writeInteger(b, int64(min(v, maxSFInteger+1)))
+	case float32:
+		return writeDecimal(b, float64(v))
+	case float64:
+		return writeDecimal(b, v)
+	case string:
+		b.WriteByte('"')
+		for i := 0; i < len(v); i++ {
+			c := v[i]
+			if c < 0x20 || c > 0x7e {
+				return fmt.Errorf("header: invalid structured field string %q", v)
+			}
+			if c == '"' || c == '\\' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(c)
+		}
+		b.WriteByte('"')
+	case Token:
+		p := &sfvParser{string(v)}
+		if c, ok := p.peek(); !ok || (c != '*' && !isAlpha(c)) || p.token() != v {
+			return fmt.Errorf("header: invalid structured field token %q", v)
+		}
+		b.WriteString(string(v))
+	case []byte:
+		b.WriteString(":" + base64.StdEncoding.EncodeToString(v) + ":")
+	case bool:
+		if v {
+			b.WriteString("?1")
+		} else {
+			b.WriteString("?0")
+		}
+	case time.Time:
+		b.WriteByte('@')
+		return writeInteger(b, v.Unix())
+	case DisplayString:
+		if !utf8.ValidString(string(v)) {
+			return fmt.Errorf("header: invalid structured field display string %q", v)
+		}
+		const hex = "0123456789abcdef"
+		b.WriteString(`%"`)
+		for i := 0; i < len(v); i++ {
+			c := v[i]
+			if c == '%' || c == '"' || c < 0x20 || c > 0x7e {
+				b.WriteByte('%')
+				b.WriteByte(hex[c>>4])
+				b.WriteByte(hex[c&15])
+				continue
+			}
+			b.WriteByte(c)
+		}
+		b.WriteByte('"')
+	default:
+		return fmt.Errorf("header: invalid structured field bare item %T", v)
+	}
+	return nil
+}
+
+func writeInteger(b *strings.Builder, n int64) error {
+	if n > maxSFInteger || n < -maxSFInteger {
+		return fmt.Errorf("header: structured field integer %d out of range", n)
+	}
+	b.WriteString(strconv.FormatInt(n, 10))
+	return nil
+}
+
+func writeDecimal(b *strings.Builder, f float64) error {
+	f = math.RoundToEven(f*1000)/1000 + 0 //also drops the sign of negative zero
+	if math.IsNaN(f) || math.Abs(f) >= 1e12 {
+		return fmt.Errorf("header: structured field decimal %v out of range", f)
+	}
+	s := strconv.FormatFloat(f, 'f', 3, 64)
+	s = strings.TrimRight(s, "0")
+	if strings.HasSuffix(s, ".") {
+		s += "0"
+	}
+	b.WriteString(s)
+	return nil
+}
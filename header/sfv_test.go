@@ -0,0 +1,188 @@
+package header
+
+import (
+	"encoding/base32"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sfvTest is a test case in the format of the public test suite, see
+// testdata/sfv/README.md.
+type sfvTest struct {
+	Name       string      `json:"name"`
+	Raw        []string    `json:"raw"`
+	HeaderType string      `json:"header_type"`
+	Expected   interface{} `json:"expected"`
+	MustFail   bool        `json:"must_fail"`
+	CanFail    bool        `json:"can_fail"`
+	Canonical  []string    `json:"canonical"`
+}
+
+func sfvBareItem(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			f, _ := v.Float64()
+			return f
+		}
+		n, _ := v.Int64()
+		return n
+	case map[string]interface{}:
+		switch v["__type"] {
+		case "token":
+			return Token(v["value"].(string))
+		case "binary":
+			b, _ := base32.StdEncoding.DecodeString(v["value"].(string))
+			return b
+		case "date":
+			n, _ := v["value"].(json.Number).Int64()
+			return time.Unix(n, 0).UTC()
+		case "displaystring":
+			return DisplayString(v["value"].(string))
+		}
+	}
+	return v
+}
+
+func sfvParams(v interface{}) (ps Params) {
+	for _, p := range v.([]interface{}) {
+		p := p.([]interface{})
+		ps = append(ps, Param{p[0].(string), sfvBareItem(p[1])})
+	}
+	return ps
+}
+
+func sfvItem(v interface{}) Item {
+	it := v.([]interface{})
+	return Item{sfvBareItem(it[0]), sfvParams(it[1])}
+}
+
+func sfvMember(v interface{}) Member {
+	m := v.([]interface{})
+	items, ok := m[0].([]interface{})
+	if !ok {
+		return sfvItem(v)
+	}
+	il := InnerList{Params: sfvParams(m[1])}
+	for _, it := range items {
+		il.Items = append(il.Items, sfvItem(it))
+	}
+	return il
+}
+
+func (tt sfvTest) expected() interface{} {
+	switch tt.HeaderType {
+	case "item":
+		return sfvItem(tt.Expected)
+	case "list":
+		var l List
+		for _, m := range tt.Expected.([]interface{}) {
+			l = append(l, sfvMember(m))
+		}
+		return l
+	default:
+		var d Dictionary
+		for _, m := range tt.Expected.([]interface{}) {
+			m := m.([]interface{})
+			d = append(d, DictMember{m[0].(string), sfvMember(m[1])})
+		}
+		return d
+	}
+}
+
+func (tt sfvTest) parse() (interface{}, error) {
+	header := http.Header{"Example": tt.Raw}
+	switch tt.HeaderType {
+	case "item":
+		return ParseSFItem(header, "Example")
+	case "list":
+		return ParseSFList(header, "Example")
+	default:
+		return ParseSFDictionary(header, "Example")
+	}
+}
+
+func formatSF(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case Item:
+		return FormatSFItem(v)
+	case List:
+		return FormatSFList(v)
+	default:
+		return FormatSFDictionary(v.(Dictionary))
+	}
+}
+
+func loadSFVTests(t *testing.T, pattern string) map[string][]sfvTest {
+	files, err := filepath.Glob(pattern)
+	if err != nil || len(files) < 1 {
+		t.Fatalf("failed to find test vectors %s: %v", pattern, err)
+	}
+	suite := map[string][]sfvTest{}
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec := json.NewDecoder(f)
+		dec.UseNumber()
+		var tests []sfvTest
+		err = dec.Decode(&tests)
+		f.Close()
+		if err != nil {
+			t.Fatalf("failed to decode %s: %v", name, err)
+		}
+		suite[filepath.Base(name)] = tests
+	}
+	return suite
+}
+
+func TestSFVSuite(t *testing.T) {
+	for name, tests := range loadSFVTests(t, filepath.Join("testdata", "sfv", "*.json")) {
+		for _, tt := range tests {
+			actual, err := tt.parse()
+			switch {
+			case tt.MustFail && err == nil:
+				t.Errorf("%s: %s: parse of %q must fail", name, tt.Name, tt.Raw)
+				continue
+			case err != nil && !tt.MustFail && !tt.CanFail:
+				t.Errorf("%s: %s: parse of %q failed: %v", name, tt.Name, tt.Raw, err)
+				continue
+			case err != nil:
+				continue
+			}
+
+			if expected := tt.expected(); !reflect.DeepEqual(actual, expected) {
+				t.Errorf("%s: %s: parse of %q=%#v, want %#v", name, tt.Name, tt.Raw, actual, expected)
+			}
+
+			canonical := tt.Raw
+			if tt.Canonical != nil {
+				canonical = tt.Canonical
+			}
+			if s, err := formatSF(actual); err != nil || s != strings.Join(canonical, ", ") {
+				t.Errorf("%s: %s: serialization=%q (%v), want %q", name, tt.Name, s, err, canonical)
+			}
+		}
+	}
+}
+
+func TestSFVSerialisationSuite(t *testing.T) {
+	for name, tests := range loadSFVTests(t, filepath.Join("testdata", "sfv", "serialisation-tests", "*.json")) {
+		for _, tt := range tests {
+			s, err := formatSF(tt.expected())
+			switch {
+			case tt.MustFail && err == nil:
+				t.Errorf("%s: %s: serialization must fail, got: %q", name, tt.Name, s)
+			case !tt.MustFail && (err != nil || s != strings.Join(tt.Canonical, ", ")):
+				t.Errorf("%s: %s: serialization=%q (%v), want %q", name, tt.Name, s, err, tt.Canonical)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package header
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	itemType   = reflect.TypeOf(Item{})
+	ilistType  = reflect.TypeOf(InnerList{})
+	memberType = reflect.TypeOf((*Member)(nil)).Elem()
+)
+
+// UnmarshalSFDictionary binds the members of the dictionary into the fields
+// of the struct pointed to by v. The key of a field is taken from its "sf"
+// tag or else its lowercase name, fields tagged with "-" are skipped. Bare
+// items are converted to the field type if this is lossless, inner lists are
+// bound into slices. Fields of type Item, InnerList or Member receive the
+// member as is, including its parameters. Members without a field are
+// ignored.
+func UnmarshalSFDictionary(d Dictionary, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("header: cannot unmarshal dictionary into %T, need a struct pointer", v)
+	}
+	rv = rv.Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		key := f.Tag.Get("sf")
+		if f.PkgPath != "" || key == "-" {
+			continue
+		}
+		if key == "" {
+			key = strings.ToLower(f.Name)
+		}
+		m, ok := d.Get(key)
+		if !ok {
+			continue
+		}
+		if err := bindSFMember(rv.Field(i), m); err != nil {
+			return fmt.Errorf("header: cannot bind dictionary member %q: %v", key, err)
+		}
+	}
+	return nil
+}
+
+func bindSFMember(fv reflect.Value, m Member) error {
+	switch fv.Type() {
+	case memberType, reflect.TypeOf(m):
+		fv.Set(reflect.ValueOf(m))
+		return nil
+	}
+	switch m := m.(type) {
+	case InnerList:
+		if fv.Kind() != reflect.Slice || fv.Type().Elem().Kind() == reflect.Uint8 {
+			return fmt.Errorf("inner list does not fit %s", fv.Type())
+		}
+		s := reflect.MakeSlice(fv.Type(), len(m.Items), len(m.Items))
+		for i, it := range m.Items {
+			if err := bindSFMember(s.Index(i), it); err != nil {
+				return err
+			}
+		}
+		fv.Set(s)
+		return nil
+	case Item:
+		return bindSFBareItem(fv, m.Value)
+	}
+	return fmt.Errorf("invalid member %T", m)
+}
+
+func bindSFBareItem(fv reflect.Value, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	switch {
+	case fv.Type() == timeType || rv.Type() == timeType:
+		if rv.Type() != fv.Type() {
+			break
+		}
+		fv.Set(rv)
+		return nil
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Uint8:
+		if b, ok := v.([]byte); ok {
+			fv.SetBytes(b)
+			return nil
+		}
+	case fv.Kind() == reflect.Slice:
+		s := reflect.MakeSlice(fv.Type(), 1, 1)
+		if err := bindSFBareItem(s.Index(0), v); err != nil {
+			return err
+		}
+		fv.Set(s)
+		return nil
+	case fv.Kind() == reflect.Bool && rv.Kind() == reflect.Bool:
+		fv.SetBool(rv.Bool())
+		return nil
+	case fv.Kind() == reflect.String && rv.Kind() == reflect.String:
+		fv.SetString(rv.String())
+		return nil
+	case fv.CanInt() && rv.Kind() == reflect.Int64:
+		if fv.OverflowInt(rv.Int()) {
+			return fmt.Errorf("integer %d overflows %s", rv.Int(), fv.Type())
+		}
+		fv.SetInt(rv.Int())
+		return nil
+	case fv.CanUint() && rv.Kind() == reflect.Int64:
+		if rv.Int() < 0 || fv.OverflowUint(uint64(rv.Int())) {
+			return fmt.Errorf("integer %d overflows %s", rv.Int(), fv.Type())
+		}
+		fv.SetUint(uint64(rv.Int()))
+		return nil
+	case fv.CanFloat() && (rv.Kind() == reflect.Float64 || rv.Kind() == reflect.Int64):
+		var f float64
+		switch rv.Kind() {
+		case reflect.Float64:
+			f = rv.Float()
+		default:
+			f = float64(rv.Int())
+		}
+		if fv.OverflowFloat(f) || math.IsNaN(f) {
+			return fmt.Errorf("decimal %v overflows %s", f, fv.Type())
+		}
+		fv.SetFloat(f)
+		return nil
+	}
+	return fmt.Errorf("%T does not fit %s", v, fv.Type())
+}
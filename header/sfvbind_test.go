@@ -0,0 +1,84 @@
+package header
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type sfvPriority struct {
+	Urgency     uint8 `sf:"u"`
+	Incremental bool  `sf:"i"`
+}
+
+type sfvBindAll struct {
+	Int     int
+	Float   float32
+	Token   Token
+	String  string
+	Display string
+	Binary  []byte
+	Date    time.Time
+	Langs   []string `sf:"langs"`
+	Single  []int    `sf:"single"`
+	Item    Item     `sf:"raw"`
+	Member  Member   `sf:"list"`
+	Skipped string   `sf:"-"`
+	private string
+}
+
+func TestUnmarshalSFDictionary(t *testing.T) {
+	var prio sfvPriority
+	d, _ := ParseSFDictionary(http.Header{"Priority": {"u=3, i, x=?0"}}, "Priority")
+	if err := UnmarshalSFDictionary(d, &prio); err != nil || prio != (sfvPriority{3, true}) {
+		t.Errorf("UnmarshalSFDictionary(%v)=%v (%v), want {3 true}", d, prio, err)
+	}
+
+	d, err := ParseSFDictionary(http.Header{"Example": {
+		`int=-42, float=2, token=foo/bar, string="a", display=%"%c3%bc", binary=:aGVsbG8=:, date=@1659578233`,
+		`langs=(en "nl"), single=7, raw=1;a=b, list=(1 2);x, skipped=1, private=1, unknown=1`,
+	}}, "Example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all sfvBindAll
+	if err := UnmarshalSFDictionary(d, &all); err != nil {
+		t.Fatal(err)
+	}
+	expected := sfvBindAll{
+		Int:     -42,
+		Float:   2,
+		Token:   "foo/bar",
+		String:  "a",
+		Display: "ü",
+		Binary:  []byte("hello"),
+		Date:    time.Unix(1659578233, 0).UTC(),
+		Langs:   []string{"en", "nl"},
+		Single:  []int{7},
+		Item:    Item{int64(1), Params{{"a", Token("b")}}},
+		Member:  InnerList{[]Item{{int64(1), nil}, {int64(2), nil}}, Params{{"x", true}}},
+	}
+	if !reflect.DeepEqual(all, expected) {
+		t.Errorf("UnmarshalSFDictionary(%v)=%#v, want %#v", d, all, expected)
+	}
+
+	var weight struct {
+		Q float64 `sf:"q"`
+		F float32 `sf:"f"`
+	}
+	d, _ = ParseSFDictionary(http.Header{"Weight": {"q=0.5, f=-1.25"}}, "Weight")
+	if err := UnmarshalSFDictionary(d, &weight); err != nil || weight.Q != 0.5 || weight.F != -1.25 {
+		t.Errorf("UnmarshalSFDictionary(%v)=%+v (%v), want {0.5 -1.25}", d, weight, err)
+	}
+
+	for _, s := range []string{`u=256`, `u=-1`, `u=1.5`, `i=1`, `u=(1 2)`} {
+		d, _ := ParseSFDictionary(http.Header{"Priority": {s}}, "Priority")
+		if err := UnmarshalSFDictionary(d, &prio); err == nil {
+			t.Errorf("UnmarshalSFDictionary(%s) must fail", s)
+		}
+	}
+	if err := UnmarshalSFDictionary(d, prio); err == nil {
+		t.Errorf("UnmarshalSFDictionary into a non-pointer must fail")
+	}
+}
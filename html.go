@@ -0,0 +1,239 @@
+package httpio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+var (
+	//MediaTypeHTML identifies HTML content
+	MediaTypeHTML = "text/html"
+
+	contextValueTemplate = contextValue("template")
+)
+
+//TemplateValue returns the name of the template stored in the (request) context, returns "" if its not
+//specified
+func TemplateValue(ctx context.Context) (name string) {
+	if slot, _ := ctx.Value(contextValueTemplate).(*string); slot != nil {
+		name = *slot
+	}
+
+	return
+}
+
+//WithTemplate will write the name of the template that renders the output to the (request) context
+func WithTemplate(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextValueTemplate, &name)
+}
+
+//SetTemplate changes the name of the template that renders the output of a handler that is already
+//running. It only has effect on the context passed to functions registered with Handle, the request
+//context after Ingress.Handle returned or contexts derived from WithTemplate, and reports whether it did.
+func SetTemplate(ctx context.Context, name string) bool {
+	slot, _ := ctx.Value(contextValueTemplate).(*string)
+	if slot == nil {
+		return false
+	}
+
+	*slot = name
+	return true
+}
+
+//Templater can be implemented by outputs to select the template they are rendered with
+type Templater interface {
+	Template() string
+}
+
+//HTML renders outputs using html/template. Every page is parsed into its own set, together with the
+//shared templates such that each page can define the blocks of a common layout. The page for an output
+//is the first of: the output's Template method, the error template if the output is an error, the name
+//set on the request context with WithTemplate or SetTemplate, or the page registered for the output's type
+type HTML struct {
+	fsys   fs.FS
+	pages  string
+	shared []string
+	funcs  template.FuncMap
+	layout string
+	errors string
+	reload bool
+
+	sets  map[string]*template.Template
+	types sync.Map
+}
+
+//HTMLOption configures the HTML encoding
+type HTMLOption func(h *HTML)
+
+//HTMLShared parses the templates matching the patterns, such as layouts and partials, into every page
+func HTMLShared(patterns ...string) HTMLOption {
+	return func(h *HTML) { h.shared = append(h.shared, patterns...) }
+}
+
+//HTMLFuncs adds functions that can be called from the templates
+func HTMLFuncs(funcs template.FuncMap) HTMLOption { return func(h *HTML) { h.funcs = funcs } }
+
+//HTMLLayout causes pages to be rendered by executing the (shared) template 'name' instead of the page
+//itself, the layout can then include the blocks that the page defines
+func HTMLLayout(name string) HTMLOption { return func(h *HTML) { h.layout = name } }
+
+//HTMLErrors sets the page that renders outputs that are errors, it defaults to "error.html"
+func HTMLErrors(page string) HTMLOption { return func(h *HTML) { h.errors = page } }
+
+//HTMLReload causes the templates to be parsed again for every output, such that changes show up without
+//a restart while developing
+func HTMLReload() HTMLOption { return func(h *HTML) { h.reload = true } }
+
+//NewHTML creates an HTML encoding for the pages in 'fsys' that match pattern 'pages', pages are named by
+//their path, e.g: "items/show.html"
+func NewHTML(fsys fs.FS, pages string, opts ...HTMLOption) (h *HTML, err error) {
+	h = &HTML{fsys: fsys, pages: pages, errors: "error.html"}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	h.sets, err = h.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+func (h *HTML) parse() (map[string]*template.Template, error) {
+	base := template.New("").Funcs(h.funcs)
+	if len(h.shared) > 0 {
+		_, err := base.ParseFS(h.fsys, h.shared...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pages, err := fs.Glob(h.fsys, h.pages)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pages) < 1 {
+		return nil, fmt.Errorf("httpio/html: pattern '%s' matches no pages", h.pages)
+	}
+
+	sets := make(map[string]*template.Template, len(pages))
+	for _, page := range pages {
+		data, err := fs.ReadFile(h.fsys, page)
+		if err != nil {
+			return nil, err
+		}
+
+		set, err := base.Clone()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = set.New(page).Parse(string(data))
+		if err != nil {
+			return nil, err
+		}
+
+		sets[page] = set
+	}
+
+	return sets, nil
+}
+
+//Register causes outputs of the same type as 'protos' to be rendered by 'page', pointers and the values
+//they point to are treated alike
+func (h *HTML) Register(page string, protos ...interface{}) *HTML {
+	for _, proto := range protos {
+		h.types.Store(htmlType(reflect.TypeOf(proto)), page)
+	}
+
+	return h
+}
+
+func htmlType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+//Page returns the page that renders 'v' given the page set on the request context, it returns "" if there
+//is none
+func (h *HTML) Page(v interface{}, ctxPage string) string {
+	if t, ok := v.(Templater); ok {
+		return t.Template()
+	}
+
+	if _, ok := v.(error); ok {
+		return h.errors
+	}
+
+	if ctxPage != "" {
+		return ctxPage
+	}
+
+	page, _ := h.types.Load(htmlType(reflect.TypeOf(v)))
+	name, _ := page.(string)
+	return name
+}
+
+//MimeType will report the EncodingMimeType
+func (h *HTML) MimeType() string { return MediaTypeHTML }
+
+//Encoder will create encoders
+func (h *HTML) Encoder(w io.Writer) Encoder { return &htmlEncoder{h, w, ""} }
+
+//RequestEncoder creates an encoder that takes the page from the request context into account
+func (h *HTML) RequestEncoder(w io.Writer, r *http.Request) Encoder {
+	return &htmlEncoder{h, w, TemplateValue(r.Context())}
+}
+
+type htmlEncoder struct {
+	h       *HTML
+	w       io.Writer
+	ctxPage string
+}
+
+//Encode executes the page into a buffer first such that a failing template doesn't result in half a page
+func (e *htmlEncoder) Encode(v interface{}) (err error) {
+	page := e.h.Page(v, e.ctxPage)
+	if page == "" {
+		return fmt.Errorf("httpio/html: no page to render output of type %T", v)
+	}
+
+	sets := e.h.sets
+	if e.h.reload {
+		sets, err = e.h.parse()
+		if err != nil {
+			return err
+		}
+	}
+
+	set, ok := sets[page]
+	if !ok {
+		return fmt.Errorf("httpio/html: page '%s' does not exist", page)
+	}
+
+	name := page
+	if e.h.layout != "" {
+		name = e.h.layout
+	}
+
+	buf := bytes.NewBuffer(nil)
+	err = set.ExecuteTemplate(buf, name, v)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(buf.Bytes())
+	return err
+}
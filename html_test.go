@@ -0,0 +1,162 @@
+package httpio_test
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+type htmlTestItem struct {
+	Name string `json:"name"`
+}
+
+type htmlTestHome struct{}
+
+func (htmlTestHome) Template() string { return "pages/home.html" }
+
+func htmlTestFS() fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html":   {Data: []byte(`<title>{{block "title" .}}Shop{{end}}</title>{{template "content" .}}`)},
+		"partials/name.html":  {Data: []byte(`{{define "name"}}<b>{{.}}</b>{{end}}`)},
+		"pages/item.html":     {Data: []byte(`{{define "title"}}{{.Name}}{{end}}{{define "content"}}{{template "name" .Name}}{{end}}`)},
+		"pages/home.html":     {Data: []byte(`{{define "content"}}{{shout "home"}}{{end}}`)},
+		"pages/error.html":    {Data: []byte(`{{define "title"}}Error{{end}}{{define "content"}}<p>{{.Error}}</p>{{end}}`)},
+		"pages/detailed.html": {Data: []byte(`{{define "content"}}<i>{{.Name}}</i>{{end}}`)},
+	}
+}
+
+func TestHTML(t *testing.T) {
+	h, err := httpio.NewHTML(htmlTestFS(), "pages/*.html",
+		httpio.HTMLShared("layouts/*.html", "partials/*.html"),
+		httpio.HTMLLayout("base.html"),
+		httpio.HTMLErrors("pages/error.html"),
+		httpio.HTMLFuncs(template.FuncMap{"shout": strings.ToUpper}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.Register("pages/item.html", &htmlTestItem{})
+	e := httpio.NewEgress(&httpio.JSON{}, h)
+
+	for _, c := range []struct {
+		Name    string
+		Accept  string
+		Page    string
+		Output  interface{}
+		ExpType string
+		ExpBody string
+	}{
+		{"registry", "text/html", "", htmlTestItem{"<x>"}, "text/html; charset=utf-8", "<title>&lt;x&gt;</title><b>&lt;x&gt;</b>"},
+		{"templater", "text/html", "", htmlTestHome{}, "text/html; charset=utf-8", "<title>Shop</title>HOME"},
+		{"context", "text/html", "pages/detailed.html", &htmlTestItem{"foo"}, "text/html; charset=utf-8", "<title>Shop</title><i>foo</i>"},
+		{"error", "text/html", "pages/detailed.html", errors.New("oops"), "text/html; charset=utf-8", "<title>Error</title><p>oops</p>"},
+		{"browser", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "", &htmlTestItem{"foo"}, "text/html; charset=utf-8", "<title>foo</title><b>foo</b>"},
+		{"api client", "application/json", "", &htmlTestItem{"foo"}, "application/json; charset=utf-8", `{"name":"foo"}` + "\n"},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", c.Accept)
+			if c.Page != "" {
+				r = r.WithContext(httpio.WithTemplate(r.Context(), c.Page))
+			}
+
+			w := httptest.NewRecorder()
+			err := e.Render(c.Output, w, r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if w.Header().Get("Content-Type") != c.ExpType || w.Body.String() != c.ExpBody {
+				t.Fatalf("expected %s %q, got: %s %q", c.ExpType, c.ExpBody, w.Header().Get("Content-Type"), w.Body.String())
+			}
+		})
+	}
+
+	err = h.Encoder(httptest.NewRecorder()).Encode(struct{}{})
+	if exp := "httpio/html: no page to render output of type struct {}"; err == nil || err.Error() != exp {
+		t.Fatalf("expected error '%s', got: %v", exp, err)
+	}
+}
+
+func TestHTMLSetTemplate(t *testing.T) {
+	h, err := httpio.NewHTML(htmlTestFS(), "pages/*.html", httpio.HTMLShared("layouts/*.html", "partials/*.html"),
+		httpio.HTMLLayout("base.html"), httpio.HTMLFuncs(template.FuncMap{"shout": strings.ToUpper}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.Register("pages/item.html", &htmlTestItem{})
+	i := httpio.NewIngress(httpio.NewEgress(h), &httpio.JSON{})
+	detailed := func(ctx context.Context, in *htmlTestItem) (*htmlTestItem, error) {
+		if !httpio.SetTemplate(ctx, "pages/detailed.html") {
+			t.Error("expected the handler context to allow setting the template")
+		}
+
+		return in, nil
+	}
+
+	rs := httpio.NewRoutes(i)
+	httpio.Handle(rs, http.MethodPost, "/items", detailed)
+	mux := http.NewServeMux()
+	mux.Handle("/items", rs)
+	mux.HandleFunc("/ingress", func(w http.ResponseWriter, r *http.Request) {
+		in := &htmlTestItem{}
+		if render, ok := i.Handle(w, r, in); ok {
+			render(detailed(r.Context(), in))
+		}
+	})
+
+	for _, path := range []string{"/items", "/ingress"} {
+		t.Run(path, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"name":"foo"}`))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, r)
+			if w.Code != http.StatusOK || w.Body.String() != "<title>Shop</title><i>foo</i>" {
+				t.Fatalf("expected the page picked by the handler, got: %d %q", w.Code, w.Body.String())
+			}
+		})
+	}
+
+	if httpio.SetTemplate(context.Background(), "pages/detailed.html") {
+		t.Fatal("expected a context without template to be left alone")
+	}
+}
+
+func TestHTMLReload(t *testing.T) {
+	fsys := htmlTestFS()
+	delete(fsys, "pages/home.html")
+	h, err := httpio.NewHTML(fsys, "pages/*.html", httpio.HTMLShared("partials/*.html"), httpio.HTMLReload())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h.Register("pages/detailed.html", htmlTestItem{})
+	w := httptest.NewRecorder()
+	if err = h.Encoder(w).Encode(htmlTestItem{"foo"}); err != nil || w.Body.String() != "" {
+		t.Fatalf("expected page without layout to render nothing outside its blocks, got: %q %v", w.Body.String(), err)
+	}
+
+	fsys["pages/detailed.html"] = &fstest.MapFile{Data: []byte(`{{template "name" .Name}}`)}
+	w = httptest.NewRecorder()
+	if err = h.Encoder(w).Encode(htmlTestItem{"foo"}); err != nil || w.Body.String() != "<b>foo</b>" {
+		t.Fatalf("expected changed page to be rendered, got: %q %v", w.Body.String(), err)
+	}
+
+	fsys["pages/detailed.html"] = &fstest.MapFile{Data: []byte(`{{template "name" .Name`)}
+	if err = h.Encoder(httptest.NewRecorder()).Encode(htmlTestItem{"foo"}); err == nil {
+		t.Fatal("expected parse error")
+	}
+
+	_, err = httpio.NewHTML(fsys, "views/*.html")
+	if exp := "httpio/html: pattern 'views/*.html' matches no pages"; err == nil || err.Error() != exp {
+		t.Fatalf("expected error '%s', got: %v", exp, err)
+	}
+}
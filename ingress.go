@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"mime"
 	"net/http"
+	"slices"
 )
 
 type decodeErr struct{ error }
 
 func (e decodeErr) DecodeCause() bool { return true }
 
+func (e decodeErr) Unwrap() error { return e.error }
+
 //IsDecodeErr can be used to
 func IsDecodeErr(err error) bool {
 	type isDecode interface {
@@ -23,36 +26,58 @@ func IsDecodeErr(err error) bool {
 type Ingress struct {
 	egress   *Egress
 	decoders DecoderList
+	index    mediaIndex
+	raw      []Transware
 	wares    []Transware
+	maxBody  int64
 }
 
 //NewIngress will setup the ingress stack, errors during parsing will be returned to using the egress stack.
 func NewIngress(e *Egress, def DecoderFactory, others ...DecoderFactory) *Ingress {
 	list := DecoderList{def}
 	list = append(list, others...)
-	return &Ingress{e, list, nil}
+	return &Ingress{egress: e, decoders: list, index: list.index()}
 }
 
+//Egress returns the stack that is used to render parse errors
+func (i *Ingress) Egress() *Egress { return i.egress }
+
+//Decoders returns the decoder factories of the ingress stack in order of preference
+func (i *Ingress) Decoders() DecoderList { return i.decoders }
+
 //Use will append the transware(s) to the egress render chain
 func (i *Ingress) Use(wares ...Transware) {
 	i.wares = append(i.wares, wares...)
 }
 
+//UseRaw will append the transware(s) to the part of the parse chain that runs before the request body is
+//decoded, they can inspect (and replace) the raw body
+func (i *Ingress) UseRaw(wares ...Transware) {
+	i.raw = append(i.raw, wares...)
+}
+
 func (i *Ingress) transformParse(next Transformer) Transformer {
 	return TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
 		if r.Body == nil || r.ContentLength == 0 {
 			return next.Transform(a, r, w)
 		}
 
+		bound, err := bindRawBody(a, r)
+		if err != nil {
+			return decodeErr{err}
+		} else if bound {
+			return next.Transform(a, r, w)
+		}
+
 		mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
-		e := i.decoders.Find(mt)
-		if e == nil {
+		idx, ok := i.index.find(mt)
+		if !ok {
 			return fmt.Errorf("httpio/ingress: unspported content type '%s'", mt)
 		}
 
-		dec := e.Decoder(r.Body)
+		dec := i.decoders[idx].Decoder(r.Body)
 		defer r.Body.Close()
-		err := dec.Decode(a)
+		err = dec.Decode(a)
 		if err != nil {
 			return decodeErr{err} //tag with decode
 		}
@@ -67,8 +92,18 @@ func (i *Ingress) Parse(r *http.Request, in interface{}) error {
 		return nil //nothing to decode into
 	}
 
-	//in the case of ingress, our parse is always put in front of the middleware chain and the base is noop
-	wares := append([]Transware{i.transformParse}, i.wares...)
+	//the body size is limited before any of the wares get to read it
+	if i.maxBody > 0 && r.Body != nil && r.ContentLength != 0 {
+		if r.ContentLength > i.maxBody {
+			return decodeErr{&http.MaxBytesError{Limit: i.maxBody}}
+		}
+
+		r.Body = http.MaxBytesReader(nil, r.Body, i.maxBody)
+	}
+
+	//in the case of ingress, our parse is always put in front of the middleware chain and the base is noop,
+	//only the raw wares come before it
+	wares := slices.Concat(i.raw, []Transware{i.transformParse}, i.wares)
 	noop := TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error { return nil })
 	chain := Chain(noop, wares...)
 	err := chain.Transform(in, r, nil)
@@ -79,8 +114,10 @@ func (i *Ingress) Parse(r *http.Request, in interface{}) error {
 	return nil
 }
 
-//Handle will parse request 'r' and decode it into 'in', it returns a renderfunction that is bound to response 'w'
+//Handle will parse request 'r' and decode it into 'in', it returns a renderfunction that is bound to response 'w'.
+//The context of 'r' is replaced by one that allows the handler to pick the template with SetTemplate.
 func (i *Ingress) Handle(w http.ResponseWriter, r *http.Request, in interface{}) (fn RenderFunc, ok bool) {
+	*r = *r.WithContext(WithTemplate(r.Context(), TemplateValue(r.Context())))
 	err := i.Parse(r, in)
 	if err != nil {
 		i.egress.MustRender(err, w, r)
@@ -1,8 +1,15 @@
 package httpio
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
 )
 
 var (
@@ -10,14 +17,291 @@ var (
 	MediaTypeJSON = "application/json"
 )
 
-//JSON allows encode and decode into JSOn
-type JSON struct{}
+//JSON allows encode and decode into JSOn. The zero value uses the defaults of encoding/json, use NewJSON to
+//configure stricter decoding or different output
+type JSON struct {
+	disallowUnknown bool
+	useNumber       bool
+	noTrailing      bool
+	noDuplicates    bool
+	maxDepth        int
+
+	prefix, indent string
+	noEscapeHTML   bool
+	prettyParam    string
+
+	backend JSONBackend
+}
+
+//JSONBackend (un)marshals JSON values, it can be implemented to use a faster library than encoding/json
+type JSONBackend interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+//JSONFuncs adapts a pair of marshal and unmarshal functions to a JSONBackend, e.g: JSONFuncs{json.Marshal,
+//json.Unmarshal}
+type JSONFuncs struct {
+	MarshalFunc   func(v interface{}) ([]byte, error)
+	UnmarshalFunc func(data []byte, v interface{}) error
+}
+
+//Marshal calls the MarshalFunc
+func (f JSONFuncs) Marshal(v interface{}) ([]byte, error) { return f.MarshalFunc(v) }
+
+//Unmarshal calls the UnmarshalFunc
+func (f JSONFuncs) Unmarshal(data []byte, v interface{}) error { return f.UnmarshalFunc(data, v) }
+
+//JSONOption configures the JSON encoding
+type JSONOption func(j *JSON)
+
+//JSONDisallowUnknownFields causes decoding to fail on object keys that do not match a field of the destination
+func JSONDisallowUnknownFields() JSONOption { return func(j *JSON) { j.disallowUnknown = true } }
+
+//JSONUseNumber causes numbers to be decoded into interface{} values as json.Number instead of float64
+func JSONUseNumber() JSONOption { return func(j *JSON) { j.useNumber = true } }
+
+//JSONDisallowTrailingData causes decoding to fail if anything but whitespace follows the decoded value
+func JSONDisallowTrailingData() JSONOption { return func(j *JSON) { j.noTrailing = true } }
+
+//JSONDisallowDuplicateKeys causes decoding to fail if an object has the same key more than once
+func JSONDisallowDuplicateKeys() JSONOption { return func(j *JSON) { j.noDuplicates = true } }
+
+//JSONMaxDepth causes decoding to fail if objects and arrays are nested deeper than 'n'
+func JSONMaxDepth(n int) JSONOption { return func(j *JSON) { j.maxDepth = n } }
+
+//JSONStrict combines all options that reject input encoding/json would silently accept
+func JSONStrict() JSONOption {
+	return func(j *JSON) {
+		j.disallowUnknown, j.noTrailing, j.noDuplicates = true, true, true
+	}
+}
+
+//JSONIndent causes encoded values to be indented, see json.Encoder.SetIndent
+func JSONIndent(prefix, indent string) JSONOption {
+	return func(j *JSON) { j.prefix, j.indent = prefix, indent }
+}
+
+//JSONEscapeHTML configures whether <, > and & are escaped in encoded strings, this is the default
+func JSONEscapeHTML(on bool) JSONOption { return func(j *JSON) { j.noEscapeHTML = !on } }
+
+//JSONPrettyQuery causes responses to be indented when the request has query parameter 'param', e.g: ?pretty
+func JSONPrettyQuery(param string) JSONOption { return func(j *JSON) { j.prettyParam = param } }
+
+//JSONWithBackend causes values to be (un)marshalled by backend 'b' instead of by the streaming encoders of
+//encoding/json. Values that implement json.Marshaler or json.Unmarshaler, such as those generated by
+//easyjson, skip the backend and are called directly: their output is trusted and not compacted, and they
+//receive the encoded value as is. The unknown fields and number options only apply to encoding/json,
+//other backends are responsible for their own behaviour. HTML escaping cannot be disabled if the backend
+//escapes itself, as encoding/json does.
+func JSONWithBackend(b JSONBackend) JSONOption { return func(j *JSON) { j.backend = b } }
+
+//NewJSON creates a JSON encoding with the provided options
+func NewJSON(opts ...JSONOption) *JSON {
+	j := &JSON{}
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	return j
+}
 
 //MimeType will report the EncodingMimeType
 func (e *JSON) MimeType() string { return MediaTypeJSON }
 
 //Encoder will create encoders
-func (e *JSON) Encoder(w io.Writer) Encoder { return json.NewEncoder(w) }
+func (e *JSON) Encoder(w io.Writer) Encoder { return e.encoder(w, e.indent) }
+
+//RequestEncoder creates an encoder that indents if the request asks for pretty output
+func (e *JSON) RequestEncoder(w io.Writer, r *http.Request) Encoder {
+	indent := e.indent
+	if e.prettyParam != "" && r.URL.Query().Has(e.prettyParam) {
+		if pretty, err := strconv.ParseBool(r.URL.Query().Get(e.prettyParam)); pretty || err != nil {
+			indent = "  "
+		}
+	}
+
+	return e.encoder(w, indent)
+}
+
+func (e *JSON) encoder(w io.Writer, indent string) Encoder {
+	if e.backend != nil {
+		return &jsonEncoder{e, w, indent}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent(e.prefix, indent)
+	enc.SetEscapeHTML(!e.noEscapeHTML)
+	return enc
+}
+
+var jsonBuffers = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+//jsonEncoder marshals using the backend into pooled buffers
+type jsonEncoder struct {
+	cfg    *JSON
+	w      io.Writer
+	indent string
+}
+
+func (e *jsonEncoder) Encode(v interface{}) (err error) {
+	var data []byte
+	if rv := reflect.ValueOf(v); !rv.IsValid() || (rv.Kind() == reflect.Pointer && rv.IsNil()) {
+		data = []byte("null") //as encoding/json, also if the value receiver of MarshalJSON cannot be called
+	} else if m, ok := v.(json.Marshaler); ok {
+		data, err = m.MarshalJSON()
+	} else {
+		data, err = e.cfg.backend.Marshal(v)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	buf := jsonBuffers.Get().(*bytes.Buffer)
+	defer jsonBuffers.Put(buf)
+	buf.Reset()
+
+	if !e.cfg.noEscapeHTML {
+		esc := jsonBuffers.Get().(*bytes.Buffer)
+		defer jsonBuffers.Put(esc)
+		esc.Reset()
+		json.HTMLEscape(esc, data)
+		data = esc.Bytes()
+	}
+
+	if e.cfg.prefix != "" || e.indent != "" {
+		err = json.Indent(buf, data, e.cfg.prefix, e.indent)
+		if err != nil {
+			return err
+		}
+	} else {
+		buf.Write(data)
+	}
+
+	buf.WriteByte('\n') //like json.Encoder, such that values can be streamed
+	_, err = e.w.Write(buf.Bytes())
+	return err
+}
 
 //Decoder will create decoders
-func (e *JSON) Decoder(r io.Reader) Decoder { return json.NewDecoder(r) }
+func (e *JSON) Decoder(r io.Reader) Decoder {
+	if e.backend != nil || e.noTrailing || e.noDuplicates || e.maxDepth > 0 {
+		return &jsonDecoder{cfg: e, r: r}
+	}
+
+	return e.decoder(r)
+}
+
+func (e *JSON) decoder(r io.Reader) *json.Decoder {
+	dec := json.NewDecoder(r)
+	if e.disallowUnknown {
+		dec.DisallowUnknownFields()
+	}
+
+	if e.useNumber {
+		dec.UseNumber()
+	}
+
+	return dec
+}
+
+//jsonDecoder checks each value before decoding it, this requires the value to be buffered. The buffer is not
+//pooled as unmarshalers may retain it
+type jsonDecoder struct {
+	cfg    *JSON
+	r      io.Reader
+	stream *json.Decoder //reads one value at a time, such that the next Decode continues after it
+}
+
+func (d *jsonDecoder) Decode(v interface{}) error {
+	if d.stream == nil {
+		d.stream = json.NewDecoder(d.r)
+	}
+
+	var data json.RawMessage
+	err := d.stream.Decode(&data)
+	if err != nil {
+		return err
+	}
+
+	if d.cfg.noTrailing {
+		if _, err = d.stream.Token(); err != io.EOF {
+			return errors.New("httpio/json: unexpected data after top-level value")
+		}
+	}
+
+	if d.cfg.noDuplicates || d.cfg.maxDepth > 0 {
+		err = d.check(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.cfg.backend != nil {
+		if u, ok := v.(json.Unmarshaler); ok {
+			return u.UnmarshalJSON(data)
+		}
+
+		return d.cfg.backend.Unmarshal(data, v)
+	}
+
+	return d.cfg.decoder(bytes.NewReader(data)).Decode(v)
+}
+
+//check walks the tokens of the first value to detect duplicate keys and excessive nesting. Syntax errors
+//are left for the decoder to report
+func (d *jsonDecoder) check(data []byte) error {
+	type frame struct {
+		obj    bool
+		expKey bool
+		keys   map[string]bool
+	}
+
+	var stack []*frame
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+
+		var top *frame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			if d.cfg.maxDepth > 0 && len(stack) >= d.cfg.maxDepth {
+				return fmt.Errorf("httpio/json: exceeded maximum nesting depth of %d", d.cfg.maxDepth)
+			}
+
+			stack = append(stack, &frame{obj: tok == json.Delim('{'), expKey: true, keys: map[string]bool{}})
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			if len(stack) < 1 {
+				return nil //only the first value is checked
+			}
+
+			stack[len(stack)-1].expKey = true
+			continue
+		}
+
+		switch {
+		case top == nil:
+			return nil //scalar top-level value
+		case top.obj && top.expKey:
+			key := tok.(string)
+			if d.cfg.noDuplicates && top.keys[key] {
+				return fmt.Errorf("httpio/json: duplicate key '%s'", key)
+			}
+
+			top.keys[key] = true
+			top.expKey = false
+		default:
+			top.expKey = true
+		}
+	}
+}
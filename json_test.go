@@ -0,0 +1,244 @@
+package httpio_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+func TestJSONDecoding(t *testing.T) {
+	type dst struct {
+		A interface{} `json:"a"`
+		B []int       `json:"b"`
+	}
+
+	for _, c := range []struct {
+		Name   string
+		Opts   []httpio.JSONOption
+		Input  string
+		Exp    interface{}
+		ExpErr string
+	}{
+		{Name: "zero value", Input: `{"a":1,"c":2} garbage`, Exp: &dst{A: 1.0}},
+		{Name: "unknown field", Opts: []httpio.JSONOption{httpio.JSONDisallowUnknownFields()}, Input: `{"a":1,"c":2}`, ExpErr: `json: unknown field "c"`},
+		{Name: "use number", Opts: []httpio.JSONOption{httpio.JSONUseNumber()}, Input: `{"a":1.50}`, Exp: &dst{A: json.Number("1.50")}},
+		{Name: "trailing data", Opts: []httpio.JSONOption{httpio.JSONDisallowTrailingData()}, Input: `{"a":1}garbage`, ExpErr: "httpio/json: unexpected data after top-level value"},
+		{Name: "trailing value", Opts: []httpio.JSONOption{httpio.JSONDisallowTrailingData()}, Input: `{"a":1} {}`, ExpErr: "httpio/json: unexpected data after top-level value"},
+		{Name: "trailing whitespace", Opts: []httpio.JSONOption{httpio.JSONDisallowTrailingData()}, Input: "{\"a\":1}\n\t ", Exp: &dst{A: 1.0}},
+		{Name: "duplicate key", Opts: []httpio.JSONOption{httpio.JSONDisallowDuplicateKeys()}, Input: `{"a":{"x":1,"y":{"x":2}},"b":[],"a":2}`, ExpErr: "httpio/json: duplicate key 'a'"},
+		{Name: "nested duplicate key", Opts: []httpio.JSONOption{httpio.JSONDisallowDuplicateKeys()}, Input: `{"a":[{"x":1,"x":2}]}`, ExpErr: "httpio/json: duplicate key 'x'"},
+		{Name: "keys equal to values", Opts: []httpio.JSONOption{httpio.JSONDisallowDuplicateKeys()}, Input: `{"a":"a","b":[1]}`, Exp: &dst{A: "a", B: []int{1}}},
+		{Name: "max depth", Opts: []httpio.JSONOption{httpio.JSONMaxDepth(2)}, Input: `{"a":[[1]]}`, ExpErr: "httpio/json: exceeded maximum nesting depth of 2"},
+		{Name: "within max depth", Opts: []httpio.JSONOption{httpio.JSONMaxDepth(2)}, Input: `{"a":[1],"b":[2]}`, Exp: &dst{A: []interface{}{1.0}, B: []int{2}}},
+		{Name: "syntax error", Opts: []httpio.JSONOption{httpio.JSONStrict()}, Input: `{"a":`, ExpErr: "unexpected EOF"},
+		{Name: "strict", Opts: []httpio.JSONOption{httpio.JSONStrict()}, Input: `{"b":[1]}`, Exp: &dst{B: []int{1}}},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			v := &dst{}
+			err := httpio.NewJSON(c.Opts...).Decoder(strings.NewReader(c.Input)).Decode(v)
+			if c.ExpErr != "" {
+				if err == nil || err.Error() != c.ExpErr {
+					t.Fatalf("expected error '%s', got: %v", c.ExpErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(v, c.Exp) {
+				t.Fatalf("expected %#v, got: %#v", c.Exp, v)
+			}
+		})
+	}
+}
+
+func TestJSONEncoding(t *testing.T) {
+	v := map[string]interface{}{"a": "<b>"}
+	for _, c := range []struct {
+		Name   string
+		Opts   []httpio.JSONOption
+		Target string
+		Exp    string
+	}{
+		{"zero value", nil, "/", `{"a":"\u003cb\u003e"}` + "\n"},
+		{"no html escape", []httpio.JSONOption{httpio.JSONEscapeHTML(false)}, "/", `{"a":"<b>"}` + "\n"},
+		{"indent", []httpio.JSONOption{httpio.JSONIndent("", "\t")}, "/", "{\n\t\"a\": \"\\u003cb\\u003e\"\n}\n"},
+		{"pretty not asked", []httpio.JSONOption{httpio.JSONPrettyQuery("pretty")}, "/", `{"a":"\u003cb\u003e"}` + "\n"},
+		{"pretty", []httpio.JSONOption{httpio.JSONPrettyQuery("pretty")}, "/?pretty", "{\n  \"a\": \"\\u003cb\\u003e\"\n}\n"},
+		{"pretty false", []httpio.JSONOption{httpio.JSONPrettyQuery("pretty")}, "/?pretty=0", `{"a":"\u003cb\u003e"}` + "\n"},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			e := httpio.NewEgress(httpio.NewJSON(c.Opts...))
+			err := e.Render(v, w, httptest.NewRequest(http.MethodGet, c.Target, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if w.Body.String() != c.Exp {
+				t.Fatalf("expected %q, got: %q", c.Exp, w.Body.String())
+			}
+		})
+	}
+
+	buf := bytes.NewBuffer(nil)
+	err := (&httpio.JSON{}).Encoder(buf).Encode(v)
+	if err != nil || buf.String() != fmt.Sprintln(`{"a":"\u003cb\u003e"}`) {
+		t.Fatalf("unexpected zero value encoding: %q %v", buf.String(), err)
+	}
+}
+
+type countingBackend struct{ marshals, unmarshals int }
+
+func (b *countingBackend) Marshal(v interface{}) ([]byte, error) {
+	b.marshals++
+	return json.Marshal(v)
+}
+
+func (b *countingBackend) Unmarshal(data []byte, v interface{}) error {
+	b.unmarshals++
+	return json.Unmarshal(data, v)
+}
+
+type benchItem struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags"`
+	Price float64  `json:"price"`
+}
+
+//benchFast stands in for a generated (un)marshaler
+type benchFast benchItem
+
+func (b *benchFast) MarshalJSON() ([]byte, error) {
+	buf := []byte(`{"id":`)
+	buf = strconv.AppendInt(buf, int64(b.ID), 10)
+	buf = append(buf, `,"name":`...)
+	buf = strconv.AppendQuote(buf, b.Name)
+	buf = append(buf, `,"tags":[`...)
+	for i, t := range b.Tags {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+
+		buf = strconv.AppendQuote(buf, t)
+	}
+
+	buf = append(buf, `],"price":`...)
+	buf = strconv.AppendFloat(buf, b.Price, 'g', -1, 64)
+	return append(buf, '}'), nil
+}
+
+func (b *benchFast) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*benchItem)(b))
+}
+
+type jsonTestValueMarshaler struct{}
+
+func (jsonTestValueMarshaler) MarshalJSON() ([]byte, error) { return []byte(`"value"`), nil }
+
+func TestJSONBackend(t *testing.T) {
+	b := &countingBackend{}
+	j := httpio.NewJSON(httpio.JSONWithBackend(b), httpio.JSONIndent("", " "), httpio.JSONDisallowDuplicateKeys())
+
+	buf := bytes.NewBuffer(nil)
+	err := j.Encoder(buf).Encode(map[string]string{"a": "<b>"})
+	if err != nil || buf.String() != "{\n \"a\": \"\\u003cb\\u003e\"\n}\n" || b.marshals != 1 {
+		t.Fatalf("unexpected backend encoding: %q %v (%d)", buf.String(), err, b.marshals)
+	}
+
+	buf.Reset()
+	err = j.Encoder(buf).Encode(&benchFast{ID: 1, Name: "<a>"})
+	if err != nil || buf.String() != "{\n \"id\": 1,\n \"name\": \"\\u003ca\\u003e\",\n \"tags\": [],\n \"price\": 0\n}\n" || b.marshals != 1 {
+		t.Fatalf("expected marshaler to skip the backend: %q %v (%d)", buf.String(), err, b.marshals)
+	}
+
+	buf.Reset()
+	err = j.Encoder(buf).Encode((*jsonTestValueMarshaler)(nil))
+	if err != nil || buf.String() != "null\n" {
+		t.Fatalf("expected nil pointer to encode as null: %q %v", buf.String(), err)
+	}
+
+	var v map[string]int
+	err = j.Decoder(strings.NewReader(`{"a":1}`)).Decode(&v)
+	if err != nil || v["a"] != 1 || b.unmarshals != 1 {
+		t.Fatalf("unexpected backend decoding: %v %v (%d)", v, err, b.unmarshals)
+	}
+
+	err = j.Decoder(strings.NewReader(`{"a":1,"a":2}`)).Decode(&v)
+	if err == nil || b.unmarshals != 1 {
+		t.Fatalf("expected checks to run before the backend, got: %v", err)
+	}
+
+	dec := j.Decoder(strings.NewReader(`{"a":3} {"a":4}`))
+	if err = dec.Decode(&v); err != nil || v["a"] != 3 {
+		t.Fatalf("unexpected first value: %v %v", v, err)
+	}
+
+	if err = dec.Decode(&v); err != nil || v["a"] != 4 || b.unmarshals != 3 {
+		t.Fatalf("expected the next value to be decoded: %v %v (%d)", v, err, b.unmarshals)
+	}
+
+	var f benchFast
+	err = j.Decoder(strings.NewReader(`{"id":2}`)).Decode(&f)
+	if err != nil || f.ID != 2 || b.unmarshals != 3 {
+		t.Fatalf("expected unmarshaler to skip the backend: %v %v (%d)", f, err, b.unmarshals)
+	}
+
+	raw := httpio.NewJSON(httpio.JSONWithBackend(httpio.JSONFuncs{MarshalFunc: json.Marshal, UnmarshalFunc: json.Unmarshal}), httpio.JSONEscapeHTML(false))
+	buf.Reset()
+	err = raw.Encoder(buf).Encode(&benchFast{Name: "<a>"})
+	if err != nil || buf.String() != `{"id":0,"name":"<a>","tags":[],"price":0}`+"\n" {
+		t.Fatalf("unexpected unescaped encoding: %q %v", buf.String(), err)
+	}
+}
+
+func benchJSON() map[string]*httpio.JSON {
+	return map[string]*httpio.JSON{
+		"std":     {},
+		"backend": httpio.NewJSON(httpio.JSONWithBackend(httpio.JSONFuncs{MarshalFunc: json.Marshal, UnmarshalFunc: json.Unmarshal})),
+	}
+}
+
+func BenchmarkJSONEncode(b *testing.B) {
+	item := benchItem{ID: 42, Name: "widget", Tags: []string{"a", "b", "c"}, Price: 9.95}
+	for name, j := range benchJSON() {
+		for kind, v := range map[string]interface{}{"reflect": &item, "marshaler": (*benchFast)(&item)} {
+			b.Run(name+"/"+kind, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if err := j.Encoder(io.Discard).Encode(v); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkJSONDecode(b *testing.B) {
+	data := []byte(`{"id":42,"name":"widget","tags":["a","b","c"],"price":9.95}`)
+	for name, j := range benchJSON() {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var v benchItem
+				if err := j.Decoder(bytes.NewReader(data)).Decode(&v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
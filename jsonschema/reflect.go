@@ -0,0 +1,492 @@
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Schemaer can be implemented by types that describe their own schema
+type Schemaer interface {
+	JSONSchema() *Schema
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	rawType       = reflect.TypeOf(json.RawMessage{})
+	schemaerType  = reflect.TypeOf((*Schemaer)(nil)).Elem()
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textType      = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	pkgQualifier  = regexp.MustCompile(`[\w./-]+\.`)
+	tagKeyword    = regexp.MustCompile(`^[A-Za-z]\w*=`)
+)
+
+//Generator derives schemas from Go types the way encoding/json would encode them. Named struct types
+//are added as definitions and referred to using the RefPrefix. Invalid 'jsonschema' struct tags don't
+//stop the generation, the first is reported by Err
+type Generator struct {
+	RefPrefix string
+	Defs      map[string]*Schema
+
+	//SkipField can be set to leave struct fields out of the schema, e.g. fields bound from elsewhere
+	SkipField func(f reflect.StructField) bool
+
+	names map[reflect.Type]string
+	err   error
+}
+
+//NewGenerator creates a generator that refers to definitions using the given prefix, for example
+//"#/$defs/" or "#/components/schemas/"
+func NewGenerator(refPrefix string) *Generator {
+	return &Generator{RefPrefix: refPrefix, Defs: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+//Err returns the first error in the struct tags of the types that were generated so far
+func (g *Generator) Err() error { return g.err }
+
+//For returns a self-contained schema document for the type of v with all definitions under $defs
+func For(v interface{}) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	g := NewGenerator("#/$defs/")
+	s := g.Generate(t)
+	if g.err != nil {
+		return nil, g.err
+	} else if s.Bool != nil {
+		return s, nil
+	}
+
+	if len(g.Defs) > 0 {
+		s.Defs = g.Defs
+	}
+
+	s.Schema = Draft
+	return s, nil
+}
+
+//Generate returns the schema for type t, named struct types are returned as a reference
+func (g *Generator) Generate(t reflect.Type) *Schema {
+	if t == nil {
+		return True()
+	}
+
+	if t.Implements(schemaerType) {
+		return reflect.Zero(t).Interface().(Schemaer).JSONSchema()
+	}
+
+	if t.Kind() == reflect.Ptr {
+		if reflect.PointerTo(t.Elem()).Implements(schemaerType) && t.Elem().Kind() != reflect.Ptr {
+			return reflect.New(t.Elem()).Interface().(Schemaer).JSONSchema()
+		}
+
+		return nullable(g.Generate(t.Elem()))
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	case t == rawType:
+		return True()
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		return True() //encodes itself, we cannot know its shape
+	case t.Implements(textType) || reflect.PointerTo(t).Implements(textType):
+		return &Schema{Type: Types{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		min := 0.0
+		return &Schema{Type: Types{"integer"}, Minimum: &min}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &Schema{Type: Types{"string"}, ContentEncoding: "base64"}
+		}
+
+		s := &Schema{Type: Types{"array"}, Items: g.Generate(t.Elem())}
+		if t.Kind() == reflect.Slice {
+			s.Type = Types{"array", "null"}
+		} else {
+			n := t.Len()
+			s.MinItems, s.MaxItems = &n, &n
+		}
+
+		return s
+	case reflect.Map:
+		s := &Schema{Type: Types{"object", "null"}, AdditionalProperties: g.Generate(t.Elem())}
+		if k := t.Key().Kind(); k >= reflect.Int && k <= reflect.Uint64 {
+			s.PropertyNames = &Schema{Pattern: "^-?[0-9]+$"}
+		}
+
+		return s
+	case reflect.Struct:
+		return g.structRef(t)
+	case reflect.Interface:
+		return True()
+	}
+
+	return False() //chan, func, complex: cannot be encoded
+}
+
+//nullable returns a schema that also allows null, as nil pointers are encoded as such
+func nullable(s *Schema) *Schema {
+	switch {
+	case s.Bool != nil:
+		return s
+	case s.Ref != "":
+		return &Schema{AnyOf: []*Schema{s, {Type: Types{"null"}}}}
+	case len(s.Type) > 0:
+		for _, t := range s.Type {
+			if t == "null" {
+				return s
+			}
+		}
+
+		cp := *s
+		cp.Type = append(Types{}, s.Type...)
+		cp.Type = append(cp.Type, "null")
+		return &cp
+	}
+
+	return s
+}
+
+func (g *Generator) structRef(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return g.structSchema(t)
+	}
+
+	name, ok := g.names[t]
+	if !ok {
+		name = t.Name()
+		if i := strings.IndexByte(name, '['); i > 0 {
+			name = name[:i] + "Of" + strings.Map(func(r rune) rune {
+				if r == '[' || r == ']' || r == '*' || r == ',' || r == ' ' {
+					return -1
+				}
+				return r
+			}, pkgQualifier.ReplaceAllString(name[i:], ""))
+		}
+
+		for n, base := 2, name; g.Defs[name] != nil; n++ {
+			name = base + strconv.Itoa(n)
+		}
+
+		g.names[t] = name
+		g.Defs[name] = &Schema{} //placeholder for recursive types
+		*g.Defs[name] = *g.structSchema(t)
+	}
+
+	return &Schema{Ref: g.RefPrefix + name}
+}
+
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: Types{"object"}, Properties: map[string]*Schema{}}
+	g.addFields(s, t)
+	if len(s.Properties) == 0 {
+		s.Properties = nil
+	}
+
+	return s
+}
+
+func (g *Generator) addFields(s *Schema, t reflect.Type) {
+	for _, jf := range g.jsonFields(t) {
+		f, name, opts, ft := jf.field, jf.name, jf.opts, jf.field.Type
+		if f.Anonymous && !jf.tagged && ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		fs := g.Generate(ft)
+		if hasOpt(opts, "string") {
+			switch ft.Kind() {
+			case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+				fs = &Schema{Type: Types{"string"}}
+			}
+		}
+
+		required := !hasOpt(opts, "omitempty") && !hasOpt(opts, "omitzero") && f.Type.Kind() != reflect.Ptr
+		fs, required, err := applyTags(fs, f, required)
+		if err != nil && g.err == nil {
+			g.err = err
+		}
+
+		s.Properties[name] = fs
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+//jsonField is a field as encoding/json sees it, possibly promoted from an embedded struct
+type jsonField struct {
+	field  reflect.StructField
+	name   string
+	opts   string
+	index  []int
+	tagged bool
+}
+
+//jsonFields lists the fields encoding/json would encode for struct type t. Promoted fields are
+//collected breadth first and conflicting names are resolved as encoding/json does: the shallowest
+//field wins, a tagged field wins from untagged ones at the same depth and otherwise all are dropped.
+func (g *Generator) jsonFields(t reflect.Type) []jsonField {
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	var fields []jsonField
+	visited := map[reflect.Type]bool{}
+	for level := []embedded{{typ: t}}; len(level) > 0; {
+		var next []embedded
+		for _, e := range level {
+			if visited[e.typ] {
+				continue
+			}
+
+			for i := 0; i < e.typ.NumField(); i++ {
+				f := e.typ.Field(i)
+				tag := f.Tag.Get("json")
+				if tag == "-" || (g.SkipField != nil && g.SkipField(f)) {
+					continue
+				}
+
+				name, opts, _ := strings.Cut(tag, ",")
+				index := append(slices.Clone(e.index), i)
+				if f.Anonymous && name == "" {
+					ft := f.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+
+					if ft.Kind() == reflect.Struct {
+						next = append(next, embedded{ft, index})
+						continue
+					}
+				}
+
+				if !f.IsExported() {
+					continue
+				}
+
+				jf := jsonField{field: f, name: name, opts: opts, index: index, tagged: name != ""}
+				if jf.name == "" {
+					jf.name = f.Name
+				}
+
+				fields = append(fields, jf)
+			}
+		}
+
+		for _, e := range level {
+			visited[e.typ] = true //types embedded twice at one depth still conflict with themselves
+		}
+
+		level = next
+	}
+
+	slices.SortStableFunc(fields, func(a, b jsonField) int {
+		if c := strings.Compare(a.name, b.name); c != 0 {
+			return c
+		}
+
+		if c := len(a.index) - len(b.index); c != 0 {
+			return c
+		}
+
+		if a.tagged != b.tagged {
+			if a.tagged {
+				return -1
+			}
+
+			return 1
+		}
+
+		return 0
+	})
+
+	dominant := fields[:0]
+	for i := 0; i < len(fields); {
+		j := i + 1
+		for j < len(fields) && fields[j].name == fields[i].name {
+			j++
+		}
+
+		if j == i+1 || len(fields[i+1].index) > len(fields[i].index) || fields[i].tagged != fields[i+1].tagged {
+			dominant = append(dominant, fields[i])
+		}
+
+		i = j
+	}
+
+	slices.SortFunc(dominant, func(a, b jsonField) int { return slices.Compare(a.index, b.index) })
+	return dominant
+}
+
+func hasOpt(opts, opt string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == opt {
+			return true
+		}
+	}
+
+	return false
+}
+
+//applyTags refines the field schema using the 'doc' tag as description and the comma separated
+//keywords of the 'jsonschema' tag, e.g: `jsonschema:"minLength=1,maxLength=20,enum=a|b,optional"`
+func applyTags(fs *Schema, f reflect.StructField, required bool) (*Schema, bool, error) {
+	doc, hasDoc := f.Tag.Lookup("doc")
+	tag, hasTag := f.Tag.Lookup("jsonschema")
+	if !hasDoc && !hasTag {
+		return fs, required, nil
+	}
+
+	if fs.Ref != "" || fs.Bool != nil {
+		fs = &Schema{AllOf: []*Schema{fs}} //don't modify shared definitions
+	} else {
+		cp := *fs
+		fs = &cp
+	}
+
+	var err error
+	fs.Description = doc
+	for _, kv := range splitTag(tag) {
+		k, v, _ := strings.Cut(kv, "=")
+		switch k {
+		case "required":
+			required = true
+		case "optional":
+			required = false
+		case "deprecated":
+			fs.Deprecated = true
+		case "readOnly":
+			fs.ReadOnly = true
+		case "writeOnly":
+			fs.WriteOnly = true
+		case "uniqueItems":
+			fs.UniqueItems = true
+		case "title":
+			fs.Title = v
+		case "format":
+			fs.Format = v
+		case "pattern":
+			fs.Pattern = v
+		case "minLength":
+			fs.MinLength, err = intPtr(v)
+		case "maxLength":
+			fs.MaxLength, err = intPtr(v)
+		case "minItems":
+			fs.MinItems, err = intPtr(v)
+		case "maxItems":
+			fs.MaxItems, err = intPtr(v)
+		case "minimum":
+			fs.Minimum, err = floatPtr(v)
+		case "maximum":
+			fs.Maximum, err = floatPtr(v)
+		case "exclusiveMinimum":
+			fs.ExclusiveMinimum, err = floatPtr(v)
+		case "exclusiveMaximum":
+			fs.ExclusiveMaximum, err = floatPtr(v)
+		case "multipleOf":
+			fs.MultipleOf, err = floatPtr(v)
+		case "default":
+			fs.Default = tagValue(f.Type, v)
+		case "example":
+			fs.Examples = append(fs.Examples, tagValue(f.Type, v))
+		case "enum":
+			for _, e := range strings.Split(v, "|") {
+				fs.Enum = append(fs.Enum, tagValue(f.Type, e))
+			}
+		case "":
+		default:
+			err = fmt.Errorf("unsupported keyword %q", k)
+		}
+
+		if err != nil {
+			return fs, required, fmt.Errorf("jsonschema: invalid tag of field %s: %v", f.Name, err)
+		}
+	}
+
+	return fs, required, nil
+}
+
+//tagFlags are the keywords of the 'jsonschema' tag without a value
+var tagFlags = map[string]bool{
+	"required": true, "optional": true, "deprecated": true, "readOnly": true, "writeOnly": true, "uniqueItems": true,
+}
+
+//splitTag splits the tag into its keywords, values may contain commas (e.g. in patterns) as the tag is
+//only split where the next keyword starts
+func splitTag(tag string) (kvs []string) {
+	for _, part := range strings.Split(tag, ",") {
+		if len(kvs) > 0 && !tagKeyword.MatchString(part) && !tagFlags[part] {
+			kvs[len(kvs)-1] += "," + part
+			continue
+		}
+
+		kvs = append(kvs, part)
+	}
+
+	return kvs
+}
+
+func intPtr(v string) (*int, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid integer %q", v)
+	}
+
+	return &n, nil
+}
+
+func floatPtr(v string) (*float64, error) {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", v)
+	}
+
+	return &f, nil
+}
+
+//tagValue converts a tag value to the json value of the field's (element) type
+func tagValue(t reflect.Type, v string) interface{} {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(v)
+		if err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(v, 64)
+		if err == nil {
+			return f
+		}
+	}
+
+	return v
+}
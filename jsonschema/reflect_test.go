@@ -0,0 +1,182 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/advanderveer/go-httpio/jsonschema"
+)
+
+type testAddress struct {
+	Street string `json:"street" doc:"street and number" jsonschema:"minLength=1"`
+	Zip    string `json:"zip,omitempty" jsonschema:"pattern=^[0-9]{4}$"`
+}
+
+type testBase struct {
+	ID      uint      `json:"id" jsonschema:"readOnly"`
+	Created time.Time `json:"created"`
+}
+
+type testPerson struct {
+	testBase
+	Name     string            `json:"name" jsonschema:"enum=alice|bob"`
+	Age      int               `json:"age,omitempty" jsonschema:"minimum=0,maximum=150,default=18"`
+	Count    int64             `json:"count,string"`
+	Home     *testAddress      `json:"home"`
+	Work     testAddress       `json:"work" jsonschema:"optional"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Avatar   []byte            `json:"avatar,omitempty"`
+	Friends  []*testPerson     `json:"friends,omitempty"`
+	Extra    json.RawMessage   `json:"extra,omitempty"`
+	Ignored  string            `json:"-"`
+	internal string
+}
+
+type testShadowed struct {
+	Name  string `json:"name"`
+	Color string
+	Size  int
+}
+
+type testSized struct {
+	Size int `json:"Size"`
+}
+
+type testShadowing struct {
+	testShadowed
+	*testSized
+	Name  bool `json:"name,omitempty"`
+	Color bool
+}
+
+type testPage[T any] struct {
+	Items []T `json:"items"`
+}
+
+type testCustom struct{}
+
+func (testCustom) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{Type: jsonschema.Types{"string"}, Format: "uuid"}
+}
+
+func TestFor(t *testing.T) {
+	for _, c := range []struct {
+		Name string
+		V    interface{}
+		Exp  string
+	}{
+		{"string", "", `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"string"}`},
+		{"uint", uint8(0), `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"integer","minimum":0}`},
+		{"array", [2]bool{}, `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"array","items":{"type":"boolean"},"minItems":2,"maxItems":2}`},
+		{"int map", map[int]float64{}, `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":["object","null"],"additionalProperties":{"type":"number"},"propertyNames":{"pattern":"^-?[0-9]+$"}}`},
+		{"interface", new(interface{}), `true`},
+		{"schemaer", testCustom{}, `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"string","format":"uuid"}`},
+		{"generic", testPage[testAddress]{}, `{"$schema":"https://json-schema.org/draft/2020-12/schema","$ref":"#/$defs/testPageOftestAddress","$defs":{` +
+			`"testAddress":{"type":"object","properties":{"street":{"description":"street and number","type":"string","minLength":1},"zip":{"type":"string","pattern":"^[0-9]{4}$"}},"required":["street"]},` +
+			`"testPageOftestAddress":{"type":"object","properties":{"items":{"type":["array","null"],"items":{"$ref":"#/$defs/testAddress"}}},"required":["items"]}}}`},
+		{"shadowed", testShadowing{}, `{"$schema":"https://json-schema.org/draft/2020-12/schema","$ref":"#/$defs/testShadowing","$defs":{` +
+			`"testShadowing":{"type":"object","properties":{"Color":{"type":"boolean"},"Size":{"type":"integer"},"name":{"type":"boolean"}},"required":["Size","Color"]}}}`},
+		{"struct", &testPerson{}, `{"$schema":"https://json-schema.org/draft/2020-12/schema","$ref":"#/$defs/testPerson","$defs":{` +
+			`"testAddress":{"type":"object","properties":{"street":{"description":"street and number","type":"string","minLength":1},"zip":{"type":"string","pattern":"^[0-9]{4}$"}},"required":["street"]},` +
+			`"testPerson":{"type":"object","properties":{` +
+			`"age":{"default":18,"type":"integer","minimum":0,"maximum":150},` +
+			`"avatar":{"type":"string","contentEncoding":"base64"},` +
+			`"count":{"type":"string"},` +
+			`"created":{"type":"string","format":"date-time"},` +
+			`"extra":true,` +
+			`"friends":{"type":["array","null"],"items":{"anyOf":[{"$ref":"#/$defs/testPerson"},{"type":"null"}]}},` +
+			`"home":{"anyOf":[{"$ref":"#/$defs/testAddress"},{"type":"null"}]},` +
+			`"id":{"readOnly":true,"type":"integer","minimum":0},` +
+			`"labels":{"type":["object","null"],"additionalProperties":{"type":"string"}},` +
+			`"name":{"type":"string","enum":["alice","bob"]},` +
+			`"tags":{"type":["array","null"],"items":{"type":"string"}},` +
+			`"work":{"allOf":[{"$ref":"#/$defs/testAddress"}]}},` +
+			`"required":["id","created","name","count","tags"]}}}`},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			s, err := jsonschema.For(c.V)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := json.Marshal(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(data) != c.Exp {
+				t.Fatalf("expected schema:\n%s\ngot:\n%s", c.Exp, data)
+			}
+		})
+	}
+}
+
+func TestGeneratorSkipField(t *testing.T) {
+	g := jsonschema.NewGenerator("#/components/schemas/")
+	g.SkipField = func(f reflect.StructField) bool { return f.Name == "Zip" }
+
+	s := g.Generate(reflect.TypeOf(testAddress{}))
+	if s.Ref != "#/components/schemas/testAddress" {
+		t.Fatalf("expected ref, got: %q", s.Ref)
+	}
+
+	def := g.Defs["testAddress"]
+	if len(def.Properties) != 1 || def.Properties["street"] == nil {
+		t.Fatalf("expected only street property, got: %v", def.Properties)
+	}
+}
+
+func TestSchemaRoundtrip(t *testing.T) {
+	in := `{"type":["string","null"],"items":false,"not":true}`
+	var s jsonschema.Schema
+	if err := json.Unmarshal([]byte(in), &s); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Items.Bool == nil || *s.Items.Bool || len(s.Type) != 2 {
+		t.Fatalf("unexpected schema: %+v", s)
+	}
+
+	out, _ := json.Marshal(&s)
+	if string(out) != `{"type":["string","null"],"items":false,"not":true}` {
+		t.Fatalf("unexpected roundtrip: %s", out)
+	}
+}
+
+func TestTags(t *testing.T) {
+	for _, c := range []struct {
+		Name   string
+		V      interface{}
+		Exp    string
+		ExpErr string
+	}{
+		{"commas in values", struct {
+			A string `json:"a" jsonschema:"pattern=^[a-z]{1,3}$,minLength=1,example=a,b,optional"`
+		}{}, `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"a":{"examples":["a,b"],"type":"string","minLength":1,"pattern":"^[a-z]{1,3}$"}}}`, ""},
+		{"unknown keyword", struct {
+			A string `jsonschema:"minLenght=1"`
+		}{}, "", `jsonschema: invalid tag of field A: unsupported keyword "minLenght"`},
+		{"invalid integer", struct {
+			A string `jsonschema:"maxLength=ten"`
+		}{}, "", `jsonschema: invalid tag of field A: invalid integer "ten"`},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			s, err := jsonschema.For(c.V)
+			if c.ExpErr != "" {
+				if err == nil || err.Error() != c.ExpErr {
+					t.Fatalf("expected error '%s', got: %v", c.ExpErr, err)
+				}
+
+				return
+			}
+
+			data, _ := json.Marshal(s)
+			if err != nil || string(data) != c.Exp {
+				t.Fatalf("expected schema:\n%s\ngot:\n%s %v", c.Exp, data, err)
+			}
+		})
+	}
+}
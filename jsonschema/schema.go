@@ -0,0 +1,171 @@
+//Package jsonschema describes Go types as JSON Schema (draft 2020-12) documents
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+//Draft is the dialect of the schemas in this package
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+//Types holds the "type" keyword, it is encoded as a plain string when it
+//holds a single type
+type Types []string
+
+//MarshalJSON encodes a single type as a string and multiple as an array
+func (ts Types) MarshalJSON() ([]byte, error) {
+	if len(ts) == 1 {
+		return json.Marshal(ts[0])
+	}
+	return json.Marshal([]string(ts))
+}
+
+//UnmarshalJSON decodes the type from either a string or an array
+func (ts *Types) UnmarshalJSON(data []byte) error {
+	var t string
+	if err := json.Unmarshal(data, &t); err == nil {
+		*ts = Types{t}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(ts))
+}
+
+//Schema is a JSON Schema. A schema with Bool set is a boolean schema that
+//encodes as just true or false
+type Schema struct {
+	Bool *bool `json:"-"`
+
+	Schema  string             `json:"$schema,omitempty"`
+	ID      string             `json:"$id,omitempty"`
+	Ref     string             `json:"$ref,omitempty"`
+	Anchor  string             `json:"$anchor,omitempty"`
+	Defs    map[string]*Schema `json:"$defs,omitempty"`
+	Comment string             `json:"$comment,omitempty"`
+
+	Title       string        `json:"title,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Examples    []interface{} `json:"examples,omitempty"`
+	Deprecated  bool          `json:"deprecated,omitempty"`
+	ReadOnly    bool          `json:"readOnly,omitempty"`
+	WriteOnly   bool          `json:"writeOnly,omitempty"`
+
+	Type  Types         `json:"type,omitempty"`
+	Enum  []interface{} `json:"enum,omitempty"`
+	Const interface{}   `json:"const,omitempty"`
+
+	MultipleOf       *float64 `json:"multipleOf,omitempty"`
+	Minimum          *float64 `json:"minimum,omitempty"`
+	Maximum          *float64 `json:"maximum,omitempty"`
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+
+	MinLength        *int   `json:"minLength,omitempty"`
+	MaxLength        *int   `json:"maxLength,omitempty"`
+	Pattern          string `json:"pattern,omitempty"`
+	Format           string `json:"format,omitempty"`
+	ContentEncoding  string `json:"contentEncoding,omitempty"`
+	ContentMediaType string `json:"contentMediaType,omitempty"`
+
+	Items       *Schema   `json:"items,omitempty"`
+	PrefixItems []*Schema `json:"prefixItems,omitempty"`
+	MinItems    *int      `json:"minItems,omitempty"`
+	MaxItems    *int      `json:"maxItems,omitempty"`
+	UniqueItems bool      `json:"uniqueItems,omitempty"`
+	Contains    *Schema   `json:"contains,omitempty"`
+	MinContains *int      `json:"minContains,omitempty"`
+	MaxContains *int      `json:"maxContains,omitempty"`
+
+	Properties           map[string]*Schema  `json:"properties,omitempty"`
+	PatternProperties    map[string]*Schema  `json:"patternProperties,omitempty"`
+	AdditionalProperties *Schema             `json:"additionalProperties,omitempty"`
+	PropertyNames        *Schema             `json:"propertyNames,omitempty"`
+	Required             []string            `json:"required,omitempty"`
+	MinProperties        *int                `json:"minProperties,omitempty"`
+	MaxProperties        *int                `json:"maxProperties,omitempty"`
+	DependentRequired    map[string][]string `json:"dependentRequired,omitempty"`
+	DependentSchemas     map[string]*Schema  `json:"dependentSchemas,omitempty"`
+
+	AllOf []*Schema `json:"allOf,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+	Not   *Schema   `json:"not,omitempty"`
+	If    *Schema   `json:"if,omitempty"`
+	Then  *Schema   `json:"then,omitempty"`
+	Else  *Schema   `json:"else,omitempty"`
+}
+
+//True returns the boolean schema that validates everything
+func True() *Schema { b := true; return &Schema{Bool: &b} }
+
+//False returns the boolean schema that validates nothing
+func False() *Schema { b := false; return &Schema{Bool: &b} }
+
+//Subschemas returns the schemas that are directly nested in this schema, for maps in order of their keys
+func (s *Schema) Subschemas() (subs []*Schema) {
+	subs = append(subs, s.Items, s.Contains, s.AdditionalProperties, s.PropertyNames, s.Not, s.If, s.Then, s.Else)
+	subs = append(subs, s.PrefixItems...)
+	subs = append(subs, s.AllOf...)
+	subs = append(subs, s.AnyOf...)
+	subs = append(subs, s.OneOf...)
+	for _, m := range []map[string]*Schema{s.Defs, s.Properties, s.PatternProperties, s.DependentSchemas} {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+		for _, k := range keys {
+			subs = append(subs, m[k])
+		}
+	}
+
+	n := 0
+	for _, sub := range subs {
+		if sub != nil {
+			subs[n] = sub
+			n++
+		}
+	}
+
+	return subs[:n]
+}
+
+//Load reads a schema from a JSON file
+func Load(name string) (*Schema, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Schema{}
+	err = json.Unmarshal(data, s)
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: failed to decode '%s': %v", name, err)
+	}
+
+	return s, nil
+}
+
+type schemaFields Schema
+
+//MarshalJSON encodes boolean schemas as true or false
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.Bool != nil {
+		return json.Marshal(*s.Bool)
+	}
+	return json.Marshal((*schemaFields)(s))
+}
+
+//UnmarshalJSON decodes both boolean and object schemas
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*s = Schema{Bool: &b}
+		return nil
+	}
+	return json.Unmarshal(data, (*schemaFields)(s))
+}
@@ -0,0 +1,693 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+//Violation describes a single place where an instance does not conform to the schema
+type Violation struct {
+	Path    string `json:"path"`    //JSON Pointer to the offending value in the instance
+	Keyword string `json:"keyword"` //the schema keyword that failed
+	Message string `json:"message"`
+}
+
+//ValidationError is returned when an instance does not conform to the schema
+type ValidationError struct {
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		path := v.Path
+		if path == "" {
+			path = "(root)"
+		}
+
+		msgs = append(msgs, path+": "+v.Message)
+	}
+
+	return "jsonschema: " + strings.Join(msgs, "; ")
+}
+
+//Validator checks JSON instances against a schema. References are resolved within the document that
+//holds the schema, remote references are not supported such that validation works offline. The
+//keywords of the 2020-12 draft are supported except for the unevaluated* and dynamic reference
+//keywords. The 'format' keyword is asserted for date-time, date, time, email, uuid, uri, ipv4 and ipv6.
+type Validator struct {
+	//Request enables request semantics: required properties that are marked readOnly may be absent
+	Request bool
+
+	schema   *Schema
+	root     interface{}
+	id       string
+	refs     map[string]*Schema
+	patterns map[string]*regexp.Regexp
+}
+
+//NewValidator prepares validation against schema 's', references must point into 's' itself
+func NewValidator(s *Schema) (*Validator, error) {
+	return Compile(s, "")
+}
+
+//Compile prepares validation against the schema at JSON Pointer 'pointer' of document 'doc'. The document
+//can be anything that encodes as JSON, e.g. a *Schema or an OpenAPI document whose component schemas are
+//referred to. All references and patterns are checked up front.
+func Compile(doc interface{}, pointer string) (*Validator, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: failed to encode document: %v", err)
+	}
+
+	var root interface{}
+	err = json.Unmarshal(data, &root)
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: failed to decode document: %v", err)
+	}
+
+	v := &Validator{root: root, refs: map[string]*Schema{}, patterns: map[string]*regexp.Regexp{}}
+	if m, ok := root.(map[string]interface{}); ok {
+		v.id, _ = m["$id"].(string)
+	}
+
+	v.schema, err = v.load(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	err = v.prepare(v.schema, map[*Schema]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+//load decodes the schema at the JSON Pointer of the root document
+func (v *Validator) load(pointer string) (*Schema, error) {
+	node := v.root
+	if pointer != "" {
+		for _, tok := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+			tok = strings.NewReplacer("~1", "/", "~0", "~").Replace(tok)
+			switch n := node.(type) {
+			case map[string]interface{}:
+				node = n[tok]
+			case []interface{}:
+				i, err := strconv.Atoi(tok)
+				if err != nil || i < 0 || i >= len(n) {
+					return nil, fmt.Errorf("jsonschema: pointer '%s' does not resolve", pointer)
+				}
+
+				node = n[i]
+			default:
+				node = nil
+			}
+
+			if node == nil {
+				return nil, fmt.Errorf("jsonschema: pointer '%s' does not resolve", pointer)
+			}
+		}
+	}
+
+	data, _ := json.Marshal(node)
+	s := &Schema{}
+	err := json.Unmarshal(data, s)
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: value at pointer '%s' is not a schema: %v", pointer, err)
+	}
+
+	return s, nil
+}
+
+//resolve returns the schema a local reference points to, it is cached such that recursive schemas
+//resolve to the same value
+func (v *Validator) resolve(ref string) (*Schema, error) {
+	if s, ok := v.refs[ref]; ok {
+		return s, nil
+	}
+
+	frag, ok := strings.CutPrefix(ref, v.id+"#")
+	if !ok {
+		return nil, fmt.Errorf("jsonschema: only local references are supported, got '%s'", ref)
+	}
+
+	frag, err := url.PathUnescape(frag)
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: invalid reference '%s': %v", ref, err)
+	}
+
+	var s *Schema
+	switch {
+	case frag == "" || strings.HasPrefix(frag, "/"):
+		s, err = v.load(frag)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		node := findAnchor(v.root, frag)
+		if node == nil {
+			return nil, fmt.Errorf("jsonschema: anchor '%s' does not exist", frag)
+		}
+
+		data, _ := json.Marshal(node)
+		s = &Schema{}
+		_ = json.Unmarshal(data, s) //it was decoded as a schema before
+	}
+
+	v.refs[ref] = s
+	return s, nil
+}
+
+//findAnchor returns the schema object in 'node' that declares the anchor, nil if there is none
+func findAnchor(node interface{}, anchor string) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if n["$anchor"] == anchor {
+			return n
+		}
+
+		for _, k := range sortedKeys(n) {
+			if found := findAnchor(n[k], anchor); found != nil {
+				return found
+			}
+		}
+	case []interface{}:
+		for _, e := range n {
+			if found := findAnchor(e, anchor); found != nil {
+				return found
+			}
+		}
+	}
+
+	return nil
+}
+
+//prepare resolves all references and compiles all patterns reachable from 's'
+func (v *Validator) prepare(s *Schema, seen map[*Schema]bool) error {
+	if s == nil || seen[s] {
+		return nil
+	}
+
+	seen[s] = true
+	if s.Ref != "" {
+		rs, err := v.resolve(s.Ref)
+		if err != nil {
+			return err
+		}
+
+		err = v.prepare(rs, seen)
+		if err != nil {
+			return err
+		}
+	}
+
+	pats := []string{s.Pattern}
+	for p := range s.PatternProperties {
+		pats = append(pats, p)
+	}
+
+	for _, p := range pats {
+		if _, ok := v.patterns[p]; ok || p == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("jsonschema: invalid pattern '%s': %v", p, err)
+		}
+
+		v.patterns[p] = re
+	}
+
+	for _, sub := range s.Subschemas() {
+		err := v.prepare(sub, seen)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//ValidateJSON decodes 'data' and validates it, it returns a *ValidationError if the instance does not
+//conform to the schema
+func (v *Validator) ValidateJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var inst interface{}
+	err := dec.Decode(&inst)
+	if err != nil {
+		return err
+	}
+
+	if _, err = dec.Token(); err != io.EOF {
+		return fmt.Errorf("jsonschema: unexpected data after top-level value")
+	}
+
+	return v.Validate(inst)
+}
+
+//Validate checks an instance as decoded by encoding/json, numbers may be decoded as float64 or
+//json.Number. It returns a *ValidationError if the instance does not conform to the schema
+func (v *Validator) Validate(inst interface{}) error {
+	var vs []Violation
+	v.validate(v.schema, inst, "", &vs)
+	if len(vs) > 0 {
+		return &ValidationError{Violations: vs}
+	}
+
+	return nil
+}
+
+func (v *Validator) valid(s *Schema, inst interface{}, path string) bool {
+	var vs []Violation
+	v.validate(s, inst, path, &vs)
+	return len(vs) == 0
+}
+
+func (v *Validator) validate(s *Schema, inst interface{}, path string, vs *[]Violation) {
+	fail := func(kw, format string, args ...interface{}) {
+		*vs = append(*vs, Violation{Path: path, Keyword: kw, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Bool != nil {
+		if !*s.Bool {
+			fail("false", "no value is allowed")
+		}
+
+		return
+	}
+
+	if s.Ref != "" {
+		v.validate(v.refs[s.Ref], inst, path, vs)
+	}
+
+	if len(s.Type) > 0 {
+		t := typeOf(inst)
+		ok := false
+		for _, st := range s.Type {
+			ok = ok || st == t || (st == "number" && t == "integer")
+		}
+
+		if !ok {
+			fail("type", "expected %s, got %s", strings.Join(s.Type, " or "), t)
+			return //other keywords would only add noise
+		}
+	}
+
+	if s.Enum != nil {
+		ok := false
+		for _, e := range s.Enum {
+			ok = ok || equal(e, inst)
+		}
+
+		if !ok {
+			fail("enum", "must be one of the enumerated values")
+		}
+	}
+
+	if s.Const != nil && !equal(s.Const, inst) {
+		fail("const", "must be equal to the constant value")
+	}
+
+	switch inst := inst.(type) {
+	case json.Number, float64:
+		r := toRat(inst)
+		if r == nil {
+			fail("type", "number is out of the supported range")
+			break
+		}
+
+		v.validateNumber(s, r, fail)
+	case string:
+		v.validateString(s, inst, fail)
+	case []interface{}:
+		v.validateArray(s, inst, path, vs, fail)
+	case map[string]interface{}:
+		v.validateObject(s, inst, path, vs, fail)
+	}
+
+	for _, sub := range s.AllOf {
+		v.validate(sub, inst, path, vs)
+	}
+
+	if len(s.AnyOf) > 0 {
+		ok := false
+		for _, sub := range s.AnyOf {
+			ok = ok || v.valid(sub, inst, path)
+		}
+
+		if !ok {
+			fail("anyOf", "must match at least one of the schemas")
+		}
+	}
+
+	if len(s.OneOf) > 0 {
+		n := 0
+		for _, sub := range s.OneOf {
+			if v.valid(sub, inst, path) {
+				n++
+			}
+		}
+
+		if n != 1 {
+			fail("oneOf", "must match exactly one of the schemas, matched %d", n)
+		}
+	}
+
+	if s.Not != nil && v.valid(s.Not, inst, path) {
+		fail("not", "must not match the schema")
+	}
+
+	if s.If != nil {
+		if v.valid(s.If, inst, path) {
+			if s.Then != nil {
+				v.validate(s.Then, inst, path, vs)
+			}
+		} else if s.Else != nil {
+			v.validate(s.Else, inst, path, vs)
+		}
+	}
+}
+
+func (v *Validator) validateNumber(s *Schema, n *big.Rat, fail func(string, string, ...interface{})) {
+	bound := func(f *float64) *big.Rat { //as written in the schema, 0.1 is not exactly 1/10 as a float
+		r, _ := new(big.Rat).SetString(strconv.FormatFloat(*f, 'g', -1, 64))
+		return r
+	}
+	if s.Minimum != nil && n.Cmp(bound(s.Minimum)) < 0 {
+		fail("minimum", "must be greater than or equal to %v", *s.Minimum)
+	}
+
+	if s.Maximum != nil && n.Cmp(bound(s.Maximum)) > 0 {
+		fail("maximum", "must be less than or equal to %v", *s.Maximum)
+	}
+
+	if s.ExclusiveMinimum != nil && n.Cmp(bound(s.ExclusiveMinimum)) <= 0 {
+		fail("exclusiveMinimum", "must be greater than %v", *s.ExclusiveMinimum)
+	}
+
+	if s.ExclusiveMaximum != nil && n.Cmp(bound(s.ExclusiveMaximum)) >= 0 {
+		fail("exclusiveMaximum", "must be less than %v", *s.ExclusiveMaximum)
+	}
+
+	if s.MultipleOf != nil && *s.MultipleOf > 0 && !new(big.Rat).Quo(n, bound(s.MultipleOf)).IsInt() {
+		fail("multipleOf", "must be a multiple of %v", *s.MultipleOf)
+	}
+}
+
+func (v *Validator) validateString(s *Schema, str string, fail func(string, string, ...interface{})) {
+	n := utf8.RuneCountInString(str)
+	if s.MinLength != nil && n < *s.MinLength {
+		fail("minLength", "must be at least %d characters long", *s.MinLength)
+	}
+
+	if s.MaxLength != nil && n > *s.MaxLength {
+		fail("maxLength", "must be at most %d characters long", *s.MaxLength)
+	}
+
+	if s.Pattern != "" && !v.patterns[s.Pattern].MatchString(str) {
+		fail("pattern", "must match pattern '%s'", s.Pattern)
+	}
+
+	if s.Format != "" && !validFormat(s.Format, str) {
+		fail("format", "must be a valid %s", s.Format)
+	}
+}
+
+func (v *Validator) validateArray(s *Schema, arr []interface{}, path string, vs *[]Violation, fail func(string, string, ...interface{})) {
+	if s.MinItems != nil && len(arr) < *s.MinItems {
+		fail("minItems", "must have at least %d items", *s.MinItems)
+	}
+
+	if s.MaxItems != nil && len(arr) > *s.MaxItems {
+		fail("maxItems", "must have at most %d items", *s.MaxItems)
+	}
+
+	for i, e := range arr {
+		switch {
+		case i < len(s.PrefixItems):
+			v.validate(s.PrefixItems[i], e, path+"/"+strconv.Itoa(i), vs)
+		case s.Items != nil:
+			v.validate(s.Items, e, path+"/"+strconv.Itoa(i), vs)
+		}
+	}
+
+	if s.UniqueItems {
+	outer:
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if equal(arr[i], arr[j]) {
+					fail("uniqueItems", "items %d and %d must be unique", i, j)
+					break outer
+				}
+			}
+		}
+	}
+
+	if s.Contains != nil {
+		n := 0
+		for i, e := range arr {
+			if v.valid(s.Contains, e, path+"/"+strconv.Itoa(i)) {
+				n++
+			}
+		}
+
+		min := 1
+		if s.MinContains != nil {
+			min = *s.MinContains
+		}
+
+		if n < min {
+			fail("contains", "must contain at least %d matching items", min)
+		}
+
+		if s.MaxContains != nil && n > *s.MaxContains {
+			fail("maxContains", "must contain at most %d matching items", *s.MaxContains)
+		}
+	}
+}
+
+func (v *Validator) validateObject(s *Schema, obj map[string]interface{}, path string, vs *[]Violation, fail func(string, string, ...interface{})) {
+	if s.MinProperties != nil && len(obj) < *s.MinProperties {
+		fail("minProperties", "must have at least %d properties", *s.MinProperties)
+	}
+
+	if s.MaxProperties != nil && len(obj) > *s.MaxProperties {
+		fail("maxProperties", "must have at most %d properties", *s.MaxProperties)
+	}
+
+	for _, name := range s.Required {
+		if _, ok := obj[name]; ok {
+			continue
+		}
+
+		if ps := s.Properties[name]; v.Request && ps != nil && ps.ReadOnly {
+			continue
+		}
+
+		fail("required", "missing required property '%s'", name)
+	}
+
+	for _, name := range sortedKeys(s.DependentRequired) {
+		if _, ok := obj[name]; !ok {
+			continue
+		}
+
+		for _, dep := range s.DependentRequired[name] {
+			if _, ok := obj[dep]; !ok {
+				fail("dependentRequired", "property '%s' is required when '%s' is present", dep, name)
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(obj) {
+		val, at := obj[name], path+"/"+escapePointer(name)
+		if s.PropertyNames != nil && !v.valid(s.PropertyNames, name, path) {
+			fail("propertyNames", "property name '%s' is not allowed", name)
+		}
+
+		if ds, ok := s.DependentSchemas[name]; ok {
+			v.validate(ds, obj, path, vs)
+		}
+
+		matched := false
+		if ps, ok := s.Properties[name]; ok {
+			matched = true
+			v.validate(ps, val, at, vs)
+		}
+
+		for _, p := range sortedKeys(s.PatternProperties) {
+			if v.patterns[p].MatchString(name) {
+				matched = true
+				v.validate(s.PatternProperties[p], val, at, vs)
+			}
+		}
+
+		if !matched && s.AdditionalProperties != nil {
+			if ap := s.AdditionalProperties; ap.Bool != nil && !*ap.Bool {
+				*vs = append(*vs, Violation{Path: at, Keyword: "additionalProperties", Message: "property is not allowed"})
+				continue
+			}
+
+			v.validate(s.AdditionalProperties, val, at, vs)
+		}
+	}
+}
+
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func typeOf(inst interface{}) string {
+	switch inst := inst.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case json.Number, float64:
+		if r := toRat(inst); r != nil && r.IsInt() {
+			return "integer"
+		}
+
+		return "number"
+	}
+
+	return fmt.Sprintf("%T", inst)
+}
+
+//maxNumberDigits and maxNumberExp bound the numbers that are validated, exact arithmetic on larger ones
+//is too costly to perform on untrusted input
+const (
+	maxNumberDigits = 1000
+	maxNumberExp    = 1000
+)
+
+//toRat returns the exact value of a number, or nil if it is out of bounds
+func toRat(n interface{}) *big.Rat {
+	switch n := n.(type) {
+	case json.Number:
+		mant, exp, _ := strings.Cut(strings.ToLower(n.String()), "e")
+		if len(mant) > maxNumberDigits {
+			return nil
+		}
+
+		if e, err := strconv.Atoi(exp); exp != "" && (err != nil || e > maxNumberExp || e < -maxNumberExp) {
+			return nil
+		}
+
+		r, ok := new(big.Rat).SetString(n.String())
+		if ok {
+			return r
+		}
+	case float64:
+		return new(big.Rat).SetFloat64(n)
+	}
+
+	return nil
+}
+
+//equal compares json values, numbers are equal if their mathematical values are
+func equal(a, b interface{}) bool {
+	switch av := a.(type) {
+	case json.Number, float64:
+		ar, br := toRat(a), toRat(b)
+		return ar != nil && br != nil && ar.Cmp(br) == 0
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+
+		for i := range av {
+			if !equal(av[i], bv[i]) {
+				return false
+			}
+		}
+
+		return true
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+
+		for k, ae := range av {
+			be, ok := bv[k]
+			if !ok || !equal(ae, be) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	switch b.(type) {
+	case []interface{}, map[string]interface{}:
+		return false
+	}
+
+	return a == b
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func validFormat(format, s string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, strings.ToUpper(s))
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, s)
+		return err == nil
+	case "time":
+		_, err := time.Parse("15:04:05Z07:00", strings.ToUpper(s))
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "uuid":
+		return uuidPattern.MatchString(s)
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	case "ipv4":
+		ip, err := netip.ParseAddr(s)
+		return err == nil && ip.Is4()
+	case "ipv6":
+		ip, err := netip.ParseAddr(s)
+		return err == nil && ip.Is6() && !strings.Contains(s, "%")
+	}
+
+	return true //unknown formats are annotations only
+}
@@ -0,0 +1,163 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/advanderveer/go-httpio/jsonschema"
+)
+
+func TestValidate(t *testing.T) {
+	for _, c := range []struct {
+		Name   string
+		Schema string
+		Inst   string
+		ExpErr string
+	}{
+		{"true", `true`, `{"a":1}`, ``},
+		{"false", `false`, `1`, `(root): no value is allowed`},
+		{"type", `{"type":"string"}`, `1`, `(root): expected string, got integer`},
+		{"type list", `{"type":["string","null"]}`, `null`, ``},
+		{"integer", `{"type":"integer"}`, `1.0`, ``},
+		{"not integer", `{"type":"integer"}`, `1.5`, `(root): expected integer, got number`},
+		{"number", `{"type":"number","minimum":1,"exclusiveMaximum":2}`, `2`, `(root): must be less than 2`},
+		{"exponent", `{"maximum":100,"type":"integer"}`, `1e2`, ``},
+		{"huge exponent", `{"items":{"type":"integer"}}`, `[1e9999999]`, `/0: expected integer, got number`},
+		{"out of range", `{"maximum":1}`, `1e999999`, `(root): number is out of the supported range`},
+		{"multipleOf", `{"multipleOf":0.1}`, `0.3`, ``},
+		{"not multipleOf", `{"multipleOf":0.1}`, `0.35`, `(root): must be a multiple of 0.1`},
+		{"string", `{"minLength":2,"maxLength":3,"pattern":"^a"}`, `"bé"`, `(root): must match pattern '^a'`},
+		{"string length", `{"maxLength":1}`, `"é"`, ``},
+		{"format", `{"format":"date-time"}`, `"2020-01-01"`, `(root): must be a valid date-time`},
+		{"formats", `{"prefixItems":[{"format":"date"},{"format":"email"},{"format":"uuid"},{"format":"ipv4"},{"format":"uri"},{"format":"custom"}]}`,
+			`["2020-01-01","a@b.co","6ba7b810-9dad-11d1-80b4-00c04fd430c8","10.0.0.1","https://example.com","x"]`, ``},
+		{"enum", `{"enum":["a",1,{"b":[true]}]}`, `{"b":[true]}`, ``},
+		{"enum number", `{"enum":[1]}`, `1.0`, ``},
+		{"not enum", `{"enum":["a",1]}`, `"b"`, `(root): must be one of the enumerated values`},
+		{"const", `{"const":"a"}`, `"b"`, `(root): must be equal to the constant value`},
+		{"array", `{"prefixItems":[{"type":"string"}],"items":{"type":"integer"},"minItems":2}`, `["a",1,"c"]`, `/2: expected integer, got string`},
+		{"unique", `{"uniqueItems":true}`, `[1,{"a":1},1.0]`, `(root): items 0 and 2 must be unique`},
+		{"contains", `{"contains":{"type":"string"},"maxContains":1}`, `["a","b",1]`, `(root): must contain at most 1 matching items`},
+		{"object", `{"properties":{"a/b":{"type":"string"},"c":{"properties":{"d~":{"type":"string"}}}},"required":["a/b","e"]}`,
+			`{"a/b":1,"c":{"d~":false}}`, `(root): missing required property 'e'; /a~1b: expected string, got integer; /c/d~0: expected string, got boolean`},
+		{"additional", `{"properties":{"a":true},"patternProperties":{"^x-":{"type":"string"}},"additionalProperties":false}`,
+			`{"a":1,"x-b":"c","d":2}`, `/d: property is not allowed`},
+		{"propertyNames", `{"propertyNames":{"maxLength":1},"maxProperties":1}`, `{"ab":1,"c":2}`,
+			`(root): must have at most 1 properties; (root): property name 'ab' is not allowed`},
+		{"dependent", `{"dependentRequired":{"a":["b"]},"dependentSchemas":{"c":{"required":["d"]}}}`, `{"a":1,"c":1}`,
+			`(root): property 'b' is required when 'a' is present; (root): missing required property 'd'`},
+		{"allOf", `{"allOf":[{"minimum":1},{"maximum":3}]}`, `4`, `(root): must be less than or equal to 3`},
+		{"anyOf", `{"anyOf":[{"type":"string"},{"type":"null"}]}`, `1`, `(root): must match at least one of the schemas`},
+		{"oneOf", `{"oneOf":[{"type":"integer"},{"type":"number"}]}`, `1`, `(root): must match exactly one of the schemas, matched 2`},
+		{"not", `{"not":{"type":"null"}}`, `null`, `(root): must not match the schema`},
+		{"if then", `{"if":{"properties":{"a":{"const":1}}},"then":{"required":["b"]},"else":{"required":["c"]}}`, `{"a":1}`, `(root): missing required property 'b'`},
+		{"if else", `{"if":{"properties":{"a":{"const":1}}},"then":{"required":["b"]},"else":{"required":["c"]}}`, `{"a":2}`, `(root): missing required property 'c'`},
+		{"ref", `{"$defs":{"node":{"type":"object","properties":{"next":{"$ref":"#/$defs/node"},"v":{"type":"integer"}}}},"$ref":"#/$defs/node"}`,
+			`{"next":{"next":{"v":"x"}}}`, `/next/next/v: expected integer, got string`},
+		{"anchor", `{"$id":"https://example.com/s","$defs":{"a":{"$anchor":"pos","minimum":0}},"items":{"$ref":"https://example.com/s#pos"}}`,
+			`[1,-1]`, `/1: must be greater than or equal to 0`},
+		{"root ref", `{"properties":{"child":{"$ref":"#"}},"additionalProperties":false}`, `{"child":{"other":1}}`, `/child/other: property is not allowed`},
+		{"trailing data", `true`, `1 2`, `jsonschema: unexpected data after top-level value`},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			var s jsonschema.Schema
+			if err := json.Unmarshal([]byte(c.Schema), &s); err != nil {
+				t.Fatal(err)
+			}
+
+			v, err := jsonschema.NewValidator(&s)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = v.ValidateJSON([]byte(c.Inst))
+			if c.ExpErr == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || strings.TrimPrefix(err.Error(), "jsonschema: ") != strings.TrimPrefix(c.ExpErr, "jsonschema: ") {
+				t.Fatalf("expected error '%s', got: %v", c.ExpErr, err)
+			}
+		})
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	s, _ := jsonschema.For(struct {
+		Name string `json:"name" jsonschema:"minLength=1"`
+	}{})
+	v, _ := jsonschema.NewValidator(s)
+
+	err := v.Validate(map[string]interface{}{"name": ""})
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected validation error, got: %v", err)
+	}
+
+	exp := []jsonschema.Violation{{Path: "/name", Keyword: "minLength", Message: "must be at least 1 characters long"}}
+	if !reflect.DeepEqual(verr.Violations, exp) {
+		t.Fatalf("expected violations %v, got: %v", exp, verr.Violations)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	doc := map[string]interface{}{
+		"components": map[string]interface{}{"schemas": map[string]interface{}{"id": map[string]interface{}{"type": "integer"}}},
+		"paths":      map[string]interface{}{"/a/{b}": map[string]interface{}{"schema": map[string]interface{}{"$ref": "#/components/schemas/id"}}},
+	}
+
+	v, err := jsonschema.Compile(doc, "/paths/~1a~1{b}/schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = v.ValidateJSON([]byte(`"x"`)); err == nil {
+		t.Fatal("expected validation error")
+	}
+
+	for ptr, exp := range map[string]string{
+		"/paths/nope":              "jsonschema: pointer '/paths/nope' does not resolve",
+		"/paths/~1a~1{b}/schema/x": "jsonschema: pointer '/paths/~1a~1{b}/schema/x' does not resolve",
+	} {
+		if _, err = jsonschema.Compile(doc, ptr); err == nil || err.Error() != exp {
+			t.Fatalf("expected error '%s', got: %v", exp, err)
+		}
+	}
+
+	for sch, exp := range map[string]string{
+		`{"$ref":"other.json#/a"}`: "jsonschema: only local references are supported, got 'other.json#/a'",
+		`{"$ref":"#/$defs/a"}`:     "jsonschema: pointer '/$defs/a' does not resolve",
+		`{"$ref":"#nope"}`:         "jsonschema: anchor 'nope' does not exist",
+		`{"pattern":"("}`:          "jsonschema: invalid pattern '(': error parsing regexp: missing closing ): `(`",
+	} {
+		var s jsonschema.Schema
+		json.Unmarshal([]byte(sch), &s)
+		if _, err = jsonschema.NewValidator(&s); err == nil || err.Error() != exp {
+			t.Fatalf("expected error '%s', got: %v", exp, err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "schema.json")
+	os.WriteFile(name, []byte(`{"type":"object","required":["a"]}`), 0o600)
+
+	s, err := jsonschema.Load(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, _ := jsonschema.NewValidator(s)
+	if err = v.ValidateJSON([]byte(`{}`)); err == nil || err.Error() != "jsonschema: (root): missing required property 'a'" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
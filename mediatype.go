@@ -0,0 +1,163 @@
+package httpio
+
+import (
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	//MediaTypeAliases maps media types to the media type of the factory that handles them, e.g: bodies of
+	//type "text/json" are decoded by the factory for "application/json". It should only be changed at init
+	MediaTypeAliases = map[string]string{
+		"text/json":             MediaTypeJSON,
+		"text/xml":              MediaTypeXML,
+		"application/x-yaml":    "application/yaml",
+		"text/yaml":             "application/yaml",
+		"application/x-msgpack": MediaTypeMsgPack,
+	}
+
+	//MediaTypeSuffixes maps structured syntax suffixes (RFC 6839) to the media type of the factory that
+	//handles them, e.g: bodies of type "application/vnd.acme.user+json" are decoded by the factory for
+	//"application/json". It should only be changed at init
+	MediaTypeSuffixes = map[string]string{
+		"json": MediaTypeJSON,
+		"xml":  MediaTypeXML,
+		"cbor": MediaTypeCBOR,
+		"yaml": "application/yaml",
+	}
+)
+
+//Aliaser can be implemented by encoder and decoder factories that handle more media types than their own.
+//Aliases may be wildcards, e.g: "image/*" or "*/*", which match media types no other factory handles
+type Aliaser interface {
+	Aliases() []string
+}
+
+//mediaTyper is implemented by both encoder and decoder factories
+type mediaTyper interface {
+	MimeType() string
+}
+
+//mediaTypes returns the media types that factory 'f' is registered for
+func mediaTypes(f mediaTyper) []string {
+	if a, ok := f.(Aliaser); ok {
+		return append([]string{f.MimeType()}, a.Aliases()...)
+	}
+
+	return []string{f.MimeType()}
+}
+
+//mediaLookup finds the factory for media type 'mt' using 'exact' to find registrations. An exact match
+//is preferred over an alias, then over the structured syntax suffix and then over wildcard registrations.
+//Media types are matched case-insensitively
+func mediaLookup(mt string, exact func(mt string) (int, bool)) (int, bool) {
+	mt = strings.ToLower(mt)
+	if i, ok := exact(mt); ok {
+		return i, true
+	}
+
+	if target, ok := MediaTypeAliases[mt]; ok {
+		if i, ok := exact(target); ok {
+			return i, true
+		}
+	}
+
+	if p := strings.LastIndexByte(mt, '+'); p >= 0 {
+		if target, ok := MediaTypeSuffixes[mt[p+1:]]; ok {
+			if i, ok := exact(target); ok {
+				return i, true
+			}
+		}
+	}
+
+	if major, _, ok := strings.Cut(mt, "/"); ok && major != "*" {
+		if i, ok := exact(major + "/*"); ok {
+			return i, true
+		}
+	}
+
+	return exact("*/*")
+}
+
+//mediaIndex maps the lowercased media types of factories to their position in the list, factories that
+//come first win
+type mediaIndex map[string]int
+
+func newMediaIndex(n int, factory func(i int) mediaTyper) mediaIndex {
+	idx := make(mediaIndex, n)
+	for i := 0; i < n; i++ {
+		for _, mt := range mediaTypes(factory(i)) {
+			mt = strings.ToLower(mt)
+			if _, ok := idx[mt]; !ok {
+				idx[mt] = i
+			}
+		}
+	}
+
+	return idx
+}
+
+//find returns the position of the factory for media type 'mt' in O(1)
+func (idx mediaIndex) find(mt string) (int, bool) {
+	return mediaLookup(mt, func(mt string) (int, bool) {
+		i, ok := idx[mt]
+		return i, ok
+	})
+}
+
+//listFind returns the position of the factory for media type 'mt' by scanning the list
+func listFind(mt string, n int, factory func(i int) mediaTyper) (int, bool) {
+	return mediaLookup(mt, func(mt string) (int, bool) {
+		for i := 0; i < n; i++ {
+			for _, ft := range mediaTypes(factory(i)) {
+				if strings.EqualFold(ft, mt) {
+					return i, true
+				}
+			}
+		}
+
+		return 0, false
+	})
+}
+
+//DecoderAliases wraps decoder factory 'f' such that it also handles the media types 'aliases'
+func DecoderAliases(f DecoderFactory, aliases ...string) DecoderFactory {
+	return &aliasedDecoder{f, aliases}
+}
+
+type aliasedDecoder struct {
+	DecoderFactory
+	aliases []string
+}
+
+func (d *aliasedDecoder) Aliases() []string { return d.aliases }
+
+//EncoderAliases wraps encoder factory 'f' such that it also handles the media types 'aliases', responses
+//are still sent with the media type of 'f'
+func EncoderAliases(f EncoderFactory, aliases ...string) EncoderFactory {
+	return &aliasedEncoder{f, aliases}
+}
+
+type aliasedEncoder struct {
+	EncoderFactory
+	aliases []string
+}
+
+func (e *aliasedEncoder) Aliases() []string { return e.aliases }
+
+func (e *aliasedEncoder) RequestEncoder(w io.Writer, r *http.Request) Encoder {
+	if rencf, ok := e.EncoderFactory.(RequestEncoderFactory); ok {
+		return rencf.RequestEncoder(w, r)
+	}
+
+	return e.Encoder(w)
+}
+
+func (e *aliasedEncoder) ContentType() string {
+	if cter, ok := e.EncoderFactory.(ContentTyper); ok {
+		return cter.ContentType()
+	}
+
+	return e.MimeType() + "; charset=utf-8"
+}
@@ -0,0 +1,92 @@
+package httpio_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+func TestMediaTypeMatching(t *testing.T) {
+	raw := httpio.DecoderAliases(&httpio.OctetStream{}, "image/*")
+	decs := httpio.DecoderList{&httpio.JSON{}, &httpio.XML{}, &httpio.CBOR{}, raw}
+	for _, c := range []struct {
+		MediaType string
+		Exp       httpio.DecoderFactory
+	}{
+		{"application/json", decs[0]},
+		{"Application/JSON", decs[0]},
+		{"text/json", decs[0]},
+		{"application/vnd.acme.user+json", decs[0]},
+		{"application/merge-patch+json", decs[0]},
+		{"application/atom+xml", decs[1]},
+		{"text/xml", decs[1]},
+		{"application/vnd.acme+cbor", decs[2]},
+		{"image/png", raw},
+		{"application/octet-stream", raw},
+		{"application/vnd.acme+yaml", nil},
+		{"text/plain", nil},
+	} {
+		t.Run(c.MediaType, func(t *testing.T) {
+			if got := decs.Find(c.MediaType); got != c.Exp {
+				t.Fatalf("expected %T, got: %T", c.Exp, got)
+			}
+		})
+	}
+
+	all := httpio.DecoderList{&httpio.XML{}, httpio.DecoderAliases(&httpio.JSON{}, "*/*", "text/xml")}
+	if all.Find("text/plain") != all[1] || all.Find("text/xml") != all[1] || all.Find("application/xml") != all[0] {
+		t.Fatal("expected exact matches and aliases to take precedence over the structured suffix and wildcards")
+	}
+}
+
+func TestMediaTypeStacks(t *testing.T) {
+	e := httpio.NewEgress(&httpio.JSON{}, &httpio.XML{}, httpio.EncoderAliases(&httpio.Text{}, "text/*"))
+	i := httpio.NewIngress(e, &httpio.JSON{})
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"a"}`))
+	r.Header.Set("Content-Type", "application/vnd.acme.user+json; charset=utf-8")
+	in := &viewTestInput{}
+	if err := i.Parse(r, in); err != nil || in.Name != "a" {
+		t.Fatalf("expected vendor json to be decoded, got: %+v %v", in, err)
+	}
+
+	for _, c := range []struct {
+		Accept  string
+		Output  interface{}
+		ExpType string
+	}{
+		{"application/vnd.acme.user+json", in, "application/json; charset=utf-8"},
+		{"text/xml", in, "application/xml; charset=utf-8"},
+		{"text/markdown, application/json;q=0.5", "# a", "text/plain; charset=utf-8"},
+		{"APPLICATION/XML", in, "application/xml; charset=utf-8"},
+	} {
+		t.Run(c.Accept, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", c.Accept)
+			w := httptest.NewRecorder()
+			if err := e.Render(c.Output, w, r); err != nil || w.Header().Get("Content-Type") != c.ExpType {
+				t.Fatalf("expected %s, got: %s %v", c.ExpType, w.Header().Get("Content-Type"), err)
+			}
+		})
+	}
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.Write([]byte(`{"name":"b"}`))
+	}))
+	defer svr.Close()
+
+	c, err := httpio.NewClient(svr.Client(), svr.URL, &httpio.JSON{}, &httpio.JSON{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &viewTestInput{}
+	if err = c.Request(context.Background(), http.MethodGet, "/", nil, nil, out); err != nil || out.Name != "b" {
+		t.Fatalf("expected hal+json to be decoded, got: %+v %v", out, err)
+	}
+}
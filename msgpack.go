@@ -0,0 +1,254 @@
+package httpio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+var (
+	//MediaTypeMsgPack identifies MessagePack content
+	MediaTypeMsgPack = "application/msgpack"
+)
+
+//MsgPack allows encoding and decoding into MessagePack. Values are mapped as encoding/json would map them:
+//structs are encoded as maps keyed by the names in their 'json' tags, []byte as binary data and
+//encoding.TextMarshaler implementations (such as time.Time) as strings. Extension types are not supported
+type MsgPack struct{}
+
+//MimeType will report the EncodingMimeType
+func (e *MsgPack) MimeType() string { return MediaTypeMsgPack }
+
+//ContentType is sent without a charset as the format is binary
+func (e *MsgPack) ContentType() string { return MediaTypeMsgPack }
+
+//Encoder will create encoders
+func (e *MsgPack) Encoder(w io.Writer) Encoder {
+	return &binaryEncoder{binaryCodec{"msgpack"}, w, func(buf *bytes.Buffer) binaryWriter { return msgpackWriter{buf} }}
+}
+
+//Decoder will create decoders
+func (e *MsgPack) Decoder(r io.Reader) Decoder {
+	return &binaryDecoder{binaryCodec{"msgpack"}, &msgpackReader{byteReader(r)}}
+}
+
+type msgpackWriter struct{ *bytes.Buffer }
+
+func (w msgpackWriter) writeNil() { w.WriteByte(0xc0) }
+
+func (w msgpackWriter) writeBool(b bool) {
+	if b {
+		w.WriteByte(0xc3)
+	} else {
+		w.WriteByte(0xc2)
+	}
+}
+
+func (w msgpackWriter) writeInt(i int64) {
+	switch {
+	case i >= 0:
+		w.writeUint(uint64(i))
+	case i >= -32:
+		w.WriteByte(byte(int8(i))) //negative fixint
+	case i >= math.MinInt8:
+		w.Write([]byte{0xd0, byte(int8(i))})
+	case i >= math.MinInt16:
+		w.WriteByte(0xd1)
+		w.Write(binary.BigEndian.AppendUint16(nil, uint16(int16(i))))
+	case i >= math.MinInt32:
+		w.WriteByte(0xd2)
+		w.Write(binary.BigEndian.AppendUint32(nil, uint32(int32(i))))
+	default:
+		w.WriteByte(0xd3)
+		w.Write(binary.BigEndian.AppendUint64(nil, uint64(i)))
+	}
+}
+
+func (w msgpackWriter) writeUint(u uint64) {
+	switch {
+	case u < 0x80:
+		w.WriteByte(byte(u)) //positive fixint
+	default:
+		w.writeHead(u, 0xcc, 0xcd, 0xce, 0xcf)
+	}
+}
+
+//writeHead writes a size or value 'n' using the smallest of the 8, 16, 32 or 64 bit forms that start with
+//the given markers, a marker of zero means the format has no such form
+func (w msgpackWriter) writeHead(n uint64, b8, b16, b32, b64 byte) {
+	switch {
+	case n <= math.MaxUint8 && b8 != 0:
+		w.Write([]byte{b8, byte(n)})
+	case n <= math.MaxUint16:
+		w.WriteByte(b16)
+		w.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n <= math.MaxUint32 || b64 == 0:
+		w.WriteByte(b32)
+		w.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		w.WriteByte(b64)
+		w.Write(binary.BigEndian.AppendUint64(nil, n))
+	}
+}
+
+func (w msgpackWriter) writeFloat(f float64, bits int) {
+	if bits == 32 {
+		w.WriteByte(0xca)
+		w.Write(binary.BigEndian.AppendUint32(nil, math.Float32bits(float32(f))))
+		return
+	}
+
+	w.WriteByte(0xcb)
+	w.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(f)))
+}
+
+func (w msgpackWriter) writeString(s string) {
+	if len(s) < 32 {
+		w.WriteByte(0xa0 | byte(len(s))) //fixstr
+	} else {
+		w.writeHead(uint64(len(s)), 0xd9, 0xda, 0xdb, 0)
+	}
+
+	w.WriteString(s)
+}
+
+func (w msgpackWriter) writeBytes(b []byte) {
+	w.writeHead(uint64(len(b)), 0xc4, 0xc5, 0xc6, 0)
+	w.Write(b)
+}
+
+func (w msgpackWriter) writeArray(n int) {
+	if n < 16 {
+		w.WriteByte(0x90 | byte(n)) //fixarray
+		return
+	}
+
+	w.writeHead(uint64(n), 0, 0xdc, 0xdd, 0)
+}
+
+func (w msgpackWriter) writeMap(n int) {
+	if n < 16 {
+		w.WriteByte(0x80 | byte(n)) //fixmap
+		return
+	}
+
+	w.writeHead(uint64(n), 0, 0xde, 0xdf, 0)
+}
+
+type msgpackReader struct {
+	r interface {
+		io.Reader
+		io.ByteReader
+	}
+}
+
+//uint reads a big endian unsigned integer of 'size' bytes
+func (r *msgpackReader) uint(size int) (uint64, error) {
+	var buf [8]byte
+	_, err := io.ReadFull(r.r, buf[8-size:])
+	return binary.BigEndian.Uint64(buf[:]), unexpectedEOF(err)
+}
+
+func (r *msgpackReader) readItem() (it binaryItem, err error) {
+	b, err := r.r.ReadByte()
+	if err != nil {
+		return it, err
+	}
+
+	switch {
+	case b < 0x80:
+		return binaryItem{kind: binaryUint, u: uint64(b)}, nil
+	case b >= 0xe0:
+		return binaryItem{kind: binaryInt, i: int64(int8(b))}, nil
+	case b&0xf0 == 0x80:
+		return binaryItem{kind: binaryMap, n: int(b & 0x0f)}, nil
+	case b&0xf0 == 0x90:
+		return binaryItem{kind: binaryArray, n: int(b & 0x0f)}, nil
+	case b&0xe0 == 0xa0:
+		return r.data(binaryString, uint64(b&0x1f))
+	}
+
+	switch b {
+	case 0xc0:
+		return binaryItem{kind: binaryNil}, nil
+	case 0xc2, 0xc3:
+		return binaryItem{kind: binaryBool, b: b == 0xc3}, nil
+	case 0xc4, 0xc5, 0xc6, 0xd9, 0xda, 0xdb:
+		kind, size := binaryBytes, 1<<(b-0xc4)
+		if b >= 0xd9 {
+			kind, size = binaryString, 1<<(b-0xd9)
+		}
+
+		n, err := r.uint(size)
+		if err != nil {
+			return it, err
+		}
+
+		return r.data(kind, n)
+	case 0xca:
+		u, err := r.uint(4)
+		return binaryItem{kind: binaryFloat, f: float64(math.Float32frombits(uint32(u)))}, err
+	case 0xcb:
+		u, err := r.uint(8)
+		return binaryItem{kind: binaryFloat, f: math.Float64frombits(u)}, err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := r.uint(1 << (b - 0xcc))
+		return binaryItem{kind: binaryUint, u: u}, err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (b - 0xd0)
+		u, err := r.uint(size)
+		i := int64(u<<(64-8*size)) >> (64 - 8*size) //sign extend
+		if i >= 0 {
+			return binaryItem{kind: binaryUint, u: uint64(i)}, err
+		}
+
+		return binaryItem{kind: binaryInt, i: i}, err
+	case 0xdc, 0xdd, 0xde, 0xdf:
+		kind := binaryArray
+		if b >= 0xde {
+			kind = binaryMap
+		}
+
+		n, err := r.uint(2 << ((b - 0xdc) % 2))
+		if err != nil {
+			return it, err
+		}
+
+		if n > math.MaxInt32 {
+			return it, errMsgPack("%s of %d items is too large", binaryKindNames[kind], n)
+		}
+
+		return binaryItem{kind: kind, n: int(n)}, nil
+	case 0xc7, 0xc8, 0xc9, 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return it, r.ext(b)
+	}
+
+	return it, errMsgPack("invalid format byte 0x%02x", b)
+}
+
+func (r *msgpackReader) data(kind binaryKind, n uint64) (binaryItem, error) {
+	data, err := readBinaryData(r.r, n)
+	return binaryItem{kind: kind, data: data}, err
+}
+
+//ext reports the type of an extension, the fixext and ext formats start with the size of the data
+//followed by the type
+func (r *msgpackReader) ext(b byte) error {
+	if b <= 0xc9 {
+		if _, err := r.uint(1 << (b - 0xc7)); err != nil {
+			return err
+		}
+	}
+
+	typ, err := r.r.ReadByte()
+	if err != nil {
+		return unexpectedEOF(err)
+	}
+
+	return errMsgPack("unsupported extension type %d", int8(typ))
+}
+
+func errMsgPack(format string, args ...interface{}) error {
+	return binaryCodec{"msgpack"}.errorf(format, args...)
+}
@@ -0,0 +1,82 @@
+package httpio_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+func TestMsgPack(t *testing.T) {
+	for _, c := range []struct {
+		Value  interface{}
+		Hex    string
+		Decode bool //only decode, the value is not encoded into the same bytes
+	}{
+		{Value: nil, Hex: "c0"},
+		{Value: false, Hex: "c2"},
+		{Value: true, Hex: "c3"},
+		{Value: int64(0), Hex: "00"},
+		{Value: int64(127), Hex: "7f"},
+		{Value: int64(128), Hex: "cc80"},
+		{Value: int64(256), Hex: "cd0100"},
+		{Value: int64(65536), Hex: "ce00010000"},
+		{Value: int64(1 << 32), Hex: "cf0000000100000000"},
+		{Value: uint64(math.MaxUint64), Hex: "cfffffffffffffffff"},
+		{Value: int64(-1), Hex: "ff"},
+		{Value: int64(-32), Hex: "e0"},
+		{Value: int64(-33), Hex: "d0df"},
+		{Value: int64(-129), Hex: "d1ff7f"},
+		{Value: int64(-32769), Hex: "d2ffff7fff"},
+		{Value: int64(math.MinInt64), Hex: "d38000000000000000"},
+		{Value: int64(1), Hex: "d001", Decode: true},
+		{Value: int64(-2), Hex: "d3fffffffffffffffe", Decode: true},
+		{Value: 1.5, Hex: "cb3ff8000000000000"},
+		{Value: 1.5, Hex: "ca3fc00000", Decode: true},
+		{Value: "", Hex: "a0"},
+		{Value: "abc", Hex: "a3616263"},
+		{Value: strings.Repeat("a", 32), Hex: "d920" + strings.Repeat("61", 32)},
+		{Value: "a", Hex: "da000161", Decode: true},
+		{Value: []byte{1, 2}, Hex: "c4020102"},
+		{Value: []interface{}{int64(1), "a"}, Hex: "9201a161"},
+		{Value: make([]interface{}, 16), Hex: "dc0010" + strings.Repeat("c0", 16)},
+		{Value: map[string]interface{}{"a": int64(1), "b": []interface{}{}}, Hex: "82a16101a16290"},
+		{Value: map[interface{}]interface{}{int64(1): "a"}, Hex: "8101a161"},
+	} {
+		t.Run(c.Hex, func(t *testing.T) {
+			data, _ := hex.DecodeString(c.Hex)
+			if !c.Decode {
+				buf := bytes.NewBuffer(nil)
+				err := (&httpio.MsgPack{}).Encoder(buf).Encode(c.Value)
+				if err != nil || hex.EncodeToString(buf.Bytes()) != c.Hex {
+					t.Fatalf("expected %s, got: %x %v", c.Hex, buf.Bytes(), err)
+				}
+			}
+
+			var v interface{}
+			err := (&httpio.MsgPack{}).Decoder(bytes.NewReader(data)).Decode(&v)
+			if exp := c.Value; err != nil || !reflect.DeepEqual(v, exp) {
+				if f, ok := exp.(float64); !ok || v != f {
+					t.Fatalf("expected %#v, got: %#v %v", exp, v, err)
+				}
+			}
+		})
+	}
+
+	for hexIn, exp := range map[string]string{
+		"c1":         "httpio/msgpack: invalid format byte 0xc1",
+		"d6ff000000": "httpio/msgpack: unsupported extension type -1",
+		"c70105ab":   "httpio/msgpack: unsupported extension type 5",
+	} {
+		data, _ := hex.DecodeString(hexIn)
+		var v interface{}
+		err := (&httpio.MsgPack{}).Decoder(bytes.NewReader(data)).Decode(&v)
+		if err == nil || err.Error() != exp {
+			t.Fatalf("expected error '%s' for %s, got: %v", exp, hexIn, err)
+		}
+	}
+}
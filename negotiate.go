@@ -20,8 +20,9 @@ import (
 // Accept header. If two offers match with equal weight, then the more specific
 // offer is preferred.  For example, text/* trumps */*. If two offers match
 // with equal weight and specificity, then the offer earlier in the list is
-// preferred. If no offers match, then defaultOffer is returned.
-func negotiateContentType(hdr http.Header, offers []string, defaultOffer string) string {
+// preferred. If no offers match, then defaultOffer is returned. Media types
+// that 'resolve' maps onto an offer, e.g. aliases, match that offer exactly.
+func negotiateContentType(hdr http.Header, offers []string, defaultOffer string, resolve func(string) string) string {
 	bestOffer := defaultOffer
 	bestQ := -1.0
 	bestWild := 3
@@ -47,7 +48,7 @@ func negotiateContentType(hdr http.Header, offers []string, defaultOffer string)
 					bestOffer = offer
 				}
 			default:
-				if spec.Value == offer &&
+				if (strings.EqualFold(spec.Value, offer) || resolve(spec.Value) == offer) &&
 					(spec.Q > bestQ || bestWild > 0) {
 					bestQ = spec.Q
 					bestWild = 0
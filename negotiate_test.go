@@ -44,7 +44,7 @@ var negotiateContentTypeTests = []struct {
 
 func TestNegotiateContentType(t *testing.T) {
 	for _, tt := range negotiateContentTypeTests {
-		actual := negotiateContentType(http.Header{"Accept": {tt.s}}, tt.offers, tt.defaultOffer)
+		actual := negotiateContentType(http.Header{"Accept": {tt.s}}, tt.offers, tt.defaultOffer, func(string) string { return "" })
 		if actual != tt.expect {
 			t.Errorf("NegotiateContentType(%q, %#v, %q)=%q, want %q", tt.s, tt.offers, tt.defaultOffer, actual, tt.expect)
 		}
@@ -0,0 +1,267 @@
+//Package openapi describes the routes of a httpio.Routes registry as an OpenAPI 3.1 document
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	httpio "github.com/advanderveer/go-httpio"
+	"github.com/advanderveer/go-httpio/jsonschema"
+)
+
+//Version of the OpenAPI specification the documents conform to
+const Version = "3.1.0"
+
+//Document is the root of an OpenAPI document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components *Components          `json:"components,omitempty"`
+}
+
+//Info provides metadata about the api
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+//Server is a base url at which the api is served
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+//PathItem holds the operations on a single path, keyed by lowercase method
+type PathItem map[string]*Operation
+
+//Operation describes a single api operation on a path
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+//Parameter describes a path, query or header parameter of an operation
+type Parameter struct {
+	Name        string             `json:"name"`
+	In          string             `json:"in"`
+	Description string             `json:"description,omitempty"`
+	Required    bool               `json:"required,omitempty"`
+	Schema      *jsonschema.Schema `json:"schema,omitempty"`
+}
+
+//RequestBody describes the body of a request per media type
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+//Response describes a single response of an operation
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+//MediaType provides the schema of content encoded with a certain media type
+type MediaType struct {
+	Schema *jsonschema.Schema `json:"schema,omitempty"`
+}
+
+//Components holds the schemas that are referred to from elsewhere in the document
+type Components struct {
+	Schemas map[string]*jsonschema.Schema `json:"schemas,omitempty"`
+}
+
+//SchemaRefPrefix is the prefix of references to the component schemas
+const SchemaRefPrefix = "#/components/schemas/"
+
+var (
+	wildcard = regexp.MustCompile(`\{([^}.]*)(\.\.\.)?\}`)
+	nonWord  = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+//bodyMethods are the methods for which the input is documented as a request body
+var bodyMethods = map[string]bool{http.MethodPost: true, http.MethodPut: true, http.MethodPatch: true}
+
+//New generates a document for all routes in 'rs'. Schemas are derived from the input and output types
+//using jsonschema, fields that are bound from the path, query or headers are described as parameters.
+//Request and response content is listed for each media type of the ingress and egress stacks. It fails if
+//the types have invalid 'jsonschema' struct tags.
+func New(rs *httpio.Routes, info Info) (*Document, error) {
+	doc := &Document{OpenAPI: Version, Info: info, Paths: map[string]*PathItem{}}
+	g := jsonschema.NewGenerator(SchemaRefPrefix)
+	g.SkipField = httpio.IsParamField
+
+	for _, rt := range rs.Routes() {
+		path := routePath(rt.Pattern)
+		op := &Operation{
+			OperationID: rt.OperationID,
+			Summary:     rt.Summary,
+			Description: rt.Description,
+			Tags:        rt.Tags,
+			Deprecated:  rt.Deprecated,
+			Parameters:  parameters(g, rt.Input),
+			Responses:   map[string]*Response{},
+		}
+
+		if op.OperationID == "" {
+			op.OperationID = operationID(rt.Method, path)
+		}
+
+		if bodyMethods[rt.Method] && hasBody(rt.Input) {
+			op.RequestBody = &RequestBody{Content: content(g, rt.Input, rt.Ingress().Decoders().Supported())}
+		}
+
+		encoders := rt.Ingress().Egress().Encoders().Supported()
+		code := rt.Code
+		if code == 0 {
+			code = http.StatusOK
+		}
+
+		op.Responses[strconv.Itoa(code)] = &Response{Description: http.StatusText(code)}
+		if code != http.StatusNoContent {
+			op.Responses[strconv.Itoa(code)].Content = content(g, rt.Output, encoders)
+		}
+
+		for code, t := range rs.Errors(rt) {
+			op.Responses[strconv.Itoa(code)] = &Response{Description: http.StatusText(code), Content: content(g, t, encoders)}
+		}
+
+		item := doc.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+
+		(*item)[strings.ToLower(rt.Method)] = op
+	}
+
+	if g.Err() != nil {
+		return nil, g.Err()
+	}
+
+	if len(g.Defs) > 0 {
+		doc.Components = &Components{Schemas: g.Defs}
+	}
+
+	return doc, nil
+}
+
+//Handler serves the document as JSON
+func Handler(doc *Document) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err := enc.Encode(doc)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+//routePath turns a http.ServeMux pattern into an OpenAPI path by dropping the host, the end anchor and
+//the dots of wildcards that match the remainder
+func routePath(pattern string) string {
+	if i := strings.IndexByte(pattern, '/'); i > 0 {
+		pattern = pattern[i:]
+	}
+
+	pattern = strings.TrimSuffix(pattern, "{$}")
+	return wildcard.ReplaceAllString(pattern, "{$1}")
+}
+
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, w := range nonWord.Split(path, -1) {
+		if w != "" {
+			id += strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+
+	return id
+}
+
+func structType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return t
+}
+
+//hasBody reports whether the input has fields that are decoded from the request body
+func hasBody(t reflect.Type) bool {
+	st := structType(t)
+	if st == nil {
+		return t != nil
+	}
+
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		if (f.IsExported() || f.Anonymous) && f.Tag.Get("json") != "-" && !httpio.IsParamField(f) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parameters(g *jsonschema.Generator, t reflect.Type) (params []*Parameter) {
+	st := structType(t)
+	if st == nil {
+		return nil
+	}
+
+	for i := 0; i < st.NumField(); i++ {
+		f := st.Field(i)
+		for _, in := range httpio.ParamTags {
+			name := f.Tag.Get(in)
+			if name == "" {
+				continue
+			}
+
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem() //absent parameters are not encoded as null
+			}
+
+			params = append(params, &Parameter{
+				Name:        name,
+				In:          in,
+				Description: f.Tag.Get("doc"),
+				Required:    in == "path" || strings.Contains(","+f.Tag.Get("jsonschema")+",", ",required,"),
+				Schema:      g.Generate(ft),
+			})
+		}
+	}
+
+	return params
+}
+
+func content(g *jsonschema.Generator, t reflect.Type, mediaTypes []string) map[string]*MediaType {
+	s := g.Generate(t)
+	c := map[string]*MediaType{}
+	for _, mt := range mediaTypes {
+		c[mt] = &MediaType{Schema: s}
+	}
+
+	return c
+}
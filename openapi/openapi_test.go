@@ -0,0 +1,172 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+	"github.com/advanderveer/go-httpio/jsonschema"
+	"github.com/advanderveer/go-httpio/openapi"
+)
+
+type item struct {
+	ID   string `json:"id" jsonschema:"readOnly"`
+	Name string `json:"name" doc:"display name" jsonschema:"minLength=1"`
+}
+
+type createItem struct {
+	Tenant string `header:"X-Tenant" jsonschema:"required"`
+	item
+}
+
+type getItem struct {
+	ID     string   `path:"id"`
+	Fields []string `query:"fields"`
+}
+
+type problem struct {
+	Title string `json:"title"`
+}
+
+var problemWare = func(next httpio.Transformer) httpio.Transformer {
+	return httpio.TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+		if err, ok := a.(error); ok {
+			status := http.StatusInternalServerError
+			if httpio.IsDecodeErr(err) {
+				status = http.StatusBadRequest
+			}
+
+			return next.Transform(problem{err.Error()}, r.WithContext(httpio.WithStatus(r.Context(), status)), w)
+		}
+
+		return next.Transform(a, r, w)
+	})
+}
+
+func newRoutes() *httpio.Routes {
+	j := &httpio.JSON{}
+	e := httpio.NewEgress(j)
+	e.Use(problemWare)
+	rs := httpio.NewRoutes(httpio.NewIngress(e, j))
+	rs.Error(http.StatusInternalServerError, problem{})
+
+	httpio.Handle(rs, http.MethodPost, "/items", func(ctx context.Context, in *createItem) (*item, error) {
+		return &in.item, nil
+	}).Returns(http.StatusCreated).Describe("Create an item", "").Tag("items").Error(http.StatusConflict, problem{})
+	httpio.Handle(rs, http.MethodGet, "/items/{id}", func(ctx context.Context, in *getItem) (*item, error) {
+		return &item{ID: in.ID}, nil
+	})
+	httpio.Handle(rs, http.MethodDelete, "/items/{id}/{$}", func(ctx context.Context, in *getItem) (*struct{}, error) {
+		return nil, nil
+	}).Returns(http.StatusNoContent).Operation("removeItem")
+	httpio.Handle(rs, http.MethodGet, "/files/{path...}", func(ctx context.Context, in *struct {
+		Path string `path:"path"`
+	}) (*[]byte, error) {
+		return nil, nil
+	})
+
+	return rs
+}
+
+func TestNew(t *testing.T) {
+	doc, err := openapi.New(newRoutes(), openapi.Info{Title: "Items", Version: "1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := doc.Validate(); err != nil {
+		t.Fatalf("expected valid document, got: %v", err)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `{"openapi":"3.1.0","info":{"title":"Items","version":"1.0.0"},"paths":{` +
+		`"/files/{path}":{"get":{"operationId":"getFilesPath","parameters":[{"name":"path","in":"path","required":true,"schema":{"type":"string"}}],"responses":{` +
+		`"200":{"description":"OK","content":{"application/json":{"schema":{"type":"string","contentEncoding":"base64"}}}},` +
+		`"500":{"description":"Internal Server Error","content":{"application/json":{"schema":{"$ref":"#/components/schemas/problem"}}}}}}},` +
+		`"/items":{"post":{"operationId":"postItems","summary":"Create an item","tags":["items"],` +
+		`"parameters":[{"name":"X-Tenant","in":"header","required":true,"schema":{"type":"string"}}],` +
+		`"requestBody":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/createItem"}}}},"responses":{` +
+		`"201":{"description":"Created","content":{"application/json":{"schema":{"$ref":"#/components/schemas/item"}}}},` +
+		`"409":{"description":"Conflict","content":{"application/json":{"schema":{"$ref":"#/components/schemas/problem"}}}},` +
+		`"500":{"description":"Internal Server Error","content":{"application/json":{"schema":{"$ref":"#/components/schemas/problem"}}}}}}},` +
+		`"/items/{id}":{"get":{"operationId":"getItemsId","parameters":[{"name":"id","in":"path","required":true,"schema":{"type":"string"}},{"name":"fields","in":"query","schema":{"type":["array","null"],"items":{"type":"string"}}}],"responses":{` +
+		`"200":{"description":"OK","content":{"application/json":{"schema":{"$ref":"#/components/schemas/item"}}}},` +
+		`"500":{"description":"Internal Server Error","content":{"application/json":{"schema":{"$ref":"#/components/schemas/problem"}}}}}}},` +
+		`"/items/{id}/":{"delete":{"operationId":"removeItem","parameters":[{"name":"id","in":"path","required":true,"schema":{"type":"string"}},{"name":"fields","in":"query","schema":{"type":["array","null"],"items":{"type":"string"}}}],"responses":{` +
+		`"204":{"description":"No Content"},` +
+		`"500":{"description":"Internal Server Error","content":{"application/json":{"schema":{"$ref":"#/components/schemas/problem"}}}}}}}},` +
+		`"components":{"schemas":{` +
+		`"createItem":{"type":"object","properties":{"id":{"readOnly":true,"type":"string"},"name":{"description":"display name","type":"string","minLength":1}},"required":["id","name"]},` +
+		`"item":{"type":"object","properties":{"id":{"readOnly":true,"type":"string"},"name":{"description":"display name","type":"string","minLength":1}},"required":["id","name"]},` +
+		`"problem":{"type":"object","properties":{"title":{"type":"string"}},"required":["title"]}}}}`
+	if string(data) != exp {
+		t.Fatalf("expected document:\n%s\ngot:\n%s", exp, data)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	doc := &openapi.Document{
+		OpenAPI: "3.0.0",
+		Paths: map[string]*openapi.PathItem{
+			"items/{id}": {
+				"get": {OperationID: "a", Responses: map[string]*openapi.Response{"200": {
+					Content: map[string]*openapi.MediaType{"application/json": {Schema: &jsonschema.Schema{Ref: "#/components/schemas/missing"}}},
+				}}},
+				"fetch": {OperationID: "a", Parameters: []*openapi.Parameter{{Name: "other", In: "path"}}, Responses: map[string]*openapi.Response{"2000": {Description: "x"}}},
+			},
+		},
+	}
+
+	err := doc.Validate()
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+
+	for _, exp := range []string{
+		"unsupported version '3.0.0'",
+		"info requires a title and version",
+		"path 'items/{id}' must start with a slash",
+		"fetch items/{id}: invalid method",
+		"get items/{id}: operationId 'a' is also used by fetch items/{id}",
+		"fetch items/{id}: path parameter 'other' must be required",
+		"fetch items/{id}: path parameter 'other' does not appear in the path",
+		"fetch items/{id}: path parameter 'id' is not declared",
+		"fetch items/{id}: invalid response status '2000'",
+		"get items/{id}: response 200 requires a description",
+		"get items/{id}: unresolvable reference '#/components/schemas/missing'",
+	} {
+		if !strings.Contains(err.Error(), exp) {
+			t.Errorf("expected error to contain %q, got:\n%v", exp, err)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	doc, err := openapi.New(newRoutes(), openapi.Info{Title: "Items", Version: "1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	openapi.Handler(doc).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Fatalf("unexpected content type: %s", w.Header().Get("Content-Type"))
+	}
+
+	var served openapi.Document
+	if err := json.Unmarshal(w.Body.Bytes(), &served); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := served.Validate(); err != nil || len(served.Paths) != 4 {
+		t.Fatalf("expected served document to be valid, got: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/advanderveer/go-httpio/jsonschema"
+)
+
+var (
+	templateParam = regexp.MustCompile(`\{([^}]*)\}`)
+	statusCode    = regexp.MustCompile(`^([1-5][0-9X]{2}|default)$`)
+	methods       = map[string]bool{"get": true, "put": true, "post": true, "delete": true, "options": true, "head": true, "patch": true, "trace": true}
+	paramIns      = map[string]bool{"path": true, "query": true, "header": true, "cookie": true}
+)
+
+//Validate checks the document for the structural mistakes that tooling commonly trips over: missing
+//required fields, undeclared or unused path parameters, duplicate operation ids and references to
+//schemas that do not exist. All problems are returned together.
+func (d *Document) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) { errs = append(errs, fmt.Errorf(format, args...)) }
+
+	if !strings.HasPrefix(d.OpenAPI, "3.1.") {
+		fail("openapi: unsupported version '%s'", d.OpenAPI)
+	}
+
+	if d.Info.Title == "" || d.Info.Version == "" {
+		fail("openapi: info requires a title and version")
+	}
+
+	var schemas map[string]*jsonschema.Schema
+	if d.Components != nil {
+		schemas = d.Components.Schemas
+		for _, name := range sortedKeys(schemas) {
+			checkRefs(schemas[name], schemas, "#/components/schemas/"+name, fail)
+		}
+	}
+
+	ids := map[string]string{}
+	for _, path := range sortedKeys(d.Paths) {
+		if !strings.HasPrefix(path, "/") {
+			fail("openapi: path '%s' must start with a slash", path)
+		}
+
+		var tmpl []string
+		for _, m := range templateParam.FindAllStringSubmatch(path, -1) {
+			tmpl = append(tmpl, m[1])
+		}
+
+		item := *d.Paths[path]
+		for _, method := range sortedKeys(item) {
+			op, at := item[method], method+" "+path
+			if !methods[method] {
+				fail("openapi: %s: invalid method", at)
+			}
+
+			if op.OperationID != "" {
+				if other, ok := ids[op.OperationID]; ok {
+					fail("openapi: %s: operationId '%s' is also used by %s", at, op.OperationID, other)
+				}
+
+				ids[op.OperationID] = at
+			}
+
+			declared := map[string]bool{}
+			for _, p := range op.Parameters {
+				if !paramIns[p.In] {
+					fail("openapi: %s: parameter '%s' has invalid location '%s'", at, p.Name, p.In)
+				}
+
+				if declared[p.In+":"+p.Name] {
+					fail("openapi: %s: parameter '%s' in %s is declared twice", at, p.Name, p.In)
+				}
+
+				declared[p.In+":"+p.Name] = true
+				if p.In == "path" && !p.Required {
+					fail("openapi: %s: path parameter '%s' must be required", at, p.Name)
+				}
+
+				if p.In == "path" && !contains(tmpl, p.Name) {
+					fail("openapi: %s: path parameter '%s' does not appear in the path", at, p.Name)
+				}
+
+				checkRefs(p.Schema, schemas, at, fail)
+			}
+
+			for _, name := range tmpl {
+				if !declared["path:"+name] {
+					fail("openapi: %s: path parameter '%s' is not declared", at, name)
+				}
+			}
+
+			if op.RequestBody != nil {
+				for _, mt := range sortedKeys(op.RequestBody.Content) {
+					checkRefs(op.RequestBody.Content[mt].Schema, schemas, at, fail)
+				}
+			}
+
+			if len(op.Responses) < 1 {
+				fail("openapi: %s: operation has no responses", at)
+			}
+
+			for _, code := range sortedKeys(op.Responses) {
+				resp := op.Responses[code]
+				if !statusCode.MatchString(code) {
+					fail("openapi: %s: invalid response status '%s'", at, code)
+				}
+
+				if resp.Description == "" {
+					fail("openapi: %s: response %s requires a description", at, code)
+				}
+
+				for _, mt := range sortedKeys(resp.Content) {
+					checkRefs(resp.Content[mt].Schema, schemas, at, fail)
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+//checkRefs reports references in 's' that do not resolve to a component schema
+func checkRefs(s *jsonschema.Schema, schemas map[string]*jsonschema.Schema, at string, fail func(string, ...interface{})) {
+	if s == nil {
+		return
+	}
+
+	if s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, SchemaRefPrefix)
+		if !ok || schemas[name] == nil {
+			fail("openapi: %s: unresolvable reference '%s'", at, s.Ref)
+		}
+	}
+
+	for _, sub := range s.Subschemas() {
+		checkRefs(sub, schemas, at, fail)
+	}
+}
+
+func contains(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
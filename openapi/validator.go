@@ -0,0 +1,91 @@
+package openapi
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	httpio "github.com/advanderveer/go-httpio"
+	"github.com/advanderveer/go-httpio/jsonschema"
+)
+
+//RequestValidator returns a validator for request bodies of the operation with 'method' on 'path' that
+//are encoded with media type 'mt'. References are resolved against the document's components. It
+//returns nil if the operation does not describe such a body.
+func (d *Document) RequestValidator(method, path, mt string) (*jsonschema.Validator, error) {
+	method = strings.ToLower(method)
+	op := d.operation(method, path)
+	if op == nil || op.RequestBody == nil || op.RequestBody.Content[mt] == nil {
+		return nil, nil
+	}
+
+	esc := strings.NewReplacer("~", "~0", "/", "~1")
+	v, err := jsonschema.Compile(d, fmt.Sprintf("/paths/%s/%s/requestBody/content/%s/schema",
+		esc.Replace(path), method, esc.Replace(mt)))
+	if err != nil {
+		return nil, err
+	}
+
+	v.Request = true
+	return v, nil
+}
+
+//ValidateRequests returns an Ingress transware that validates request bodies against the schemas in the
+//document before they are decoded, it must be installed using UseRaw. The operation is looked up using
+//the pattern that routed the request, see Request.Pattern. Violations are returned as decode errors
+//that wrap a *jsonschema.ValidationError.
+func ValidateRequests(d *Document) httpio.Transware {
+	var validators sync.Map //validators of the media types that operations declare, by operation
+	return func(next httpio.Transformer) httpio.Transformer {
+		return httpio.TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+			method, pattern, _ := strings.Cut(r.Pattern, " ")
+			if pattern == "" {
+				method = r.Method //pattern without method
+				pattern = r.Pattern
+			}
+
+			key := method + " " + pattern
+			cached, ok := validators.Load(key)
+			if !ok {
+				op := d.operation(method, routePath(pattern))
+				if op == nil {
+					return next.Transform(a, r, w) //not cached, the method may be anything
+				}
+
+				vs := map[string]*jsonschema.Validator{}
+				if op.RequestBody != nil {
+					for mt := range op.RequestBody.Content {
+						v, err := d.RequestValidator(method, routePath(pattern), mt)
+						if err != nil {
+							return err
+						}
+
+						vs[mt] = v
+					}
+				}
+
+				cached, _ = validators.LoadOrStore(key, vs)
+			}
+
+			mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			v := cached.(map[string]*jsonschema.Validator)[mt]
+			if v == nil {
+				return next.Transform(a, r, w)
+			}
+
+			return httpio.ValidateSchema(v)(next).Transform(a, r, w)
+		})
+	}
+}
+
+//operation returns the operation with 'method' on 'path', or nil if the document has none
+func (d *Document) operation(method, path string) *Operation {
+	item := d.Paths[path]
+	if item == nil {
+		return nil
+	}
+
+	return (*item)[strings.ToLower(method)]
+}
@@ -0,0 +1,64 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/advanderveer/go-httpio/openapi"
+)
+
+func TestValidateRequests(t *testing.T) {
+	rs := newRoutes()
+	doc, err := openapi.New(rs, openapi.Info{Title: "Items", Version: "1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs.Ingress().UseRaw(openapi.ValidateRequests(doc))
+
+	for _, c := range []struct {
+		Name      string
+		Method    string
+		Target    string
+		Body      string
+		ExpStatus int
+		ExpBody   string
+	}{
+		{"valid, readOnly id may be absent", http.MethodPost, "/items", `{"name":"foo"}`, http.StatusCreated, `{"id":"","name":"foo"}` + "\n"},
+		{"invalid", http.MethodPost, "/items", `{"name":""}`, http.StatusBadRequest, `{"title":"jsonschema: /name: must be at least 1 characters long"}` + "\n"},
+		{"no body schema", http.MethodGet, "/items/a1", ``, http.StatusOK, `{"id":"a1","name":""}` + "\n"},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			r := httptest.NewRequest(c.Method, c.Target, strings.NewReader(c.Body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			rs.ServeHTTP(w, r)
+			if w.Code != c.ExpStatus || w.Body.String() != c.ExpBody {
+				t.Fatalf("expected %d %q, got: %d %q", c.ExpStatus, c.ExpBody, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequestValidator(t *testing.T) {
+	doc, err := openapi.New(newRoutes(), openapi.Info{Title: "Items", Version: "1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := doc.RequestValidator(http.MethodPost, "/items", "application/json")
+	if err != nil || v == nil {
+		t.Fatalf("expected validator, got: %v %v", v, err)
+	}
+
+	if err = v.ValidateJSON([]byte(`{"name":1}`)); err == nil || err.Error() != "jsonschema: /name: expected string, got integer" {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	v, err = doc.RequestValidator(http.MethodPost, "/items", "text/plain")
+	if err != nil || v != nil {
+		t.Fatalf("expected no validator, got: %v %v", v, err)
+	}
+}
@@ -0,0 +1,124 @@
+package httpio
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+
+	"github.com/advanderveer/go-httpio/header"
+)
+
+//CursorFunc extracts the items from a decoded page and returns the path of the next page to request,
+//an empty path ends the pagination
+type CursorFunc[P, T any] func(page *P, meta *ResponseMeta) (items []T, next string)
+
+//LinkCursor is the CursorFunc for pages that are encoded as a plain list of items with the location of
+//the next page in a 'Link: <...>; rel="next"' header
+func LinkCursor[T any](page *[]T, meta *ResponseMeta) (items []T, next string) {
+	if u, ok := meta.Link("next"); ok {
+		next = u.String()
+	}
+
+	return *page, next
+}
+
+//Paginate requests path 'p' and follows the 'next' links of each response until there are no more pages.
+//Each page is expected to be encoded as a list of items. Iteration stops at the first error
+func Paginate[T any](ctx context.Context, c *Client, p string, hdr http.Header) iter.Seq2[T, error] {
+	return PaginateCursor(ctx, c, p, hdr, LinkCursor[T])
+}
+
+//PaginateCursor requests path 'p' and decodes each response into a page of type P. The cursor function
+//'fn' provides the items of the page and the path of the next page. Iteration stops at the first error
+//or when a page links to a path that was already requested. The sequence can be iterated more than once
+func PaginateCursor[P, T any](ctx context.Context, c *Client, p string, hdr http.Header, fn CursorFunc[P, T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		seen := map[string]bool{}
+		for next := p; next != "" && !seen[next]; {
+			seen[next] = true
+
+			var page P
+			meta, err := c.Do(ctx, http.MethodGet, next, hdr, nil, &page)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			var items []T
+			items, next = fn(&page, meta)
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+//CursorQuery returns path 'p' with query parameter 'param' set to 'cursor'. It returns an empty path if
+//the cursor is empty such that it can be used to end pagination in a CursorFunc
+func CursorQuery(p, param, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+
+	u, err := url.Parse(p)
+	if err != nil {
+		return ""
+	}
+
+	q := u.Query()
+	q.Set(param, cursor)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+//Page is an output that holds a single page of items. When rendered with the PageLinks transware only
+//the items are encoded while the locations of other pages are written as a Link header
+type Page[T any] struct {
+	Items []T
+	Next  string
+	Prev  string
+	First string
+	Last  string
+}
+
+func (p Page[T]) pageItems() interface{} { return p.Items }
+
+func (p Page[T]) pageLinks() (links []header.Link) {
+	for _, l := range []header.Link{
+		{URL: p.Next, Rel: "next"},
+		{URL: p.Prev, Rel: "prev"},
+		{URL: p.First, Rel: "first"},
+		{URL: p.Last, Rel: "last"},
+	} {
+		if l.URL != "" {
+			links = append(links, l)
+		}
+	}
+
+	return links
+}
+
+//PageLinks is an Egress transware that renders Page outputs as a list of items with Link headers
+func PageLinks(next Transformer) Transformer {
+	return TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+		type pager interface {
+			pageItems() interface{}
+			pageLinks() []header.Link
+		}
+
+		p, ok := a.(pager)
+		if !ok {
+			return next.Transform(a, r, w)
+		}
+
+		if links := p.pageLinks(); len(links) > 0 {
+			w.Header().Add("Link", header.FormatLinks(links...))
+		}
+
+		return next.Transform(p.pageItems(), r, w)
+	})
+}
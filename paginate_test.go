@@ -0,0 +1,140 @@
+package httpio_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+var pagedItems = []string{"a", "b", "c", "d", "e"}
+
+func TestPaginateLinks(t *testing.T) {
+	egress := httpio.NewEgress(&httpio.JSON{})
+	egress.Use(httpio.PageLinks)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page := httpio.Page[string]{Items: pagedItems[n*2 : min(n*2+2, len(pagedItems))]}
+		if n*2+2 < len(pagedItems) {
+			page.Next = fmt.Sprintf("/items?page=%d", n+1)
+		}
+
+		egress.MustRender(page, w, r)
+	}))
+	defer ts.Close()
+
+	client, err := httpio.NewClient(ts.Client(), ts.URL, &httpio.JSON{}, &httpio.JSON{})
+	if err != nil {
+		t.Fatal("failed to create client:", err)
+	}
+
+	var items []string
+	for item, err := range httpio.Paginate[string](context.Background(), client, "/items", nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		items = append(items, item)
+	}
+
+	if !reflect.DeepEqual(items, pagedItems) {
+		t.Fatalf("expected all items, got: %v", items)
+	}
+
+	t.Run("stop early", func(t *testing.T) {
+		items = items[:0]
+		for item := range httpio.Paginate[string](context.Background(), client, "/items", nil) {
+			items = append(items, item)
+			if len(items) == 3 {
+				break
+			}
+		}
+
+		if !reflect.DeepEqual(items, pagedItems[:3]) {
+			t.Fatalf("expected first three items, got: %v", items)
+		}
+	})
+}
+
+type cursorPage struct {
+	Items      []string `json:"items"`
+	NextCursor string   `json:"next_cursor"`
+}
+
+func TestPaginateCursor(t *testing.T) {
+	egress := httpio.NewEgress(&httpio.JSON{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Query().Get("cursor"))
+		if n >= len(pagedItems) {
+			egress.MustRender(fmt.Errorf("invalid cursor"), w, r.WithContext(httpio.WithStatus(r.Context(), http.StatusBadRequest)))
+			return
+		}
+
+		page := &cursorPage{Items: pagedItems[n:min(n+3, len(pagedItems))]}
+		if n+3 < len(pagedItems) {
+			page.NextCursor = strconv.Itoa(n + 3)
+		}
+
+		egress.MustRender(page, w, r)
+	}))
+	defer ts.Close()
+
+	client, err := httpio.NewClient(ts.Client(), ts.URL, &httpio.JSON{}, &httpio.JSON{})
+	if err != nil {
+		t.Fatal("failed to create client:", err)
+	}
+
+	seq := httpio.PaginateCursor(context.Background(), client, "/items", nil,
+		func(p *cursorPage, meta *httpio.ResponseMeta) ([]string, string) {
+			return p.Items, httpio.CursorQuery("/items", "cursor", p.NextCursor)
+		})
+
+	for range 2 { //iterating again starts from the first page
+		var items []string
+		for item, err := range seq {
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			items = append(items, item)
+		}
+
+		if !reflect.DeepEqual(items, pagedItems) {
+			t.Fatalf("expected all items, got: %v", items)
+		}
+	}
+
+	t.Run("repeated cursor ends iteration", func(t *testing.T) {
+		var items []string
+		for item, err := range httpio.PaginateCursor(context.Background(), client, "/items?cursor=3", nil,
+			func(p *cursorPage, meta *httpio.ResponseMeta) ([]string, string) {
+				return p.Items, "/items?cursor=3"
+			}) {
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			items = append(items, item)
+		}
+
+		if !reflect.DeepEqual(items, pagedItems[3:]) {
+			t.Fatalf("expected a single page, got: %v", items)
+		}
+	})
+
+	t.Run("error ends iteration", func(t *testing.T) {
+		var errs []error
+		for _, err := range httpio.Paginate[string](context.Background(), client, "/items?cursor=10", nil) {
+			errs = append(errs, err)
+		}
+
+		if len(errs) != 1 || errs[0] == nil {
+			t.Fatalf("expected a single error, got: %v", errs)
+		}
+	})
+}
@@ -0,0 +1,628 @@
+package httpio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	//MediaTypeJSONPatch identifies JSON Patch documents (RFC 6902)
+	MediaTypeJSONPatch = "application/json-patch+json"
+
+	//MediaTypeMergePatch identifies JSON Merge Patch documents (RFC 7396)
+	MediaTypeMergePatch = "application/merge-patch+json"
+
+	//ErrPatchTestFailed is the cause of a PatchError when a 'test' operation did not match the resource
+	ErrPatchTestFailed = errors.New("httpio/patch: test failed")
+
+	//ErrPatchTooLarge is the cause of a PatchError when the 'copy' operations of a patch copy more than
+	//MaxBufferedBodySize bytes in total, as each can double the size of the resource
+	ErrPatchTooLarge = errors.New("httpio/patch: copies exceed the size limit")
+)
+
+//PatchError is returned when an operation of a JSON Patch cannot be applied. It is rendered with status
+//409 Conflict when the cause is ErrPatchTestFailed, 413 Content Too Large when it is ErrPatchTooLarge and
+//with 422 Unprocessable Entity otherwise
+type PatchError struct {
+	Index int //position of the operation in the patch
+	Op    string
+	Path  string
+	Err   error
+}
+
+//Error describes the operation that could not be applied
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("httpio/patch: cannot apply operation %d ('%s' at '%s'): %v", e.Index, e.Op, e.Path, e.Err)
+}
+
+//Unwrap returns the cause
+func (e *PatchError) Unwrap() error { return e.Err }
+
+//StatusCode implements the StatusCoder
+func (e *PatchError) StatusCode() int {
+	if errors.Is(e.Err, ErrPatchTestFailed) {
+		return http.StatusConflict
+	} else if errors.Is(e.Err, ErrPatchTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+
+	return http.StatusUnprocessableEntity
+}
+
+//PatchLoader loads the current state of the resource that request 'r' patches into 'current', which is
+//the input the request is parsed into. Its parameters are already bound when it is used with Handle
+type PatchLoader func(r *http.Request, current interface{}) error
+
+//ApplyPatch returns an Ingress transware that applies JSON Patch and JSON Merge Patch request bodies to the
+//resource that is loaded by 'load'. The patched resource is then decoded into the input as if it was sent
+//as a JSON body, so the ingress needs a JSON decoder. The input is reset in between: fields that are not
+//part of its JSON representation are lost except for the parameters, which are bound again. Errors of the
+//loader are returned as is, operations that cannot be applied are reported as a decode error that wraps a
+//*PatchError. It must be installed using UseRaw, other bodies are passed on untouched
+func ApplyPatch(load PatchLoader) Transware {
+	return func(next Transformer) Transformer {
+		return TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+			if r.Body == nil || r.ContentLength == 0 {
+				return next.Transform(a, r, w)
+			}
+
+			mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if mt = strings.ToLower(mt); mt != MediaTypeJSONPatch && mt != MediaTypeMergePatch {
+				return next.Transform(a, r, w)
+			}
+
+			data, err := readBody(r)
+			if err != nil {
+				return decodeErr{err}
+			}
+
+			err = load(r, a)
+			if err != nil {
+				return err
+			}
+
+			current, err := json.Marshal(a)
+			if err != nil {
+				return err
+			}
+
+			var patched []byte
+			if mt == MediaTypeJSONPatch {
+				patched, err = applyJSONPatch(current, data)
+			} else {
+				patched, err = applyMergePatch(current, data)
+			}
+
+			if err != nil {
+				return decodeErr{err}
+			}
+
+			if v := reflect.ValueOf(a); v.Kind() == reflect.Ptr && !v.IsNil() {
+				v.Elem().Set(reflect.Zero(v.Elem().Type()))
+			}
+
+			err = bindParams(a, r)
+			if err != nil {
+				return err
+			}
+
+			r = r.WithContext(r.Context())
+			r.Header = r.Header.Clone()
+			r.Header.Set("Content-Type", MediaTypeJSON)
+			r.Body = io.NopCloser(bytes.NewReader(patched))
+			r.ContentLength = int64(len(patched))
+			return next.Transform(a, r, w)
+		})
+	}
+}
+
+//applyMergePatch merges 'patch' into 'doc' as described by RFC 7396
+func applyMergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p interface{}
+	err := unmarshalJSONDoc(doc, &target)
+	if err != nil {
+		return nil, err
+	}
+
+	err = unmarshalJSONDoc(patch, &p)
+	if err != nil {
+		return nil, fmt.Errorf("httpio/patch: invalid merge patch: %w", err)
+	}
+
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+
+		t[k] = mergePatch(t[k], v)
+	}
+
+	return t
+}
+
+//patchOp is an operation as it is decoded, the value is kept raw to tell a null value from a missing one
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+//applyJSONPatch applies the operations of 'patch' to 'doc' in order as described by RFC 6902
+func applyJSONPatch(doc, patch []byte) ([]byte, error) {
+	var target interface{}
+	err := unmarshalJSONDoc(doc, &target)
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []patchOp
+	err = json.Unmarshal(patch, &ops)
+	if err != nil {
+		return nil, fmt.Errorf("httpio/patch: invalid json patch: %w", err)
+	}
+
+	var copied int64
+	for i, op := range ops {
+		target, err = applyPatchOp(target, op, &copied)
+		if err != nil {
+			perr := &PatchError{Index: i, Op: op.Op, Err: err}
+			if op.Path != nil {
+				perr.Path = *op.Path
+			}
+
+			return nil, perr
+		}
+	}
+
+	return json.Marshal(target)
+}
+
+//applyPatchOp applies a single operation, 'copied' accumulates the size of the values that are copied
+func applyPatchOp(doc interface{}, op patchOp, copied *int64) (interface{}, error) {
+	if op.Path == nil {
+		return nil, errors.New("missing 'path'")
+	}
+
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, errors.New("missing 'value'")
+		}
+
+		err = unmarshalJSONDoc(op.Value, &value)
+		if err != nil {
+			return nil, err
+		}
+	case "move", "copy":
+		if op.From == nil {
+			return nil, errors.New("missing 'from'")
+		}
+
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err = pointerGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == "copy" {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+
+			if *copied += int64(len(data)); *copied > MaxBufferedBodySize {
+				return nil, ErrPatchTooLarge
+			}
+
+			value = nil
+			err = unmarshalJSONDoc(data, &value) //deep copy
+			if err != nil {
+				return nil, err
+			}
+
+			break
+		}
+
+		if strings.HasPrefix(*op.Path, *op.From+"/") {
+			return nil, errors.New("cannot move a value into one of its children")
+		}
+
+		doc, err = pointerRemove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+	case "remove":
+	default:
+		return nil, fmt.Errorf("unsupported operation '%s'", op.Op)
+	}
+
+	switch op.Op {
+	case "remove":
+		return pointerRemove(doc, path)
+	case "replace":
+		if _, err = pointerGet(doc, path); err != nil {
+			return nil, err
+		}
+
+		return pointerSet(doc, path, value, false)
+	case "test":
+		current, err := pointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !jsonEqual(current, value) {
+			return nil, ErrPatchTestFailed
+		}
+
+		return doc, nil
+	default:
+		return pointerSet(doc, path, value, true)
+	}
+}
+
+//unmarshalJSONDoc decodes into generic values, numbers are kept as is
+func unmarshalJSONDoc(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+//JSONPointer formats the reference tokens as a JSON Pointer (RFC 6901), escaping them as needed
+func JSONPointer(tokens ...string) string {
+	var b strings.Builder
+	for _, tok := range tokens {
+		b.WriteByte('/')
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(tok))
+	}
+
+	return b.String()
+}
+
+//parsePointer returns the unescaped reference tokens of JSON Pointer 'p'
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+
+	if p[0] != '/' {
+		return nil, fmt.Errorf("invalid pointer '%s'", p)
+	}
+
+	tokens := strings.Split(p[1:], "/")
+	for i, tok := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(tok)
+	}
+
+	return tokens, nil
+}
+
+//arrayIndex parses array index 'tok' of an array of length 'n', '-' refers to the end of the array
+func arrayIndex(tok string, n int, end bool) (int, error) {
+	if tok == "-" && end {
+		return n, nil
+	}
+
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || (len(tok) > 1 && tok[0] == '0') || tok[0] == '+' {
+		return 0, fmt.Errorf("invalid array index '%s'", tok)
+	}
+
+	if i > n || (i == n && !end) {
+		return 0, fmt.Errorf("array index '%s' is out of bounds", tok)
+	}
+
+	return i, nil
+}
+
+func pointerGet(doc interface{}, path []string) (interface{}, error) {
+	for _, tok := range path {
+		switch c := doc.(type) {
+		case map[string]interface{}:
+			v, ok := c[tok]
+			if !ok {
+				return nil, fmt.Errorf("member '%s' does not exist", tok)
+			}
+
+			doc = v
+		case []interface{}:
+			i, err := arrayIndex(tok, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+
+			doc = c[i]
+		default:
+			return nil, fmt.Errorf("cannot reference '%s' in a scalar value", tok)
+		}
+	}
+
+	return doc, nil
+}
+
+//pointerSet sets the value at 'path', as a new array element or member if 'add' is true. It returns the
+//changed document as arrays may have to grow
+func pointerSet(doc interface{}, path []string, value interface{}, add bool) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return pointerAt(doc, path, func(parent interface{}, tok string) (interface{}, error) {
+		switch c := parent.(type) {
+		case map[string]interface{}:
+			c[tok] = value
+			return c, nil
+		case []interface{}:
+			i, err := arrayIndex(tok, len(c), add)
+			if err != nil {
+				return nil, err
+			}
+
+			if !add {
+				c[i] = value
+				return c, nil
+			}
+
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+			return c, nil
+		default:
+			return nil, fmt.Errorf("cannot reference '%s' in a scalar value", tok)
+		}
+	})
+}
+
+//pointerRemove removes the value at 'path' and returns the changed document
+func pointerRemove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot remove the whole document")
+	}
+
+	return pointerAt(doc, path, func(parent interface{}, tok string) (interface{}, error) {
+		switch c := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := c[tok]; !ok {
+				return nil, fmt.Errorf("member '%s' does not exist", tok)
+			}
+
+			delete(c, tok)
+			return c, nil
+		case []interface{}:
+			i, err := arrayIndex(tok, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+
+			return append(c[:i], c[i+1:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot reference '%s' in a scalar value", tok)
+		}
+	})
+}
+
+//pointerAt calls 'fn' with the value that holds the last token of 'path' and stores what it returns in
+//the document
+func pointerAt(doc interface{}, path []string, fn func(parent interface{}, tok string) (interface{}, error)) (interface{}, error) {
+	parent, err := pointerGet(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	parent, err = fn(parent, path[len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(path) == 1 {
+		return parent, nil
+	}
+
+	return pointerSet(doc, path[:len(path)-1], parent, false)
+}
+
+//jsonEqual compares generic JSON values, numbers are equal if their values are
+func jsonEqual(a, b interface{}) bool {
+	switch ca := a.(type) {
+	case map[string]interface{}:
+		cb, ok := b.(map[string]interface{})
+		if !ok || len(ca) != len(cb) {
+			return false
+		}
+
+		for k, v := range ca {
+			if w, ok := cb[k]; !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		cb, ok := b.([]interface{})
+		if !ok || len(ca) != len(cb) {
+			return false
+		}
+
+		for i := range ca {
+			if !jsonEqual(ca[i], cb[i]) {
+				return false
+			}
+		}
+
+		return true
+	case json.Number:
+		cb, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+
+		fa, erra := ca.Float64()
+		fb, errb := cb.Float64()
+		return ca == cb || (erra == nil && errb == nil && fa == fb)
+	default:
+		return a == b
+	}
+}
+
+//PatchDocument is implemented by the patches that the client sends with their own media type rather than
+//encoding them with the default encoder
+type PatchDocument interface {
+	PatchMediaType() string
+}
+
+//PatchOperation is a single operation of a JSON Patch
+type PatchOperation struct {
+	Op    string
+	Path  string
+	From  string      //only used by 'move' and 'copy'
+	Value interface{} //only used by 'add', 'replace' and 'test'
+}
+
+//MarshalJSON only writes the members that the operation uses, such that null values are kept
+func (o PatchOperation) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{"op": o.Op, "path": o.Path}
+	switch o.Op {
+	case "add", "replace", "test":
+		m["value"] = o.Value
+	case "move", "copy":
+		m["from"] = o.From
+	}
+
+	return json.Marshal(m)
+}
+
+//JSONPatch is a JSON Patch document, use its methods to build it, e.g:
+//JSONPatch{}.Test("/version", 3).Replace("/name", "bob")
+type JSONPatch []PatchOperation
+
+//PatchMediaType implements the PatchDocument
+func (p JSONPatch) PatchMediaType() string { return MediaTypeJSONPatch }
+
+//Add appends an operation that adds value 'v' at 'path'
+func (p JSONPatch) Add(path string, v interface{}) JSONPatch {
+	return append(p, PatchOperation{Op: "add", Path: path, Value: v})
+}
+
+//Remove appends an operation that removes the value at 'path'
+func (p JSONPatch) Remove(path string) JSONPatch {
+	return append(p, PatchOperation{Op: "remove", Path: path})
+}
+
+//Replace appends an operation that replaces the value at 'path' with 'v'
+func (p JSONPatch) Replace(path string, v interface{}) JSONPatch {
+	return append(p, PatchOperation{Op: "replace", Path: path, Value: v})
+}
+
+//Move appends an operation that moves the value at 'from' to 'path'
+func (p JSONPatch) Move(from, path string) JSONPatch {
+	return append(p, PatchOperation{Op: "move", Path: path, From: from})
+}
+
+//Copy appends an operation that copies the value at 'from' to 'path'
+func (p JSONPatch) Copy(from, path string) JSONPatch {
+	return append(p, PatchOperation{Op: "copy", Path: path, From: from})
+}
+
+//Test appends an operation that fails the patch unless the value at 'path' equals 'v'
+func (p JSONPatch) Test(path string, v interface{}) JSONPatch {
+	return append(p, PatchOperation{Op: "test", Path: path, Value: v})
+}
+
+//MergePatch is a JSON Merge Patch document, members with a nil value are removed from the resource
+type MergePatch map[string]interface{}
+
+//PatchMediaType implements the PatchDocument
+func (p MergePatch) PatchMediaType() string { return MediaTypeMergePatch }
+
+//NewMergePatch creates the merge patch that turns 'original' into 'modified', both must encode to JSON
+//objects
+func NewMergePatch(original, modified interface{}) (MergePatch, error) {
+	var docs [2]interface{}
+	for i, v := range []interface{}{original, modified} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		err = unmarshalJSONDoc(data, &docs[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	from, ok1 := docs[0].(map[string]interface{})
+	to, ok2 := docs[1].(map[string]interface{})
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("httpio/patch: cannot create a merge patch from %T to %T, expected objects", original, modified)
+	}
+
+	return mergeDiff(from, to), nil
+}
+
+func mergeDiff(from, to map[string]interface{}) MergePatch {
+	p := MergePatch{}
+	for k := range from {
+		if _, ok := to[k]; !ok {
+			p[k] = nil
+		}
+	}
+
+	for k, v := range to {
+		old, ok := from[k]
+		switch {
+		case !ok:
+			p[k] = v
+		case jsonEqual(old, v):
+		default:
+			om, ok1 := old.(map[string]interface{})
+			nm, ok2 := v.(map[string]interface{})
+			if ok1 && ok2 {
+				p[k] = map[string]interface{}(mergeDiff(om, nm))
+			} else {
+				p[k] = v
+			}
+		}
+	}
+
+	return p
+}
+
+//Patch sends 'patch' to path 'p' with the PATCH method and decodes the response into 'out', see Do
+func (c *Client) Patch(ctx context.Context, p string, hdr http.Header, patch PatchDocument, out interface{}) (meta *ResponseMeta, err error) {
+	return c.Do(ctx, http.MethodPatch, p, hdr, patch, out)
+}
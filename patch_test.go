@@ -0,0 +1,166 @@
+package httpio_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+type patchTestUser struct {
+	ID      string   `json:"-" header:"X-Id" path:"id"`
+	Name    string   `json:"name"`
+	Email   string   `json:"email,omitempty"`
+	Tags    []string `json:"tags"`
+	Version int      `json:"version"`
+}
+
+var errPatchTestNotFound = errors.New("not found")
+
+func patchTestLoad(r *http.Request, current interface{}) error {
+	u := current.(*patchTestUser)
+	if u.ID != "1" {
+		return errPatchTestNotFound
+	}
+
+	*u = patchTestUser{ID: u.ID, Name: "alice", Email: "a@example.com", Tags: []string{"a", "b"}, Version: 3}
+	return nil
+}
+
+func TestApplyPatch(t *testing.T) {
+	i := httpio.NewIngress(httpio.NewEgress(&httpio.JSON{}), &httpio.JSON{})
+	i.UseRaw(httpio.ApplyPatch(patchTestLoad))
+
+	for _, c := range []struct {
+		Name      string
+		ID        string
+		Type      string
+		Body      string
+		Exp       patchTestUser
+		ExpErr    error
+		ExpStatus int
+	}{
+		{"json patch", "1", httpio.MediaTypeJSONPatch,
+			`[{"op":"test","path":"/version","value":3.0},{"op":"replace","path":"/name","value":"bob"},{"op":"add","path":"/tags/-","value":"c"},{"op":"remove","path":"/email"}]`,
+			patchTestUser{ID: "1", Name: "bob", Tags: []string{"a", "b", "c"}, Version: 3}, nil, 0},
+		{"move and copy", "1", httpio.MediaTypeJSONPatch,
+			`[{"op":"move","from":"/tags/0","path":"/name"},{"op":"copy","from":"/tags/0","path":"/tags/0"}]`,
+			patchTestUser{ID: "1", Name: "a", Email: "a@example.com", Tags: []string{"b", "b"}, Version: 3}, nil, 0},
+		{"merge patch", "1", httpio.MediaTypeMergePatch, `{"email":null,"tags":["x"],"version":4}`,
+			patchTestUser{ID: "1", Name: "alice", Tags: []string{"x"}, Version: 4}, nil, 0},
+		{"plain json", "1", httpio.MediaTypeJSON, `{"name":"carol"}`, patchTestUser{ID: "1", Name: "carol"}, nil, 0},
+		{"failed test", "1", httpio.MediaTypeJSONPatch, `[{"op":"test","path":"/version","value":2}]`,
+			patchTestUser{}, httpio.ErrPatchTestFailed, http.StatusConflict},
+		{"missing member", "1", httpio.MediaTypeJSONPatch, `[{"op":"replace","path":"/nickname","value":"x"}]`,
+			patchTestUser{}, nil, http.StatusUnprocessableEntity},
+		{"out of bounds", "1", httpio.MediaTypeJSONPatch, `[{"op":"add","path":"/tags/3","value":"x"}]`,
+			patchTestUser{}, nil, http.StatusUnprocessableEntity},
+		{"unsupported op", "1", httpio.MediaTypeJSONPatch, `[{"op":"merge","path":"/name"}]`,
+			patchTestUser{}, nil, http.StatusUnprocessableEntity},
+		{"copies too large", "1", httpio.MediaTypeJSONPatch, `[` + strings.Repeat(`{"op":"copy","from":"","path":"/tags/-"},`, 40) + `{"op":"remove","path":"/name"}]`,
+			patchTestUser{}, httpio.ErrPatchTooLarge, http.StatusRequestEntityTooLarge},
+		{"not found", "2", httpio.MediaTypeMergePatch, `{"name":"bob"}`, patchTestUser{}, errPatchTestNotFound, http.StatusOK},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(c.Body))
+			r.Header.Set("Content-Type", c.Type)
+			r.Header.Set("X-Id", c.ID)
+			u := &patchTestUser{ID: c.ID}
+			err := i.Parse(r, u)
+			if c.ExpStatus == 0 {
+				if err != nil || !reflect.DeepEqual(*u, c.Exp) {
+					t.Fatalf("expected %+v, got: %+v %v", c.Exp, *u, err)
+				}
+
+				return
+			}
+
+			var perr *httpio.PatchError
+			if err == nil || (c.ExpErr != nil && !errors.Is(err, c.ExpErr)) || (c.ExpErr == nil && !errors.As(err, &perr)) {
+				t.Fatalf("expected error %v, got: %v", c.ExpErr, err)
+			}
+
+			w := httptest.NewRecorder()
+			i.Egress().MustRender(err, w, r)
+			if w.Code != c.ExpStatus {
+				t.Fatalf("expected status %d, got: %d", c.ExpStatus, w.Code)
+			}
+		})
+	}
+	t.Run("body too large", func(t *testing.T) {
+		defer func(n int64) { httpio.MaxBufferedBodySize = n }(httpio.MaxBufferedBodySize)
+		httpio.MaxBufferedBodySize = 8
+
+		r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"name":"bob"}`))
+		r.Header.Set("Content-Type", httpio.MediaTypeMergePatch)
+		r.Header.Set("X-Id", "1")
+		err := i.Parse(r, &patchTestUser{ID: "1"})
+		if merr := (*http.MaxBytesError)(nil); !errors.As(err, &merr) || !httpio.IsDecodeErr(err) {
+			t.Fatalf("expected a max bytes decode error, got: %v", err)
+		}
+	})
+}
+
+func TestPatchDocuments(t *testing.T) {
+	if p := httpio.JSONPointer("a/b", "m~n"); p != "/a~1b/m~0n" {
+		t.Fatalf("unexpected pointer: %s", p)
+	}
+
+	data, _ := json.Marshal(httpio.JSONPatch{}.Add("/a", nil).Remove("/b").Move("/c", "/d"))
+	if exp := `[{"op":"add","path":"/a","value":null},{"op":"remove","path":"/b"},{"from":"/c","op":"move","path":"/d"}]`; string(data) != exp {
+		t.Fatalf("expected %s, got: %s", exp, data)
+	}
+
+	orig := map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 1, "d": 2}, "e": "x"}
+	mod := map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2, "d": 2}, "f": []int{1}}
+	p, err := httpio.NewMergePatch(orig, mod)
+	data, _ = json.Marshal(p)
+	if exp := `{"b":{"c":2},"e":null,"f":[1]}`; err != nil || string(data) != exp {
+		t.Fatalf("expected %s, got: %s %v", exp, data, err)
+	}
+
+	_, err = httpio.NewMergePatch([]int{}, mod)
+	if exp := "httpio/patch: cannot create a merge patch from []int to map[string]interface {}, expected objects"; err == nil || err.Error() != exp {
+		t.Fatalf("expected error '%s', got: %v", exp, err)
+	}
+}
+
+func TestClientPatch(t *testing.T) {
+	rs := httpio.NewRoutes(httpio.NewIngress(httpio.NewEgress(&httpio.JSON{}), &httpio.JSON{}))
+	httpio.Handle(rs, http.MethodPatch, "/users/{id}", func(ctx context.Context, in *patchTestUser) (*patchTestUser, error) {
+		return in, nil
+	}).With(httpio.RouteUseRaw(httpio.ApplyPatch(patchTestLoad)))
+
+	svr := httptest.NewServer(rs)
+	defer svr.Close()
+
+	c, err := httpio.NewClient(svr.Client(), svr.URL, &httpio.JSON{}, &httpio.JSON{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &patchTestUser{}
+	_, err = c.Patch(context.Background(), "/users/1", nil, httpio.JSONPatch{}.Test("/version", 3).Replace("/name", "bob"), out)
+	if err != nil || out.Name != "bob" || out.Email != "a@example.com" {
+		t.Fatalf("unexpected output: %+v %v", out, err)
+	}
+
+	mp, _ := httpio.NewMergePatch(patchTestUser{Email: "a@example.com"}, patchTestUser{})
+	out = &patchTestUser{}
+	_, err = c.Patch(context.Background(), "/users/1", nil, mp, out)
+	if err != nil || out.Name != "alice" || out.Email != "" {
+		t.Fatalf("unexpected output: %+v %v", out, err)
+	}
+
+	var rerr *httpio.ResponseError
+	meta, err := c.Patch(context.Background(), "/users/1", nil, httpio.JSONPatch{}.Test("/version", 2), nil)
+	if !errors.As(err, &rerr) || meta.StatusCode != http.StatusConflict {
+		t.Fatalf("expected a conflict, got: %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package httpio
+
+import (
+	"net/http"
+
+	"github.com/advanderveer/go-httpio/header"
+)
+
+//PreferMinimal is an Egress transware that honours the 'Prefer: return=minimal' request header (RFC 7240)
+//for successful outputs: instead of encoding the output a 204 is written, or the status as is if it is
+//not a 200. The 'Preference-Applied' header is set to indicate the body was left out on purpose
+func PreferMinimal(next Transformer) Transformer {
+	return TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+		w.Header().Add("Vary", "Prefer")
+		if _, ok := a.(error); ok || header.ParsePrefer(r.Header, "Prefer").Return() != "minimal" {
+			return next.Transform(a, r, w)
+		}
+
+		status := statusOf(a, r)
+		switch {
+		case status == http.StatusOK:
+			status = http.StatusNoContent
+		case status < 200 || status > 299:
+			return next.Transform(a, r, w)
+		}
+
+		w.Header().Set("Preference-Applied", "return=minimal")
+		w.WriteHeader(status)
+		return nil
+	})
+}
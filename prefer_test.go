@@ -0,0 +1,112 @@
+package httpio_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+func TestPreferMinimal(t *testing.T) {
+	for _, c := range []struct {
+		Name       string
+		Prefer     string
+		Status     int
+		Value      interface{}
+		ExpStatus  int
+		ExpBody    string
+		ExpApplied string
+	}{
+		{
+			Name:      "no preference",
+			Value:     map[string]string{"foo": "bar"},
+			ExpStatus: http.StatusOK,
+			ExpBody:   `{"foo":"bar"}` + "\n",
+		},
+		{
+			Name:      "prefers representation",
+			Prefer:    "return=representation",
+			Value:     map[string]string{"foo": "bar"},
+			ExpStatus: http.StatusOK,
+			ExpBody:   `{"foo":"bar"}` + "\n",
+		},
+		{
+			Name:       "prefers minimal",
+			Prefer:     "return=minimal",
+			Value:      map[string]string{"foo": "bar"},
+			ExpStatus:  http.StatusNoContent,
+			ExpApplied: "return=minimal",
+		},
+		{
+			Name:       "prefers minimal on created",
+			Prefer:     "respond-async, return=minimal",
+			Status:     http.StatusCreated,
+			Value:      map[string]string{"foo": "bar"},
+			ExpStatus:  http.StatusCreated,
+			ExpApplied: "return=minimal",
+		},
+		{
+			Name:      "prefers minimal on error status",
+			Prefer:    "return=minimal",
+			Status:    http.StatusConflict,
+			Value:     map[string]string{"foo": "bar"},
+			ExpStatus: http.StatusConflict,
+			ExpBody:   `{"foo":"bar"}` + "\n",
+		},
+		{
+			Name:      "prefers minimal on error",
+			Prefer:    "return=minimal",
+			Value:     errors.New("foo"),
+			ExpStatus: http.StatusOK,
+			ExpBody:   `{"message":"foo"}` + "\n",
+		},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			e := httpio.NewEgress(&httpio.JSON{})
+			e.Use(httpio.PreferMinimal, errWare)
+
+			r, _ := http.NewRequest("GET", "/", nil)
+			r.Header.Set("Prefer", c.Prefer)
+			if c.Status != 0 {
+				r = r.WithContext(httpio.WithStatus(r.Context(), c.Status))
+			}
+
+			w := httptest.NewRecorder()
+			err := e.Render(c.Value, w, r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if w.Code != c.ExpStatus {
+				t.Fatalf("expected status %d, got: %d", c.ExpStatus, w.Code)
+			}
+
+			if w.Body.String() != c.ExpBody {
+				t.Fatalf("expected body '%s', got: '%s'", c.ExpBody, w.Body.String())
+			}
+
+			if w.Header().Get("Preference-Applied") != c.ExpApplied {
+				t.Fatalf("expected preference applied '%s', got: '%s'", c.ExpApplied, w.Header().Get("Preference-Applied"))
+			}
+		})
+	}
+}
+
+func TestPreferMinimalRouteStatus(t *testing.T) {
+	j := &httpio.JSON{}
+	rs := httpio.NewRoutes(httpio.NewIngress(httpio.NewEgress(j), j))
+	httpio.Handle(rs, http.MethodPost, "/items", func(ctx context.Context, in *struct{}) (*map[string]string, error) {
+		return &map[string]string{"foo": "bar"}, nil
+	}).With(httpio.RouteStatus(http.StatusCreated), httpio.RouteUseRender(httpio.PreferMinimal))
+
+	r, _ := http.NewRequest(http.MethodPost, "/items", nil)
+	r.Header.Set("Prefer", "return=minimal")
+	w := httptest.NewRecorder()
+	rs.ServeHTTP(w, r)
+	if w.Code != http.StatusCreated || w.Body.Len() != 0 || w.Header().Get("Preference-Applied") != "return=minimal" {
+		t.Fatalf("expected an empty 201, got: %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,170 @@
+//Package protobuf provides encoding factories for Protocol Buffer messages, it is a separate package such
+//that the core remains free of dependencies
+package protobuf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	httpio "github.com/advanderveer/go-httpio"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+var (
+	//MediaTypeProtobuf identifies binary protobuf content
+	MediaTypeProtobuf = "application/x-protobuf"
+)
+
+func message(v interface{}) (proto.Message, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("httpio/protobuf: cannot encode or decode %T, it is not a proto.Message", v)
+	}
+
+	return m, nil
+}
+
+//encodable returns the message to encode for 'v', errors that are no messages themselves are encoded as a
+//string value that holds their message such that handler errors can still be rendered
+func encodable(v interface{}) (proto.Message, error) {
+	if err, ok := v.(error); ok {
+		if _, isMsg := v.(proto.Message); !isMsg {
+			return wrapperspb.String(err.Error()), nil
+		}
+	}
+
+	return message(v)
+}
+
+//Binary encodes proto.Message values in the binary wire format
+type Binary struct {
+	//Delimited prefixes each message with its varint encoded size such that multiple messages can be
+	//streamed in one body. Without it a body holds exactly one message
+	Delimited bool
+
+	MarshalOptions   proto.MarshalOptions
+	UnmarshalOptions proto.UnmarshalOptions
+}
+
+//MimeType will report the EncodingMimeType
+func (e *Binary) MimeType() string { return MediaTypeProtobuf }
+
+//ContentType is sent without a charset as the format is binary
+func (e *Binary) ContentType() string { return MediaTypeProtobuf }
+
+//Encoder will create encoders
+func (e *Binary) Encoder(w io.Writer) httpio.Encoder { return &binaryEncoder{e, w} }
+
+//Decoder will create decoders
+func (e *Binary) Decoder(r io.Reader) httpio.Decoder { return &binaryDecoder{e, r, nil} }
+
+type binaryEncoder struct {
+	cfg *Binary
+	w   io.Writer
+}
+
+func (e *binaryEncoder) Encode(v interface{}) error {
+	m, err := encodable(v)
+	if err != nil {
+		return err
+	}
+
+	if e.cfg.Delimited {
+		_, err = protodelim.MarshalOptions{MarshalOptions: e.cfg.MarshalOptions}.MarshalTo(e.w, m)
+		return err
+	}
+
+	data, err := e.cfg.MarshalOptions.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+type binaryDecoder struct {
+	cfg *Binary
+	r   io.Reader
+	br  *bufio.Reader
+}
+
+func (d *binaryDecoder) Decode(v interface{}) error {
+	m, err := message(v)
+	if err != nil {
+		return err
+	}
+
+	if d.cfg.Delimited {
+		if d.br == nil {
+			d.br = bufio.NewReader(d.r) //buffered across calls, the reader may hold the next message
+		}
+
+		return protodelim.UnmarshalOptions{UnmarshalOptions: d.cfg.UnmarshalOptions}.UnmarshalFrom(d.br, m)
+	}
+
+	data, err := io.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+
+	return d.cfg.UnmarshalOptions.Unmarshal(data, m)
+}
+
+//JSON encodes proto.Message values using the canonical JSON mapping of protojson
+type JSON struct {
+	MarshalOptions   protojson.MarshalOptions
+	UnmarshalOptions protojson.UnmarshalOptions
+}
+
+//MimeType will report the EncodingMimeType
+func (e *JSON) MimeType() string { return httpio.MediaTypeJSON }
+
+//Encoder will create encoders
+func (e *JSON) Encoder(w io.Writer) httpio.Encoder { return &jsonEncoder{e, w} }
+
+//Decoder will create decoders
+func (e *JSON) Decoder(r io.Reader) httpio.Decoder { return &jsonDecoder{e, r} }
+
+type jsonEncoder struct {
+	cfg *JSON
+	w   io.Writer
+}
+
+func (e *jsonEncoder) Encode(v interface{}) error {
+	m, err := encodable(v)
+	if err != nil {
+		return err
+	}
+
+	data, err := e.cfg.MarshalOptions.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(append(data, '\n'))
+	return err
+}
+
+type jsonDecoder struct {
+	cfg *JSON
+	r   io.Reader
+}
+
+func (d *jsonDecoder) Decode(v interface{}) error {
+	m, err := message(v)
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+
+	return d.cfg.UnmarshalOptions.Unmarshal(data, m)
+}
@@ -0,0 +1,127 @@
+package protobuf_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+	"github.com/advanderveer/go-httpio/protobuf"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestBinary(t *testing.T) {
+	for _, delimited := range []bool{false, true} {
+		f := &protobuf.Binary{Delimited: delimited}
+		buf := bytes.NewBuffer(nil)
+		enc := f.Encoder(buf)
+		if err := enc.Encode(wrapperspb.String("foo")); err != nil {
+			t.Fatal(err)
+		}
+
+		if !delimited {
+			var out wrapperspb.StringValue
+			if err := f.Decoder(buf).Decode(&out); err != nil || out.Value != "foo" {
+				t.Fatalf("expected foo, got: %v %v", out.Value, err)
+			}
+
+			continue
+		}
+
+		if err := enc.Encode(wrapperspb.String("bar")); err != nil {
+			t.Fatal(err)
+		}
+
+		dec := f.Decoder(buf)
+		for _, exp := range []string{"foo", "bar"} {
+			var out wrapperspb.StringValue
+			if err := dec.Decode(&out); err != nil || out.Value != exp {
+				t.Fatalf("expected %s, got: %v %v", exp, out.Value, err)
+			}
+		}
+	}
+}
+
+func TestNonMessage(t *testing.T) {
+	for _, f := range []interface {
+		httpio.EncoderFactory
+		httpio.DecoderFactory
+	}{&protobuf.Binary{}, &protobuf.Binary{Delimited: true}, &protobuf.JSON{}} {
+		exp := "httpio/protobuf: cannot encode or decode struct { Foo string }, it is not a proto.Message"
+		if err := f.Encoder(bytes.NewBuffer(nil)).Encode(struct{ Foo string }{}); err == nil || err.Error() != exp {
+			t.Fatalf("expected error '%s', got: %v", exp, err)
+		}
+
+		exp = "httpio/protobuf: cannot encode or decode *string, it is not a proto.Message"
+		if err := f.Decoder(strings.NewReader("")).Decode(new(string)); err == nil || err.Error() != exp {
+			t.Fatalf("expected error '%s', got: %v", exp, err)
+		}
+	}
+}
+
+func TestNegotiation(t *testing.T) {
+	bin, js := &protobuf.Binary{}, &protobuf.JSON{}
+	e := httpio.NewEgress(js, bin)
+	i := httpio.NewIngress(e, js, bin)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &structpb.Struct{}
+		if render, ok := i.Handle(w, r, in); ok {
+			render(wrapperspb.String(in.Fields["name"].GetStringValue()), nil)
+		}
+	})
+
+	data, _ := proto.Marshal(&structpb.Struct{Fields: map[string]*structpb.Value{"name": structpb.NewStringValue("foo")}})
+	for _, c := range []struct {
+		Name        string
+		ContentType string
+		Accept      string
+		Body        []byte
+		ExpType     string
+		ExpBody     []byte
+	}{
+		{"json", "application/json", "application/json", []byte(`{"name":"foo"}`), "application/json; charset=utf-8", []byte(`"foo"` + "\n")},
+		{"binary", "application/x-protobuf", "application/x-protobuf", data, "application/x-protobuf", []byte("\x0a\x03foo")},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(c.Body))
+			r.Header.Set("Content-Type", c.ContentType)
+			r.Header.Set("Accept", c.Accept)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Header().Get("Content-Type") != c.ExpType || !bytes.Equal(w.Body.Bytes(), c.ExpBody) {
+				t.Fatalf("expected %s %q, got: %s %q", c.ExpType, c.ExpBody, w.Header().Get("Content-Type"), w.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestRenderError(t *testing.T) {
+	e := httpio.NewEgress(&protobuf.Binary{}, &protobuf.JSON{})
+	for _, c := range []struct {
+		Accept  string
+		ExpBody []byte
+	}{
+		{"application/x-protobuf", []byte("\x0a\x06failed")},
+		{"application/json", []byte(`"failed"` + "\n")},
+	} {
+		t.Run(c.Accept, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", c.Accept)
+			w := httptest.NewRecorder()
+			e.MustRender(errors.New("failed"), w, r)
+			if !bytes.Equal(w.Body.Bytes(), c.ExpBody) {
+				t.Fatalf("expected %q, got: %q", c.ExpBody, w.Body.Bytes())
+			}
+
+			var out wrapperspb.StringValue
+			if err := proto.Unmarshal(w.Body.Bytes(), &out); c.Accept == "application/x-protobuf" && (err != nil || out.Value != "failed") {
+				t.Fatalf("expected the message as a string value, got: %v %v", out.Value, err)
+			}
+		})
+	}
+}
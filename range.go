@@ -0,0 +1,162 @@
+package httpio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+//ErrContentChanged is returned by the client when a download cannot be resumed because the content
+//changed since it was interrupted, it must then be restarted from the first byte
+var ErrContentChanged = errors.New("httpio/client: content changed since the download was interrupted")
+
+//RangeReader can be implemented by outputs, or Content bodies, that can be read at any offset without
+//seeking, such as objects in remote storage. Range requests are then answered by reading only the
+//requested ranges. The *bytes.Reader, *strings.Reader and *io.SectionReader types implement it
+type RangeReader interface {
+	io.ReaderAt
+	Size() int64
+}
+
+//rangeSeeker returns a seeker for bodies whose ranges can be served
+func rangeSeeker(body io.Reader) (io.ReadSeeker, bool) {
+	if rs, ok := body.(io.ReadSeeker); ok {
+		return rs, true
+	}
+
+	if rr, ok := body.(RangeReader); ok {
+		return io.NewSectionReader(rr, 0, rr.Size()), true
+	}
+
+	return nil, false
+}
+
+//parseContentRange parses the Content-Range header value of a byte range response. The start and end are
+//-1 for unsatisfied ranges, the size is -1 if it is unknown
+func parseContentRange(v string) (start, end, size int64, ok bool) {
+	spec, found := strings.CutPrefix(v, "bytes ")
+	if !found {
+		return 0, 0, 0, false
+	}
+
+	rng, total, found := strings.Cut(strings.TrimSpace(spec), "/")
+	if !found {
+		return 0, 0, 0, false
+	}
+
+	size = -1
+	if total != "*" {
+		var err error
+		size, err = strconv.ParseInt(total, 10, 64)
+		if err != nil || size < 0 {
+			return 0, 0, 0, false
+		}
+	}
+
+	if rng == "*" {
+		return -1, -1, size, size >= 0
+	}
+
+	first, last, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+
+	end, err = strconv.ParseInt(last, 10, 64)
+	if err != nil || end < start || (size >= 0 && end >= size) {
+		return 0, 0, 0, false
+	}
+
+	return start, end, size, true
+}
+
+//Download writes the content at path 'p' to 'dst' as is, rather than decoding it. An interrupted
+//download is resumed by calling it again with 'offset' set to the number of bytes that 'dst' already
+//holds, only the remaining range is then requested. Pass the Validator of the interrupted response as
+//'validator' to make sure the parts belong to the same content, ErrContentChanged is returned without
+//writing anything if it changed. It returns the number of bytes 'dst' holds, also when interrupted again
+func (c *Client) Download(ctx context.Context, p string, hdr http.Header, dst io.Writer, offset int64, validator string) (meta *ResponseMeta, n int64, err error) {
+	ref, err := url.Parse(p)
+	if err != nil {
+		return nil, offset, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, c.base.ResolveReference(ref).String(), nil)
+	if err != nil {
+		return nil, offset, err
+	}
+
+	if hdr != nil {
+		req.Header = hdr.Clone()
+	}
+
+	req = req.WithContext(ctx)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if validator != "" {
+			req.Header.Set("If-Range", validator)
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, offset, err
+	}
+
+	defer resp.Body.Close()
+	meta = &ResponseMeta{StatusCode: resp.StatusCode, Header: resp.Header, Trailer: resp.Trailer, resp: resp}
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0 {
+		if _, _, size, ok := parseContentRange(resp.Header.Get("Content-Range")); ok && size == offset {
+			return meta, offset, nil //the interrupted download was already complete
+		}
+	}
+
+	if !isSuccess(resp.StatusCode) {
+		_, err = bufferErrBody(resp)
+		if err != nil {
+			return meta, offset, err
+		}
+	}
+
+	errOut := c.receiveErr(ctx, resp)
+	if errOut != nil {
+		return meta, offset, c.decodeErr(resp, errOut)
+	}
+
+	if offset > 0 {
+		switch start, _, _, ok := parseContentRange(resp.Header.Get("Content-Range")); {
+		case resp.StatusCode == http.StatusPartialContent && (!ok || start != offset):
+			return meta, offset, fmt.Errorf("httpio/client: expected content range starting at %d, got: '%s'", offset, resp.Header.Get("Content-Range"))
+		case resp.StatusCode != http.StatusPartialContent && validator != "":
+			return meta, offset, ErrContentChanged
+		case resp.StatusCode != http.StatusPartialContent:
+			_, err = io.CopyN(io.Discard, resp.Body, offset) //ranges are not supported, skip what 'dst' holds
+			if err != nil {
+				return meta, offset, err
+			}
+		}
+	}
+
+	written, err := io.Copy(dst, resp.Body)
+	return meta, offset + written, err
+}
+
+//Validator returns the value that identifies this version of the content when resuming its download: the
+//ETag if it is a strong one or else the Last-Modified header, weak entity tags cannot be used for ranges
+func (m *ResponseMeta) Validator() string {
+	if etag := m.ETag(); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+
+	return m.Header.Get("Last-Modified")
+}
@@ -0,0 +1,158 @@
+package httpio_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+//rangeTestObject can only be read at offsets, like an object in remote storage
+type rangeTestObject struct{ data string }
+
+func (o rangeTestObject) ReadAt(p []byte, off int64) (int, error) {
+	return strings.NewReader(o.data).ReadAt(p, off)
+}
+
+func (o rangeTestObject) Size() int64 { return int64(len(o.data)) }
+
+func TestRangeRequests(t *testing.T) {
+	for _, c := range []struct {
+		Name     string
+		Output   interface{}
+		Hdr      http.Header
+		ExpCode  int
+		ExpRange string
+		ExpBody  string
+	}{
+		{"range reader", rangeTestObject{"0123456789"}, http.Header{"Range": {"bytes=2-4"}}, http.StatusPartialContent, "bytes 2-4/10", "234"},
+		{"range reader body", &httpio.Content{Body: io.NewSectionReader(rangeTestObject{"0123456789"}, 0, 10)}, http.Header{"Range": {"bytes=-3"}}, http.StatusPartialContent, "bytes 7-9/10", "789"},
+		{"unsatisfiable", rangeTestObject{"0123456789"}, http.Header{"Range": {"bytes=20-"}}, http.StatusRequestedRangeNotSatisfiable, "bytes */10", ""},
+		{"if-range match", &httpio.Content{Body: strings.NewReader("0123456789"), ETag: `"v1"`}, http.Header{"Range": {"bytes=5-"}, "If-Range": {`"v1"`}}, http.StatusPartialContent, "bytes 5-9/10", "56789"},
+		{"if-range mismatch", &httpio.Content{Body: strings.NewReader("0123456789"), ETag: `"v2"`}, http.Header{"Range": {"bytes=5-"}, "If-Range": {`"v1"`}}, http.StatusOK, "", "0123456789"},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range c.Hdr {
+				r.Header[k] = v
+			}
+
+			w := httptest.NewRecorder()
+			err := httpio.NewEgress(&httpio.JSON{}).Render(c.Output, w, r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if w.Code != c.ExpCode || w.Header().Get("Content-Range") != c.ExpRange || w.Header().Get("Accept-Ranges") != "bytes" {
+				t.Fatalf("expected %d with range '%s', got: %d %v", c.ExpCode, c.ExpRange, w.Code, w.Header())
+			}
+
+			if c.ExpCode != http.StatusRequestedRangeNotSatisfiable && w.Body.String() != c.ExpBody {
+				t.Fatalf("expected body %q, got: %q", c.ExpBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRangeMultipart(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Range", "bytes=0-1,5-6")
+	w := httptest.NewRecorder()
+	err := httpio.NewEgress(&httpio.JSON{}).Render(&httpio.Content{Body: strings.NewReader("0123456789"), Type: "text/plain"}, w, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mt, params, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	if w.Code != http.StatusPartialContent || mt != "multipart/byteranges" {
+		t.Fatalf("expected multipart 206, got: %d %s", w.Code, mt)
+	}
+
+	mr := multipart.NewReader(w.Body, params["boundary"])
+	for _, exp := range []struct{ Range, Body string }{{"bytes 0-1/10", "01"}, {"bytes 5-6/10", "56"}} {
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, _ := io.ReadAll(part)
+		if part.Header.Get("Content-Range") != exp.Range || part.Header.Get("Content-Type") != "text/plain" || string(data) != exp.Body {
+			t.Fatalf("expected part %s %q, got: %v %q", exp.Range, exp.Body, part.Header, data)
+		}
+	}
+}
+
+//rangeTestWriter fails once it holds 'max' bytes, like a download that is interrupted
+type rangeTestWriter struct {
+	buf bytes.Buffer
+	max int
+}
+
+func (w *rangeTestWriter) Write(p []byte) (int, error) {
+	if w.max > 0 && w.buf.Len()+len(p) > w.max {
+		n, _ := w.buf.Write(p[:w.max-w.buf.Len()])
+		return n, errors.New("interrupted")
+	}
+
+	return w.buf.Write(p)
+}
+
+func (w *rangeTestWriter) String() string { return w.buf.String() }
+
+func TestClientDownload(t *testing.T) {
+	data, etag := "0123456789", `"v1"`
+	e := httpio.NewEgress(&httpio.JSON{})
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain" {
+			w.Write([]byte(data)) //no support for ranges
+			return
+		}
+
+		e.Render(&httpio.Content{Body: strings.NewReader(data), ETag: etag}, w, r)
+	}))
+	defer svr.Close()
+
+	c, err := httpio.NewClient(svr.Client(), svr.URL, &httpio.JSON{}, &httpio.JSON{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	dst := &rangeTestWriter{max: 4}
+	meta, n, err := c.Download(ctx, "/", nil, dst, 0, "")
+	if err == nil || n != 4 || meta.Validator() != `"v1"` {
+		t.Fatalf("expected interrupted download, got: %d %v", n, err)
+	}
+
+	dst.max = 0
+	meta, n, err = c.Download(ctx, "/", nil, dst, n, meta.Validator())
+	if err != nil || n != 10 || meta.StatusCode != http.StatusPartialContent || dst.String() != data {
+		t.Fatalf("expected resumed download, got: %d %q %v", n, dst.String(), err)
+	}
+
+	meta, n, err = c.Download(ctx, "/", nil, dst, n, meta.Validator())
+	if err != nil || n != 10 || meta.StatusCode != http.StatusRequestedRangeNotSatisfiable || dst.String() != data {
+		t.Fatalf("expected complete download to be left as is, got: %d %q %v", n, dst.String(), err)
+	}
+
+	plain := &rangeTestWriter{}
+	plain.Write([]byte("0123"))
+	_, n, err = c.Download(ctx, "/plain", nil, plain, 4, "")
+	if err != nil || n != 10 || plain.String() != data {
+		t.Fatalf("expected skipped bytes without range support, got: %d %q %v", n, plain.String(), err)
+	}
+
+	etag = `"v2"`
+	_, n, err = c.Download(ctx, "/", nil, io.Discard, 4, `"v1"`)
+	if !errors.Is(err, httpio.ErrContentChanged) || n != 4 {
+		t.Fatalf("expected content changed error, got: %d %v", n, err)
+	}
+}
@@ -0,0 +1,270 @@
+package httpio
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	//MediaTypeText identifies plain text content
+	MediaTypeText = "text/plain"
+
+	//MediaTypeOctetStream identifies arbitrary binary content
+	MediaTypeOctetStream = "application/octet-stream"
+)
+
+//Text encodes strings, byte slices, errors, fmt.Stringer and encoding.TextMarshaler values as plain text,
+//readers are copied as is and other basic values are formatted with fmt. It decodes into pointers to
+//strings, byte slices and readers, or into an io.Writer
+type Text struct{}
+
+//MimeType will report the EncodingMimeType
+func (e *Text) MimeType() string { return MediaTypeText }
+
+//Encoder will create encoders
+func (e *Text) Encoder(w io.Writer) Encoder { return &rawEncoder{"text", true, w} }
+
+//Decoder will create decoders
+func (e *Text) Decoder(r io.Reader) Decoder { return &rawDecoder{"text", r} }
+
+//OctetStream encodes byte slices, strings and readers as is. It decodes into pointers to strings, byte
+//slices and readers, or into an io.Writer
+type OctetStream struct{}
+
+//MimeType will report the EncodingMimeType
+func (e *OctetStream) MimeType() string { return MediaTypeOctetStream }
+
+//ContentType is sent without a charset as the format is binary
+func (e *OctetStream) ContentType() string { return MediaTypeOctetStream }
+
+//Encoder will create encoders
+func (e *OctetStream) Encoder(w io.Writer) Encoder { return &rawEncoder{"octet-stream", false, w} }
+
+//Decoder will create decoders
+func (e *OctetStream) Decoder(r io.Reader) Decoder { return &rawDecoder{"octet-stream", r} }
+
+type rawEncoder struct {
+	name string
+	text bool
+	w    io.Writer
+}
+
+func (e *rawEncoder) Encode(v interface{}) (err error) {
+	switch vt := v.(type) {
+	case string:
+		_, err = io.WriteString(e.w, vt)
+		return err
+	case []byte:
+		_, err = e.w.Write(vt)
+		return err
+	case io.WriterTo:
+		_, err = vt.WriteTo(e.w)
+		return err
+	case io.Reader:
+		_, err = io.Copy(e.w, vt)
+		return err
+	case error:
+		_, err = io.WriteString(e.w, vt.Error()) //also in binary mode, such that handler errors can be rendered
+		return err
+	}
+
+	if e.text {
+		switch vt := v.(type) {
+		case fmt.Stringer:
+			_, err = io.WriteString(e.w, vt.String())
+			return err
+		case encoding.TextMarshaler:
+			text, err := vt.MarshalText()
+			if err != nil {
+				return err
+			}
+
+			_, err = e.w.Write(text)
+			return err
+		}
+
+		switch reflect.ValueOf(v).Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64, reflect.String:
+			_, err = fmt.Fprint(e.w, v)
+			return err
+		}
+	}
+
+	return fmt.Errorf("httpio/%s: cannot encode value of type %T", e.name, v)
+}
+
+type rawDecoder struct {
+	name string
+	r    io.Reader
+}
+
+func (d *rawDecoder) Decode(v interface{}) error {
+	switch vt := v.(type) {
+	case *io.Reader:
+		*vt = d.r
+		return nil
+	case io.Writer:
+		_, err := io.Copy(vt, d.r)
+		return err
+	case *[]byte, *string:
+		data, err := io.ReadAll(d.r)
+		if err != nil {
+			return err
+		}
+
+		if s, ok := vt.(*string); ok {
+			*s = string(data)
+		} else {
+			*(vt.(*[]byte)) = data
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("httpio/%s: cannot decode into value of type %T", d.name, v)
+}
+
+//Content is an output that is sent as is, rather than encoded by the negotiated encoder. Outputs that
+//are an io.Reader, io.WriterTo or RangeReader are sent as Content without a name
+type Content struct {
+	//Body provides the content, an io.ReadSeeker or RangeReader allows Range requests to be honoured. If
+	//it is an io.Closer it is closed once sent
+	Body io.Reader
+
+	//Name is used as the filename in the Content-Disposition header, the header is left out if empty
+	Name string
+
+	//Type is the Content-Type, it defaults to the type of the extension of Name or else to
+	//application/octet-stream
+	Type string
+
+	//ModTime allows conditional requests to be answered, see http.ServeContent
+	ModTime time.Time
+
+	//ETag is sent as is and allows conditional requests, including If-Range, to be answered. It must be
+	//quoted, e.g: `"v1"`
+	ETag string
+
+	//Inline causes browsers to display the content rather than to download it
+	Inline bool
+}
+
+//rawContent returns the output as content if it should be sent as is
+func rawContent(out interface{}) (*Content, bool) {
+	switch ot := out.(type) {
+	case *Content:
+		return ot, true
+	case Content:
+		return &ot, true
+	case io.Reader:
+		return &Content{Body: ot}, true
+	case io.WriterTo:
+		return &Content{Body: writerToReader{ot}}, true
+	case RangeReader:
+		return &Content{Body: io.NewSectionReader(ot, 0, ot.Size())}, true
+	}
+
+	return nil, false
+}
+
+//writerToReader allows io.WriterTo values to be sent like readers, it is never actually read from
+type writerToReader struct{ io.WriterTo }
+
+func (writerToReader) Read(p []byte) (int, error) { return 0, io.EOF }
+
+//serveContent writes the content, honouring Range and conditional headers if the body can seek or read
+//at offsets and the status is not customized. Multiple ranges are sent as multipart/byteranges and
+//unsatisfiable ranges are answered with 416
+func serveContent(c *Content, status int, w http.ResponseWriter, r *http.Request) (err error) {
+	body := c.Body
+	if body == nil {
+		body = bytes.NewReader(nil) //content without a body is sent as empty
+	}
+
+	if cl, ok := body.(io.Closer); ok {
+		defer cl.Close()
+	}
+
+	ct := c.Type
+	if ct == "" {
+		ct = mime.TypeByExtension(path.Ext(c.Name))
+	}
+
+	if ct == "" {
+		ct = MediaTypeOctetStream
+	}
+
+	w.Header().Set("Content-Type", ct)
+	if c.Name != "" {
+		disposition := "attachment"
+		if c.Inline {
+			disposition = "inline"
+		}
+
+		w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": c.Name}))
+	}
+
+	if c.ETag != "" {
+		w.Header().Set("ETag", c.ETag)
+	}
+
+	if rs, ok := rangeSeeker(body); ok && (status == 0 || status == http.StatusOK) {
+		w.Header().Set("Accept-Ranges", "bytes") //also for 416 responses, which http.ServeContent leaves out
+		http.ServeContent(w, r, c.Name, c.ModTime, rs)
+		return nil
+	}
+
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if l, ok := body.(interface{ Len() int }); ok {
+		w.Header().Set("Content-Length", strconv.Itoa(l.Len()))
+	}
+
+	w.WriteHeader(status)
+	if wt, ok := body.(writerToReader); ok {
+		_, err = wt.WriteTo(w)
+		return err
+	}
+
+	_, err = io.Copy(w, body)
+	return err
+}
+
+//bindRawBody binds the request body into inputs that are, or have a field tagged `body:"raw"` that is, a
+//[]byte, string or io.Reader. The body is not closed when it is bound to a reader. It reports whether
+//the body was bound
+func bindRawBody(a interface{}, r *http.Request) (bool, error) {
+	dec := &rawDecoder{"ingress", r.Body}
+	switch a.(type) {
+	case *[]byte, *string, *io.Reader:
+		return true, dec.Decode(a)
+	}
+
+	v := reflect.ValueOf(a)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return false, nil
+	}
+
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Tag.Get("body") != "raw" || !v.Field(i).CanSet() {
+			continue
+		}
+
+		return true, dec.Decode(v.Field(i).Addr().Interface())
+	}
+
+	return false, nil
+}
@@ -0,0 +1,215 @@
+package httpio_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+type rawTestWriterTo struct{}
+
+func (rawTestWriterTo) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, "written")
+	return int64(n), err
+}
+
+func TestRawEncoding(t *testing.T) {
+	for _, c := range []struct {
+		Name   string
+		Enc    httpio.EncoderFactory
+		Value  interface{}
+		Exp    string
+		ExpErr string
+	}{
+		{"string", &httpio.Text{}, "ok", "ok", ""},
+		{"bytes", &httpio.Text{}, []byte("ok"), "ok", ""},
+		{"stringer", &httpio.Text{}, net.IPv4(127, 0, 0, 1), "127.0.0.1", ""},
+		{"error", &httpio.Text{}, errors.New("oops"), "oops", ""},
+		{"stringer before text marshaler", &httpio.Text{}, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), "2024-01-02 00:00:00 +0000 UTC", ""},
+		{"number", &httpio.Text{}, 4.5, "4.5", ""},
+		{"reader", &httpio.Text{}, strings.NewReader("read"), "read", ""},
+		{"struct", &httpio.Text{}, struct{}{}, "", "httpio/text: cannot encode value of type struct {}"},
+		{"octets", &httpio.OctetStream{}, []byte{0, 1}, "\x00\x01", ""},
+		{"octets writer to", &httpio.OctetStream{}, rawTestWriterTo{}, "written", ""},
+		{"octets number", &httpio.OctetStream{}, 1, "", "httpio/octet-stream: cannot encode value of type int"},
+		{"octets error", &httpio.OctetStream{}, errors.New("oops"), "oops", ""},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			err := c.Enc.Encoder(buf).Encode(c.Value)
+			if c.ExpErr != "" {
+				if err == nil || err.Error() != c.ExpErr {
+					t.Fatalf("expected error '%s', got: %v", c.ExpErr, err)
+				}
+
+				return
+			}
+
+			if err != nil || buf.String() != c.Exp {
+				t.Fatalf("expected %q, got: %q %v", c.Exp, buf.String(), err)
+			}
+		})
+	}
+}
+
+func TestRawDecoding(t *testing.T) {
+	var s string
+	var b []byte
+	var r io.Reader
+	buf := bytes.NewBuffer(nil)
+	for _, v := range []interface{}{&s, &b, &r, buf} {
+		if err := (&httpio.OctetStream{}).Decoder(strings.NewReader("body")).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, _ := io.ReadAll(r)
+	if s != "body" || string(b) != "body" || string(data) != "body" || buf.String() != "body" {
+		t.Fatalf("unexpected decoded values: %q %q %q %q", s, b, data, buf.String())
+	}
+
+	err := (&httpio.Text{}).Decoder(strings.NewReader("body")).Decode(new(int))
+	if exp := "httpio/text: cannot decode into value of type *int"; err == nil || err.Error() != exp {
+		t.Fatalf("expected error '%s', got: %v", exp, err)
+	}
+}
+
+func TestRawContent(t *testing.T) {
+	mod := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, c := range []struct {
+		Name    string
+		Output  interface{}
+		Status  int
+		Hdr     http.Header
+		ExpCode int
+		ExpHdr  http.Header
+		ExpBody string
+	}{
+		{
+			Name:    "reader",
+			Output:  bytes.NewBufferString("hello"),
+			ExpCode: http.StatusOK,
+			ExpHdr:  http.Header{"Content-Type": {"application/octet-stream"}, "Content-Length": {"5"}},
+			ExpBody: "hello",
+		},
+		{
+			Name:    "no body",
+			Output:  &httpio.Content{Name: "empty.txt"},
+			ExpCode: http.StatusOK,
+			ExpHdr: http.Header{
+				"Content-Type":        {"text/plain; charset=utf-8"},
+				"Content-Disposition": {`attachment; filename=empty.txt`},
+				"Content-Length":      {"0"},
+				"Accept-Ranges":       {"bytes"},
+			},
+			ExpBody: "",
+		},
+		{
+			Name:    "writer to",
+			Output:  rawTestWriterTo{},
+			Status:  http.StatusCreated,
+			ExpCode: http.StatusCreated,
+			ExpHdr:  http.Header{"Content-Type": {"application/octet-stream"}},
+			ExpBody: "written",
+		},
+		{
+			Name:    "download",
+			Output:  &httpio.Content{Body: strings.NewReader("a,b\n"), Name: "report 1.csv", ModTime: mod},
+			ExpCode: http.StatusOK,
+			ExpHdr: http.Header{
+				"Content-Type":        {"text/csv; charset=utf-8"},
+				"Content-Disposition": {`attachment; filename="report 1.csv"`},
+				"Content-Length":      {"4"},
+				"Last-Modified":       {"Tue, 02 Jan 2024 03:04:05 GMT"},
+				"Accept-Ranges":       {"bytes"},
+			},
+			ExpBody: "a,b\n",
+		},
+		{
+			Name:    "range",
+			Output:  httpio.Content{Body: strings.NewReader("0123456789"), Name: "digits.txt", Inline: true},
+			Hdr:     http.Header{"Range": {"bytes=2-4"}},
+			ExpCode: http.StatusPartialContent,
+			ExpHdr: http.Header{
+				"Content-Type":        {"text/plain; charset=utf-8"},
+				"Content-Disposition": {`inline; filename=digits.txt`},
+				"Content-Length":      {"3"},
+				"Content-Range":       {"bytes 2-4/10"},
+				"Accept-Ranges":       {"bytes"},
+			},
+			ExpBody: "234",
+		},
+		{
+			Name:    "not modified",
+			Output:  &httpio.Content{Body: strings.NewReader("a,b\n"), Type: "text/csv", ModTime: mod},
+			Hdr:     http.Header{"If-Modified-Since": {"Tue, 02 Jan 2024 03:04:05 GMT"}},
+			ExpCode: http.StatusNotModified,
+			ExpHdr:  http.Header{"Last-Modified": {"Tue, 02 Jan 2024 03:04:05 GMT"}},
+		},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			e := httpio.NewEgress(&httpio.JSON{})
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range c.Hdr {
+				r.Header[k] = v
+			}
+
+			if c.Status != 0 {
+				r = r.WithContext(httpio.WithStatus(r.Context(), c.Status))
+			}
+
+			w := httptest.NewRecorder()
+			err := e.Render(c.Output, w, r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if w.Code != c.ExpCode || w.Body.String() != c.ExpBody {
+				t.Fatalf("expected %d %q, got: %d %q", c.ExpCode, c.ExpBody, w.Code, w.Body.String())
+			}
+
+			for k := range c.ExpHdr {
+				if w.Header().Get(k) != c.ExpHdr.Get(k) {
+					t.Fatalf("expected header %s: %s, got: %s", k, c.ExpHdr.Get(k), w.Header().Get(k))
+				}
+			}
+		})
+	}
+}
+
+type rawTestUpload struct {
+	ID   string    `query:"id"`
+	Body io.Reader `body:"raw"`
+}
+
+func TestRawBinding(t *testing.T) {
+	i := httpio.NewIngress(httpio.NewEgress(&httpio.JSON{}), &httpio.JSON{})
+	i.Use(httpio.BindParams)
+
+	var s string
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"not":"decoded"}`))
+	r.Header.Set("Content-Type", "application/json")
+	if err := i.Parse(r, &s); err != nil || s != `{"not":"decoded"}` {
+		t.Fatalf("expected raw string, got: %q %v", s, err)
+	}
+
+	in := &rawTestUpload{}
+	r = httptest.NewRequest(http.MethodPut, "/?id=a1", strings.NewReader("binary data"))
+	r.Header.Set("Content-Type", "image/png")
+	if err := i.Parse(r, in); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(in.Body)
+	if err != nil || string(data) != "binary data" || in.ID != "a1" {
+		t.Fatalf("expected open body and bound params, got: %q %v %+v", data, err, in)
+	}
+}
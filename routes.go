@@ -0,0 +1,165 @@
+package httpio
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+)
+
+//Route describes a typed handler that was registered with Handle, it is used to document the api
+type Route struct {
+	Method  string
+	Pattern string
+	Input   reflect.Type
+	Output  reflect.Type
+
+	//Code is the status code of successful responses, zero means 200 OK
+	Code        int
+	Summary     string
+	Description string
+	OperationID string
+	Tags        []string
+	Deprecated  bool
+
+	//Errors maps status codes to the shape of the error responses with that status
+	Errors map[int]reflect.Type
+
+	ingress *Ingress
+}
+
+//Describe sets a short summary and a longer description of the route
+func (rt *Route) Describe(summary, description string) *Route {
+	rt.Summary, rt.Description = summary, description
+	return rt
+}
+
+//Operation sets the unique identifier of the route's operation
+func (rt *Route) Operation(id string) *Route {
+	rt.OperationID = id
+	return rt
+}
+
+//Tag adds tag(s) that are used to group the route
+func (rt *Route) Tag(tags ...string) *Route {
+	rt.Tags = append(rt.Tags, tags...)
+	return rt
+}
+
+//Returns sets the status code that is rendered when the handler succeeds
+func (rt *Route) Returns(code int) *Route {
+	rt.Code = code
+	return rt
+}
+
+//With scopes the encodings, transwares and limits of the route, see Ingress.With
+func (rt *Route) With(opts ...RouteOption) *Route {
+	rt.ingress = rt.ingress.With(opts...)
+	return rt
+}
+
+//Ingress returns the stack that parses the requests of the route, its egress renders the responses
+func (rt *Route) Ingress() *Ingress { return rt.ingress }
+
+//Error documents that the route responds with status 'code' and an error rendered in the shape of 'proto'
+func (rt *Route) Error(code int, proto interface{}) *Route {
+	if rt.Errors == nil {
+		rt.Errors = map[int]reflect.Type{}
+	}
+
+	rt.Errors[code] = reflect.TypeOf(proto)
+	return rt
+}
+
+//Routes registers typed handlers on a http.ServeMux while recording their shape such that it can be
+//documented, e.g. using the openapi package
+type Routes struct {
+	ingress *Ingress
+	mux     *http.ServeMux
+	routes  []*Route
+	errors  map[int]reflect.Type
+}
+
+//NewRoutes creates an empty registry that parses requests and renders responses using the ingress stack
+func NewRoutes(i *Ingress) *Routes {
+	return &Routes{ingress: i, mux: http.NewServeMux(), errors: map[int]reflect.Type{}}
+}
+
+//Ingress returns the stack that is used by the routes
+func (rs *Routes) Ingress() *Ingress { return rs.ingress }
+
+//Routes returns the registered routes in order of registration
+func (rs *Routes) Routes() []*Route { return rs.routes }
+
+//Error documents that all routes may respond with status 'code' and an error in the shape of 'proto'
+func (rs *Routes) Error(code int, proto interface{}) *Routes {
+	rs.errors[code] = reflect.TypeOf(proto)
+	return rs
+}
+
+//Errors returns the error shapes that apply to the route, route specific errors take precedence
+func (rs *Routes) Errors(rt *Route) map[int]reflect.Type {
+	errs := map[int]reflect.Type{}
+	for code, t := range rs.errors {
+		errs[code] = t
+	}
+
+	for code, t := range rt.Errors {
+		errs[code] = t
+	}
+
+	return errs
+}
+
+//ServeHTTP routes the request to the handler that was registered with a matching pattern
+func (rs *Routes) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rs.mux.ServeHTTP(w, r)
+}
+
+//Handle registers business function 'fn' for requests with 'method' that match 'pattern' in the syntax of
+//http.ServeMux, e.g. "/items/{id}". Fields of the input tagged with 'path', 'query' or 'header' are bound
+//first (see BindParams), the request body is then parsed by the ingress stack after which the parameters
+//are bound again such that the body cannot overwrite them. Output and errors are rendered using the egress
+//stack, 'fn' may pick the template they are rendered with using SetTemplate.
+func Handle[I, O any](rs *Routes, method, pattern string, fn func(context.Context, *I) (*O, error)) *Route {
+	rt := &Route{
+		Method:  method,
+		Pattern: pattern,
+		Input:   reflect.TypeOf((*I)(nil)).Elem(),
+		Output:  reflect.TypeOf((*O)(nil)).Elem(),
+		ingress: rs.ingress,
+	}
+
+	rs.mux.HandleFunc(method+" "+pattern, func(w http.ResponseWriter, r *http.Request) {
+		i, e := rt.ingress, rt.ingress.Egress()
+		in := new(I)
+		err := bindParams(in, r)
+		if err == nil {
+			err = i.Parse(r, in)
+		}
+
+		if err == nil {
+			err = bindParams(in, r)
+		}
+
+		if err != nil {
+			e.MustRender(err, w, r)
+			return
+		}
+
+		r = r.WithContext(WithTemplate(r.Context(), TemplateValue(r.Context()))) //see SetTemplate
+		out, err := fn(r.Context(), in)
+		if err != nil {
+			e.MustRender(err, w, r)
+			return
+		}
+
+		if rt.Code != 0 {
+			r = r.WithContext(WithStatus(r.Context(), rt.Code))
+		}
+
+		e.MustRender(out, w, r)
+	})
+
+	rs.routes = append(rs.routes, rt)
+	return rt
+}
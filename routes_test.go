@@ -0,0 +1,127 @@
+package httpio_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+type routeTestInput struct {
+	ID   string `path:"id"`
+	Name string `json:"name"`
+}
+
+type routeTestOutput struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func newRouteTestRoutes() *httpio.Routes {
+	j := &httpio.JSON{}
+	e := httpio.NewEgress(j)
+	e.Use(stdErrWare)
+	return httpio.NewRoutes(httpio.NewIngress(e, j))
+}
+
+func TestRoutes(t *testing.T) {
+	rs := newRouteTestRoutes()
+	create := httpio.Handle(rs, http.MethodPut, "/items/{id}", func(ctx context.Context, in *routeTestInput) (*routeTestOutput, error) {
+		if in.Name == "" {
+			return nil, errors.New("name is required")
+		}
+
+		return &routeTestOutput{ID: in.ID, Name: in.Name}, nil
+	}).Returns(http.StatusCreated).Describe("Create item", "").Tag("items").Error(http.StatusBadRequest, struct{}{})
+
+	for _, c := range []struct {
+		Name      string
+		Method    string
+		Target    string
+		Body      string
+		ExpStatus int
+		ExpBody   string
+	}{
+		{"created", http.MethodPut, "/items/a1", `{"name":"foo"}`, http.StatusCreated, `{"id":"a1","name":"foo"}` + "\n"},
+		{"path over body", http.MethodPut, "/items/a1", `{"ID":"b2","name":"foo"}`, http.StatusCreated, `{"id":"a1","name":"foo"}` + "\n"},
+		{"business error", http.MethodPut, "/items/a1", `{}`, http.StatusInternalServerError, `{"message":"name is required"}` + "\n"},
+		{"decode error", http.MethodPut, "/items/a1", `{`, http.StatusBadRequest, `{"message":"unexpected EOF"}` + "\n"},
+		{"method not allowed", http.MethodGet, "/items/a1", ``, http.StatusMethodNotAllowed, "Method Not Allowed\n"},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			r, _ := http.NewRequest(c.Method, c.Target, strings.NewReader(c.Body))
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			rs.ServeHTTP(w, r)
+			if w.Code != c.ExpStatus {
+				t.Fatalf("expected status %d, got: %d", c.ExpStatus, w.Code)
+			}
+
+			if w.Body.String() != c.ExpBody {
+				t.Fatalf("expected body %q, got: %q", c.ExpBody, w.Body.String())
+			}
+		})
+	}
+
+	if len(rs.Routes()) != 1 || rs.Routes()[0] != create {
+		t.Fatalf("expected route to be recorded, got: %v", rs.Routes())
+	}
+
+	if create.Input.Name() != "routeTestInput" || create.Output.Name() != "routeTestOutput" || create.Summary != "Create item" {
+		t.Fatalf("unexpected route description: %+v", create)
+	}
+
+	rs.Error(http.StatusInternalServerError, struct{ Message string }{})
+	if errs := rs.Errors(create); len(errs) != 2 {
+		t.Fatalf("expected route and registry errors, got: %v", errs)
+	}
+}
+
+func TestRoutesWith(t *testing.T) {
+	rs := newRouteTestRoutes()
+	rt := httpio.Handle(rs, http.MethodPost, "/items", func(ctx context.Context, in *routeTestInput) (*routeTestOutput, error) {
+		return &routeTestOutput{Name: in.Name}, nil
+	}).With(httpio.RouteMaxBodySize(8), httpio.RouteStatus(http.StatusCreated))
+
+	for _, c := range []struct {
+		Body      string
+		ExpStatus int
+	}{
+		{`{"name":"a"}`, http.StatusBadRequest},
+		{`{"a":1}`, http.StatusCreated},
+	} {
+		r, _ := http.NewRequest(http.MethodPost, "/items", strings.NewReader(c.Body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		rs.ServeHTTP(w, r)
+		if w.Code != c.ExpStatus {
+			t.Fatalf("expected status %d for %s, got: %d %s", c.ExpStatus, c.Body, w.Code, w.Body.String())
+		}
+	}
+
+	if rt.Ingress() == rs.Ingress() {
+		t.Fatal("expected the route to have its own view of the stack")
+	}
+
+	var rawErr error
+	httpio.Handle(rs, http.MethodPut, "/items", func(ctx context.Context, in *routeTestInput) (*routeTestOutput, error) {
+		return &routeTestOutput{}, nil
+	}).With(httpio.RouteMaxBodySize(8), httpio.RouteUseRaw(func(next httpio.Transformer) httpio.Transformer {
+		return httpio.TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+			_, rawErr = io.ReadAll(r.Body)
+			return rawErr
+		})
+	}))
+
+	r, _ := http.NewRequest(http.MethodPut, "/items", strings.NewReader(`{"name":"abcdef"}`))
+	r.ContentLength = -1 //unknown length, as with chunked requests
+	rs.ServeHTTP(httptest.NewRecorder(), r)
+	if merr := (*http.MaxBytesError)(nil); !errors.As(rawErr, &merr) {
+		t.Fatalf("expected raw wares to read a limited body, got: %v", rawErr)
+	}
+}
@@ -0,0 +1,50 @@
+package httpio
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/advanderveer/go-httpio/header"
+)
+
+//StructuredHeaders is an Ingress transware that binds RFC 8941 dictionary headers into fields of the input
+//that are tagged with the header name, e.g: `sf:"Priority"`. A field can be a header.Dictionary or a struct
+//that is bound using header.UnmarshalSFDictionary. Malformed headers are reported as decode errors.
+func StructuredHeaders(next Transformer) Transformer {
+	return TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+		v := reflect.ValueOf(a)
+		if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+			return next.Transform(a, r, w)
+		}
+
+		v = v.Elem()
+		for i := 0; i < v.NumField(); i++ {
+			name := v.Type().Field(i).Tag.Get("sf")
+			if name == "" || !v.Field(i).CanSet() {
+				continue
+			}
+
+			d, err := header.ParseSFDictionary(r.Header, name)
+			if err != nil {
+				return decodeErr{err}
+			}
+
+			if len(d) < 1 {
+				continue
+			}
+
+			fv := v.Field(i)
+			switch {
+			case fv.Type() == reflect.TypeOf(d):
+				fv.Set(reflect.ValueOf(d))
+			case fv.Kind() == reflect.Struct:
+				err = header.UnmarshalSFDictionary(d, fv.Addr().Interface())
+				if err != nil {
+					return decodeErr{err}
+				}
+			}
+		}
+
+		return next.Transform(a, r, w)
+	})
+}
@@ -0,0 +1,84 @@
+package httpio_test
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+	"github.com/advanderveer/go-httpio/header"
+)
+
+type priority struct {
+	Urgency     int  `sf:"u"`
+	Incremental bool `sf:"i"`
+}
+
+type sfvTestInput struct {
+	Name     string            `json:"name"`
+	Priority priority          `json:"-" sf:"Priority"`
+	Cache    header.Dictionary `json:"-" sf:"X-Cache"`
+}
+
+func TestStructuredHeaders(t *testing.T) {
+	for _, c := range []struct {
+		Name     string
+		Headers  http.Header
+		ExpInput *sfvTestInput
+		ExpErr   bool
+	}{
+		{
+			Name:     "no headers",
+			ExpInput: &sfvTestInput{Name: "foo"},
+		},
+		{
+			Name:    "dictionary headers",
+			Headers: http.Header{"Priority": {"u=1, i"}, "X-Cache": {"hit"}},
+			ExpInput: &sfvTestInput{
+				Name:     "foo",
+				Priority: priority{Urgency: 1, Incremental: true},
+				Cache:    header.Dictionary{{Key: "hit", Value: header.Item{Value: true}}},
+			},
+		},
+		{
+			Name:    "malformed header",
+			Headers: http.Header{"Priority": {"u=1,"}},
+			ExpErr:  true,
+		},
+		{
+			Name:    "mismatched type",
+			Headers: http.Header{"Priority": {"u=high"}},
+			ExpErr:  true,
+		},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			r, _ := http.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"name": "foo"}`))
+			r.Header = http.Header{"Content-Type": {"application/json"}}
+			for k, v := range c.Headers {
+				r.Header[k] = v
+			}
+
+			j := &httpio.JSON{}
+			ingress := httpio.NewIngress(httpio.NewEgress(j), j)
+			ingress.Use(httpio.StructuredHeaders)
+
+			in := &sfvTestInput{}
+			err := ingress.Parse(r, in)
+			if c.ExpErr {
+				if !httpio.IsDecodeErr(err) {
+					t.Fatalf("expected decode error, got: %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(in, c.ExpInput) {
+				t.Fatalf("expected input %#v, got: %#v", c.ExpInput, in)
+			}
+		})
+	}
+}
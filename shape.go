@@ -0,0 +1,578 @@
+package httpio
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/advanderveer/go-httpio/header"
+)
+
+var (
+	//MediaTypeHAL identifies JSON Hypertext Application Language documents
+	MediaTypeHAL = "application/hal+json"
+
+	//MediaTypeJSONAPI identifies JSON:API documents
+	MediaTypeJSONAPI = "application/vnd.api+json"
+)
+
+//Linker can be implemented by outputs to provide links to related resources, the shaping transwares render
+//them in the format of the shape
+type Linker interface {
+	Links() []header.Link
+}
+
+//Meta can be implemented by outputs to provide information that is not part of the resource itself, e.g: the
+//total number of items. It is rendered by the envelope and JSON:API shapes
+type Meta interface {
+	Meta() map[string]interface{}
+}
+
+//Embedder can be implemented by outputs to provide related resources that are embedded in HAL documents
+type Embedder interface {
+	Embedded() map[string]interface{}
+}
+
+//Resource can be implemented by outputs to provide their JSON:API type, it defaults to the lowercased name
+//of the Go type
+type Resource interface {
+	ResourceType() string
+}
+
+//Envelope is the shape that WrapEnvelope renders outputs in, links are keyed by their relation type. The
+//client decodes the whole envelope when it is passed as the output of a request
+type Envelope struct {
+	Data  interface{}            `json:"data" xml:"data"`
+	Meta  map[string]interface{} `json:"meta,omitempty" xml:"-"`
+	Links map[string]string      `json:"links,omitempty" xml:"-"`
+}
+
+//WrapEnvelope is an Egress transware that wraps outputs in an Envelope, errors and raw content are left as
+//is just like responses that are negotiated as HAL or JSON:API documents. Use UnwrapEnvelope to decode the
+//data of such responses with the client
+func WrapEnvelope(next Transformer) Transformer {
+	return TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+		if mt := MediaTypeValue(r.Context()); !shapeable(a) || mt == MediaTypeHAL || mt == MediaTypeJSONAPI {
+			return next.Transform(a, r, w)
+		}
+
+		env := &Envelope{Data: a}
+		if m, ok := a.(Meta); ok {
+			env.Meta = m.Meta()
+		}
+
+		if l, ok := a.(Linker); ok {
+			env.Links = linkMap(l.Links())
+		}
+
+		return next.Transform(env, r, w)
+	})
+}
+
+//shapeable reports whether output 'a' is shaped by the transwares
+func shapeable(a interface{}) bool {
+	if _, isErr := a.(error); isErr {
+		return false
+	}
+
+	_, raw := rawContent(a)
+	return !raw
+}
+
+//linkMap keys the targets of links by their relation types
+func linkMap(links []header.Link) map[string]string {
+	if len(links) < 1 {
+		return nil
+	}
+
+	m := map[string]string{}
+	for _, l := range links {
+		for _, rel := range l.Rels() {
+			m[rel] = l.URL
+		}
+	}
+
+	return m
+}
+
+//UnwrapEnvelope wraps decoder factory 'f' such that the data of envelopes is decoded into the output
+func UnwrapEnvelope(f DecoderFactory) DecoderFactory { return &envelopeDecoding{f} }
+
+type envelopeDecoding struct{ DecoderFactory }
+
+func (d *envelopeDecoding) Decoder(r io.Reader) Decoder {
+	dec := d.DecoderFactory.Decoder(r)
+	return decoderFunc(func(v interface{}) error {
+		if _, ok := v.(*Envelope); ok {
+			return dec.Decode(v)
+		}
+
+		return dec.Decode(&Envelope{Data: v})
+	})
+}
+
+//decoderFunc implements the Decoder with a function
+type decoderFunc func(v interface{}) error
+
+func (f decoderFunc) Decode(v interface{}) error { return f(v) }
+
+//ShapeHAL is an Egress transware that shapes outputs as HAL documents when the response is negotiated as
+//application/hal+json, the egress needs the HAL encoder for that. Links and embedded resources are added to
+//the members of the output, slices are embedded as "items"
+func ShapeHAL(next Transformer) Transformer {
+	return TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+		if MediaTypeValue(r.Context()) != MediaTypeHAL || !shapeable(a) {
+			return next.Transform(a, r, w)
+		}
+
+		doc, err := halResource(a)
+		if err != nil {
+			return err
+		}
+
+		return next.Transform(doc, r, w)
+	})
+}
+
+func halResource(v interface{}) (interface{}, error) {
+	var doc map[string]interface{}
+	if rv := reflect.Indirect(reflect.ValueOf(v)); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			item, err := halResource(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+
+			items[i] = item
+		}
+
+		doc = map[string]interface{}{"_embedded": map[string]interface{}{"items": items}}
+	} else {
+		members, ok, err := jsonMembers(v)
+		if err != nil || !ok {
+			return v, err
+		}
+
+		doc = make(map[string]interface{}, len(members)+2)
+		for k, m := range members {
+			doc[k] = m
+		}
+	}
+
+	if l, ok := v.(Linker); ok {
+		if links := halLinks(l.Links()); links != nil {
+			doc["_links"] = links
+		}
+	}
+
+	if e, ok := v.(Embedder); ok {
+		embedded := map[string]interface{}{}
+		for rel, res := range e.Embedded() {
+			item, err := halResource(res)
+			if err != nil {
+				return nil, err
+			}
+
+			embedded[rel] = item
+		}
+
+		if len(embedded) > 0 {
+			doc["_embedded"] = embedded
+		}
+	}
+
+	return doc, nil
+}
+
+//halLinks renders links as HAL link objects keyed by relation type, the parameters of a link become members
+//of its object and relations with multiple links hold an array
+func halLinks(links []header.Link) map[string]interface{} {
+	if len(links) < 1 {
+		return nil
+	}
+
+	m := map[string]interface{}{}
+	for _, l := range links {
+		obj := map[string]string{"href": l.URL}
+		for k, v := range l.Params {
+			obj[k] = v
+		}
+
+		for _, rel := range l.Rels() {
+			switch cur := m[rel].(type) {
+			case nil:
+				m[rel] = obj
+			case map[string]string:
+				m[rel] = []map[string]string{cur, obj}
+			case []map[string]string:
+				m[rel] = append(cur, obj)
+			}
+		}
+	}
+
+	return m
+}
+
+//jsonMembers returns the members of the JSON object that 'v' encodes to, ok is false if it is not an object
+func jsonMembers(v interface{}) (members map[string]json.RawMessage, ok bool, err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if data = bytes.TrimSpace(data); len(data) < 1 || data[0] != '{' {
+		return nil, false, nil
+	}
+
+	err = json.Unmarshal(data, &members)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return members, true, nil
+}
+
+//HAL encodes the HAL documents that outputs are shaped into by ShapeHAL and decodes HAL documents into
+//their resource: embedded resources are decoded as members unless the resource holds a member of the same
+//name, embedded "items" are decoded into slices
+type HAL struct{ json *JSON }
+
+//NewHAL creates a HAL encoding factory that uses 'json' to encode and decode, nil uses the defaults
+func NewHAL(json *JSON) *HAL { return &HAL{json} }
+
+func (e *HAL) base() *JSON {
+	if e.json == nil {
+		return &JSON{}
+	}
+
+	return e.json
+}
+
+//MimeType will report the EncodingMimeType
+func (e *HAL) MimeType() string { return MediaTypeHAL }
+
+//Encoder will create encoders
+func (e *HAL) Encoder(w io.Writer) Encoder { return e.base().Encoder(w) }
+
+//Decoder will create decoders
+func (e *HAL) Decoder(r io.Reader) Decoder {
+	return decoderFunc(func(v interface{}) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+
+		data, err = halUnwrap(data, isSliceOutput(v))
+		if err != nil {
+			return err
+		}
+
+		return e.base().Decoder(bytes.NewReader(data)).Decode(v)
+	})
+}
+
+func halUnwrap(data []byte, list bool) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return data, nil //not a document, decoded as is
+	}
+
+	var embedded map[string]json.RawMessage
+	if raw, ok := doc["_embedded"]; ok {
+		err = json.Unmarshal(raw, &embedded)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if list {
+		var items []json.RawMessage
+		err = json.Unmarshal(embedded["items"], &items)
+		if err != nil || items == nil {
+			return []byte("[]"), err
+		}
+
+		for i, item := range items {
+			items[i], err = halUnwrap(item, false)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return json.Marshal(items)
+	}
+
+	delete(doc, "_links")
+	delete(doc, "_embedded")
+	for rel, res := range embedded {
+		if _, ok := doc[rel]; !ok {
+			doc[rel], err = halUnwrap(res, false)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+//isSliceOutput reports whether 'v' is a pointer to a slice
+func isSliceOutput(v interface{}) bool {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8
+}
+
+//ShapeJSONAPI is an Egress transware that shapes outputs as JSON:API documents when the response is
+//negotiated as application/vnd.api+json, the egress needs the JSONAPI encoder for that. The "id" member of
+//the output becomes the resource id, the other members its attributes. Links of the output become links of
+//the resource, or of the document for slices, and meta becomes meta of the document. Errors are rendered
+//as a document with a single error object
+func ShapeJSONAPI(next Transformer) Transformer {
+	return TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+		if MediaTypeValue(r.Context()) != MediaTypeJSONAPI {
+			return next.Transform(a, r, w)
+		}
+
+		if err, isErr := a.(error); isErr {
+			obj := map[string]string{"detail": err.Error()}
+			status := StatusValue(r.Context())
+			var sc StatusCoder
+			if status == 0 && errors.As(err, &sc) {
+				status = sc.StatusCode()
+			}
+
+			if status != 0 {
+				obj["status"] = strconv.Itoa(status)
+				r = r.WithContext(WithStatus(r.Context(), status)) //the document is no longer an error
+			}
+
+			return next.Transform(map[string]interface{}{"errors": []interface{}{obj}}, r, w)
+		}
+
+		if _, raw := rawContent(a); raw {
+			return next.Transform(a, r, w)
+		}
+
+		doc := map[string]interface{}{}
+		if rv := reflect.Indirect(reflect.ValueOf(a)); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			data := make([]interface{}, rv.Len())
+			for i := range data {
+				res, err := jsonAPIResource(rv.Index(i).Interface())
+				if err != nil {
+					return err
+				}
+
+				data[i] = res
+			}
+
+			doc["data"] = data
+			if l, ok := a.(Linker); ok && len(l.Links()) > 0 {
+				doc["links"] = linkMap(l.Links())
+			}
+		} else {
+			res, err := jsonAPIResource(a)
+			if err != nil {
+				return err
+			}
+
+			doc["data"] = res
+		}
+
+		if m, ok := a.(Meta); ok && len(m.Meta()) > 0 {
+			doc["meta"] = m.Meta()
+		}
+
+		return next.Transform(doc, r, w)
+	})
+}
+
+func jsonAPIResource(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	members, ok, err := jsonMembers(v)
+	if err != nil || !ok {
+		return v, err
+	}
+
+	res := map[string]interface{}{"type": resourceType(v)}
+	if id, ok := members["id"]; ok {
+		var s string
+		if json.Unmarshal(id, &s) != nil {
+			s = string(id) //ids are strings, numbers are kept as they are written
+		}
+
+		res["id"] = s
+		delete(members, "id")
+	}
+
+	if len(members) > 0 {
+		res["attributes"] = members
+	}
+
+	if l, ok := v.(Linker); ok && len(l.Links()) > 0 {
+		res["links"] = linkMap(l.Links())
+	}
+
+	return res, nil
+}
+
+func resourceType(v interface{}) string {
+	if res, ok := v.(Resource); ok {
+		return res.ResourceType()
+	}
+
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return strings.ToLower(t.Name())
+}
+
+//JSONAPI encodes the JSON:API documents that outputs are shaped into by ShapeJSONAPI and decodes JSON:API
+//documents into their resource(s): the attributes and id are decoded as members, the id as a number if
+//the output has a numeric "id" field. Error documents are decoded from their first error object, its
+//detail is also decoded as the "message" member
+type JSONAPI struct{ json *JSON }
+
+//NewJSONAPI creates a JSON:API encoding factory that uses 'json' to encode and decode, nil uses the defaults
+func NewJSONAPI(json *JSON) *JSONAPI { return &JSONAPI{json} }
+
+func (e *JSONAPI) base() *JSON {
+	if e.json == nil {
+		return &JSON{}
+	}
+
+	return e.json
+}
+
+//MimeType will report the EncodingMimeType
+func (e *JSONAPI) MimeType() string { return MediaTypeJSONAPI }
+
+//ContentType is sent without parameters, as JSON:API requires
+func (e *JSONAPI) ContentType() string { return MediaTypeJSONAPI }
+
+//Encoder will create encoders
+func (e *JSONAPI) Encoder(w io.Writer) Encoder { return e.base().Encoder(w) }
+
+//Decoder will create decoders
+func (e *JSONAPI) Decoder(r io.Reader) Decoder {
+	return decoderFunc(func(v interface{}) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+
+		data, err = jsonAPIUnwrap(data, numericID(reflect.TypeOf(v)))
+		if err != nil {
+			return err
+		}
+
+		return e.base().Decoder(bytes.NewReader(data)).Decode(v)
+	})
+}
+
+func jsonAPIUnwrap(data []byte, numeric bool) ([]byte, error) {
+	var doc struct {
+		Data   json.RawMessage              `json:"data"`
+		Errors []map[string]json.RawMessage `json:"errors"`
+	}
+
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return data, nil //not a document, decoded as is
+	}
+
+	if doc.Data == nil && len(doc.Errors) > 0 {
+		obj := doc.Errors[0]
+		if _, ok := obj["message"]; !ok && obj["detail"] != nil {
+			obj["message"] = obj["detail"]
+		}
+
+		return json.Marshal(obj)
+	}
+
+	if data = bytes.TrimSpace(doc.Data); len(data) > 0 && data[0] == '[' {
+		var list []json.RawMessage
+		err = json.Unmarshal(data, &list)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, res := range list {
+			list[i], err = jsonAPIFlatten(res, numeric)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return json.Marshal(list)
+	}
+
+	return jsonAPIFlatten(data, numeric)
+}
+
+//jsonAPIFlatten turns a resource object into the object of its id and attributes
+func jsonAPIFlatten(data []byte, numeric bool) ([]byte, error) {
+	var res struct {
+		ID         *string                    `json:"id"`
+		Attributes map[string]json.RawMessage `json:"attributes"`
+	}
+
+	err := json.Unmarshal(data, &res)
+	if err != nil || bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return data, err
+	}
+
+	obj := res.Attributes
+	if obj == nil {
+		obj = map[string]json.RawMessage{}
+	}
+
+	if res.ID != nil {
+		obj["id"], _ = json.Marshal(*res.ID)
+		if _, err := strconv.ParseFloat(*res.ID, 64); err == nil && numeric {
+			obj["id"] = json.RawMessage(*res.ID)
+		}
+	}
+
+	return json.Marshal(obj)
+}
+
+//numericID reports whether the (items of the) output of type 't' have a numeric "id" field
+func numericID(t reflect.Type) bool {
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for _, f := range binaryFields(t) {
+		if f.name != "id" {
+			continue
+		}
+
+		switch t.FieldByIndex(f.index).Type.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8,
+			reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,125 @@
+package httpio_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+	"github.com/advanderveer/go-httpio/header"
+)
+
+type shapeTestUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func (u *shapeTestUser) Links() []header.Link {
+	return []header.Link{{URL: "/users/" + strconv.Itoa(u.ID), Rel: "self"}}
+}
+
+type shapeTestUsers []*shapeTestUser
+
+func (l shapeTestUsers) Links() []header.Link {
+	return []header.Link{{URL: "/users?page=2", Rel: "next"}}
+}
+
+func (l shapeTestUsers) Meta() map[string]interface{} { return map[string]interface{}{"total": 2} }
+
+func shapeTestHandler(e *httpio.Egress) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user":
+			e.MustRender(&shapeTestUser{1, "a"}, w, r)
+		case "/users":
+			e.MustRender(shapeTestUsers{{1, "a"}, {2, "b"}}, w, r)
+		default:
+			e.MustRender(&httpio.FieldsError{Field: "x"}, w, r)
+		}
+	})
+}
+
+func TestShapes(t *testing.T) {
+	e := httpio.NewEgress(&httpio.JSON{}, httpio.NewHAL(nil), httpio.NewJSONAPI(nil))
+	e.Use(httpio.ShapeHAL, httpio.ShapeJSONAPI)
+	env := httpio.NewEgress(&httpio.JSON{})
+	env.Use(httpio.WrapEnvelope)
+
+	for _, c := range []struct {
+		Name    string
+		Egress  *httpio.Egress
+		Path    string
+		Accept  string
+		ExpCode int
+		ExpType string
+		ExpBody string
+	}{
+		{"bare", e, "/user", "application/json", http.StatusOK, "application/json; charset=utf-8", `{"id":1,"name":"a"}`},
+		{"hal", e, "/user", "application/hal+json", http.StatusOK, "application/hal+json; charset=utf-8",
+			`{"_links":{"self":{"href":"/users/1"}},"id":1,"name":"a"}`},
+		{"hal list", e, "/users", "application/hal+json", http.StatusOK, "application/hal+json; charset=utf-8",
+			`{"_embedded":{"items":[{"_links":{"self":{"href":"/users/1"}},"id":1,"name":"a"},{"_links":{"self":{"href":"/users/2"}},"id":2,"name":"b"}]},"_links":{"next":{"href":"/users?page=2"}}}`},
+		{"json:api", e, "/user", "application/vnd.api+json", http.StatusOK, "application/vnd.api+json",
+			`{"data":{"attributes":{"name":"a"},"id":"1","links":{"self":"/users/1"},"type":"shapetestuser"}}`},
+		{"json:api list", e, "/users", "application/vnd.api+json", http.StatusOK, "application/vnd.api+json",
+			`{"data":[{"attributes":{"name":"a"},"id":"1","links":{"self":"/users/1"},"type":"shapetestuser"},{"attributes":{"name":"b"},"id":"2","links":{"self":"/users/2"},"type":"shapetestuser"}],"links":{"next":"/users?page=2"},"meta":{"total":2}}`},
+		{"json:api error", e, "/fail", "application/vnd.api+json", http.StatusBadRequest, "application/vnd.api+json",
+			`{"errors":[{"detail":"httpio/fields: unknown field 'x'","status":"400"}]}`},
+		{"envelope", env, "/users", "application/json", http.StatusOK, "application/json; charset=utf-8",
+			`{"data":[{"id":1,"name":"a"},{"id":2,"name":"b"}],"meta":{"total":2},"links":{"next":"/users?page=2"}}`},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, c.Path, nil)
+			r.Header.Set("Accept", c.Accept)
+			w := httptest.NewRecorder()
+			shapeTestHandler(c.Egress).ServeHTTP(w, r)
+			if body := strings.TrimSpace(w.Body.String()); w.Code != c.ExpCode || body != c.ExpBody || w.Header().Get("Content-Type") != c.ExpType {
+				t.Fatalf("expected %d %s %s, got: %d %s %s", c.ExpCode, c.ExpType, c.ExpBody, w.Code, w.Header().Get("Content-Type"), body)
+			}
+		})
+	}
+}
+
+func TestShapesClient(t *testing.T) {
+	e := httpio.NewEgress(&httpio.JSON{}, httpio.NewHAL(nil), httpio.NewJSONAPI(nil))
+	e.Use(httpio.ShapeHAL, httpio.ShapeJSONAPI, httpio.WrapEnvelope)
+	svr := httptest.NewServer(shapeTestHandler(e))
+	defer svr.Close()
+
+	c, err := httpio.NewClient(svr.Client(), svr.URL, &httpio.JSON{}, httpio.UnwrapEnvelope(&httpio.JSON{}), httpio.NewHAL(nil), httpio.NewJSONAPI(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []*shapeTestUser{{1, "a"}, {2, "b"}}
+	for _, accept := range []string{"application/json", "application/hal+json", "application/vnd.api+json"} {
+		t.Run(accept, func(t *testing.T) {
+			user := &shapeTestUser{}
+			err := c.Request(context.Background(), http.MethodGet, "/user", http.Header{"Accept": {accept}}, nil, user)
+			if err != nil || !reflect.DeepEqual(user, exp[0]) {
+				t.Fatalf("expected %+v, got: %+v %v", exp[0], user, err)
+			}
+
+			var users []*shapeTestUser
+			err = c.Request(context.Background(), http.MethodGet, "/users", http.Header{"Accept": {accept}}, nil, &users)
+			if err != nil || !reflect.DeepEqual(users, exp) {
+				t.Fatalf("expected %+v, got: %+v %v", exp, users, err)
+			}
+		})
+	}
+
+	err = c.Request(context.Background(), http.MethodGet, "/fail", http.Header{"Accept": {"application/vnd.api+json"}}, nil, nil)
+	if exp := "httpio/fields: unknown field 'x'"; err == nil || err.Error() != exp {
+		t.Fatalf("expected error '%s', got: %v", exp, err)
+	}
+
+	out := &httpio.Envelope{}
+	err = c.Request(context.Background(), http.MethodGet, "/users", http.Header{"Accept": {"application/json"}}, nil, out)
+	if exp := map[string]string{"next": "/users?page=2"}; err != nil || !reflect.DeepEqual(out.Links, exp) || len(out.Data.([]interface{})) != 2 {
+		t.Fatalf("expected envelope with links %v, got: %+v %v", exp, out, err)
+	}
+}
@@ -0,0 +1,141 @@
+//Package toml provides an encoding factory for TOML documents, it is a separate package such that the core
+//remains free of dependencies
+package toml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	httpio "github.com/advanderveer/go-httpio"
+	gotoml "github.com/pelletier/go-toml/v2"
+)
+
+var (
+	//MediaTypeTOML identifies TOML content
+	MediaTypeTOML = "application/toml"
+)
+
+//TOML allows encoding and decoding into TOML. Values are mapped through their JSON representation such
+//that the names in 'json' tags, json.Marshaler and json.Unmarshaler are honoured and the same structs can
+//be used for both formats. Duplicate keys are always rejected, as required by the TOML specification. A
+//document is a table so only values that encode to JSON objects can be encoded, null values are left out
+//as TOML cannot represent them
+type TOML struct {
+	disallowUnknown bool
+}
+
+//Option configures the TOML encoding
+type Option func(t *TOML)
+
+//DisallowUnknownFields causes decoding to fail on keys that do not match a field of the destination
+func DisallowUnknownFields() Option { return func(t *TOML) { t.disallowUnknown = true } }
+
+//Strict combines all options that reject input that would otherwise be accepted silently
+func Strict() Option { return func(t *TOML) { t.disallowUnknown = true } }
+
+//New creates a TOML encoding with the provided options
+func New(opts ...Option) *TOML {
+	t := &TOML{}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+//MimeType will report the EncodingMimeType
+func (e *TOML) MimeType() string { return MediaTypeTOML }
+
+//ContentType is sent without a charset, TOML documents are always UTF-8
+func (e *TOML) ContentType() string { return MediaTypeTOML }
+
+//Encoder will create encoders
+func (e *TOML) Encoder(w io.Writer) httpio.Encoder { return &encoder{w} }
+
+//Decoder will create decoders, a document is read until the end so only one value can be decoded
+func (e *TOML) Decoder(r io.Reader) httpio.Decoder { return &decoder{e, r} }
+
+type encoder struct{ w io.Writer }
+
+func (e *encoder) Encode(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	err = dec.Decode(&doc)
+	if err != nil {
+		return err
+	}
+
+	table, ok := tomlValue(doc).(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("httpio/toml: cannot encode %T, it does not encode to a table", v)
+	}
+
+	data, err = gotoml.Marshal(table)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+//tomlValue converts JSON numbers into integers where possible and leaves out null values
+func tomlValue(v interface{}) interface{} {
+	switch vt := v.(type) {
+	case json.Number:
+		if i, err := vt.Int64(); err == nil {
+			return i
+		}
+
+		f, _ := vt.Float64()
+		return f
+	case map[string]interface{}:
+		for k, el := range vt {
+			if el == nil {
+				delete(vt, k)
+				continue
+			}
+
+			vt[k] = tomlValue(el)
+		}
+	case []interface{}:
+		for i, el := range vt {
+			vt[i] = tomlValue(el)
+		}
+	}
+
+	return v
+}
+
+type decoder struct {
+	cfg *TOML
+	r   io.Reader
+}
+
+func (d *decoder) Decode(v interface{}) error {
+	var doc map[string]interface{}
+	err := gotoml.NewDecoder(d.r).Decode(&doc)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("httpio/toml: %v", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if d.cfg.disallowUnknown {
+		dec.DisallowUnknownFields()
+	}
+
+	return dec.Decode(v)
+}
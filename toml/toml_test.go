@@ -0,0 +1,114 @@
+package toml_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+	"github.com/advanderveer/go-httpio/toml"
+)
+
+type config struct {
+	Name     string            `json:"name"`
+	Replicas int               `json:"replicas,omitempty"`
+	Ratio    float64           `json:"ratio"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Ports    []int             `json:"ports"`
+	Parent   *config           `json:"parent,omitempty"`
+}
+
+func TestDecode(t *testing.T) {
+	for _, c := range []struct {
+		Name   string
+		Opts   []toml.Option
+		Input  string
+		Exp    *config
+		ExpErr string
+	}{
+		{
+			Name:  "json names",
+			Input: "name = 'web'\nreplicas = 3\nratio = 0.5\nports = [80, 443]\nunknown = 1\n[labels]\ntier = 'front'\n[parent]\nname = 'base'\n",
+			Exp:   &config{Name: "web", Replicas: 3, Ratio: 0.5, Ports: []int{80, 443}, Labels: map[string]string{"tier": "front"}, Parent: &config{Name: "base"}},
+		},
+		{
+			Name:   "unknown field",
+			Opts:   []toml.Option{toml.Strict()},
+			Input:  "name = 'web'\nunknown = 1\n",
+			ExpErr: `json: unknown field "unknown"`,
+		},
+		{
+			Name:   "duplicate key",
+			Input:  "name = 'web'\nname = 'api'\n",
+			ExpErr: "toml: key name is already defined",
+		},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			v := &config{}
+			err := toml.New(c.Opts...).Decoder(strings.NewReader(c.Input)).Decode(v)
+			if c.ExpErr != "" {
+				if err == nil || err.Error() != c.ExpErr {
+					t.Fatalf("expected error '%s', got: %v", c.ExpErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(v, c.Exp) {
+				t.Fatalf("expected %+v, got: %+v", c.Exp, v)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	err := toml.New().Encoder(buf).Encode(&config{Name: "web", Ratio: 2, Ports: []int{80}, Parent: &config{Name: "base"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := "name = 'web'\nports = [80]\nratio = 2\n\n[parent]\nname = 'base'\nratio = 0\n"
+	if buf.String() != exp {
+		t.Fatalf("expected:\n%s\ngot:\n%s", exp, buf.String())
+	}
+
+	v := &config{}
+	err = toml.New().Decoder(buf).Decode(v)
+	if err != nil || v.Name != "web" || v.Ratio != 2 || v.Parent.Name != "base" || v.Parent.Ports != nil {
+		t.Fatalf("expected encoding to roundtrip, got: %+v %v", v, err)
+	}
+
+	err = toml.New().Encoder(buf).Encode([]int{1})
+	if exp := "httpio/toml: cannot encode []int, it does not encode to a table"; err == nil || err.Error() != exp {
+		t.Fatalf("expected error '%s', got: %v", exp, err)
+	}
+}
+
+func TestNegotiation(t *testing.T) {
+	tm := toml.New(toml.Strict())
+	e := httpio.NewEgress(&httpio.JSON{}, tm)
+	i := httpio.NewIngress(e, &httpio.JSON{}, tm)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &config{}
+		if render, ok := i.Handle(w, r, in); ok {
+			render(in, nil)
+		}
+	})
+
+	r := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("name = 'web'\n"))
+	r.Header.Set("Content-Type", "application/toml")
+	r.Header.Set("Accept", "application/toml")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if exp := "name = 'web'\nratio = 0\n"; w.Body.String() != exp || w.Header().Get("Content-Type") != "application/toml" {
+		t.Fatalf("expected %q, got: %s %q", exp, w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
@@ -0,0 +1,117 @@
+package httpio
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/advanderveer/go-httpio/jsonschema"
+)
+
+//ValidateSchema returns an Ingress transware that validates JSON request bodies against the schema of 'v'
+//before they are decoded into the input. It must be installed using UseRaw. Violations are returned as a
+//decode error that wraps a *jsonschema.ValidationError, use errors.As to access the individual violations.
+func ValidateSchema(v *jsonschema.Validator) Transware {
+	return func(next Transformer) Transformer {
+		return TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+			r, err := validateBody(r, func() (*jsonschema.Validator, error) { return v, nil })
+			if err != nil {
+				return err
+			}
+
+			return next.Transform(a, r, w)
+		})
+	}
+}
+
+//MaxBufferedBodySize limits how much of a request body is buffered by the transwares that need to read it
+//as a whole, such as the validators and ApplyPatch. Routes can lower it further with RouteMaxBodySize
+var MaxBufferedBodySize int64 = 10 << 20
+
+var inputValidators sync.Map
+
+//ValidateInput is an Ingress transware like ValidateSchema but it validates against the schema that is
+//generated from the type of the input, fields that are bound from parameters are left out. It must be
+//installed using UseRaw.
+func ValidateInput(next Transformer) Transformer {
+	return TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+		r, err := validateBody(r, func() (*jsonschema.Validator, error) { return inputValidator(reflect.TypeOf(a)) })
+		if err != nil {
+			return err
+		}
+
+		return next.Transform(a, r, w)
+	})
+}
+
+func inputValidator(t reflect.Type) (*jsonschema.Validator, error) {
+	if v, ok := inputValidators.Load(t); ok {
+		return v.(*jsonschema.Validator), nil
+	}
+
+	key := t
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	g := jsonschema.NewGenerator("#/$defs/")
+	g.SkipField = IsParamField
+	s := g.Generate(t)
+	if g.Err() != nil {
+		return nil, g.Err()
+	}
+
+	if len(g.Defs) > 0 {
+		s.Defs = g.Defs
+	}
+
+	v, err := jsonschema.NewValidator(s)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Request = true
+	inputValidators.Store(key, v)
+	return v, nil
+}
+
+//validateBody buffers the body of JSON requests to validate it, the returned request can be read again
+func validateBody(r *http.Request, validator func() (*jsonschema.Validator, error)) (*http.Request, error) {
+	if r.Body == nil || r.ContentLength == 0 {
+		return r, nil
+	}
+
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mt != MediaTypeJSON && !strings.HasSuffix(mt, "+json") {
+		return r, nil
+	}
+
+	v, err := validator()
+	if err != nil {
+		return r, err
+	}
+
+	data, err := readBody(r)
+	if err != nil {
+		return r, decodeErr{err}
+	}
+
+	r = r.WithContext(r.Context())
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	err = v.ValidateJSON(data)
+	if err != nil {
+		return r, decodeErr{err}
+	}
+
+	return r, nil
+}
+
+//readBody reads and closes the request body, it fails when the body exceeds the MaxBufferedBodySize
+func readBody(r *http.Request) ([]byte, error) {
+	defer r.Body.Close()
+	return io.ReadAll(http.MaxBytesReader(nil, r.Body, MaxBufferedBodySize))
+}
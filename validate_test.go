@@ -0,0 +1,131 @@
+package httpio_test
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+	"github.com/advanderveer/go-httpio/jsonschema"
+)
+
+type validateTestInput struct {
+	ID    string   `path:"id"`
+	Name  string   `json:"name" jsonschema:"minLength=2"`
+	Email string   `json:"email,omitempty" jsonschema:"format=email"`
+	Tags  []string `json:"tags,omitempty" jsonschema:"maxItems=1"`
+}
+
+func TestValidateInput(t *testing.T) {
+	s := &jsonschema.Schema{Type: jsonschema.Types{"object"}, Required: []string{"email"}}
+	v, err := jsonschema.NewValidator(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		Name          string
+		Ware          httpio.Transware
+		ContentType   string
+		Body          string
+		ExpInput      *validateTestInput
+		ExpErr        string
+		ExpViolations []jsonschema.Violation
+	}{
+		{
+			Name:        "valid",
+			Ware:        httpio.ValidateInput,
+			ContentType: "application/json",
+			Body:        `{"name":"foo","tags":["a"]}`,
+			ExpInput:    &validateTestInput{Name: "foo", Tags: []string{"a"}},
+		},
+		{
+			Name:        "violations",
+			Ware:        httpio.ValidateInput,
+			ContentType: "application/json; charset=utf-8",
+			Body:        `{"email":"not an email","tags":["a","b"]}`,
+			ExpErr:      "jsonschema: (root): missing required property 'name'; /email: must be a valid email; /tags: must have at most 1 items",
+			ExpViolations: []jsonschema.Violation{
+				{Path: "", Keyword: "required", Message: "missing required property 'name'"},
+				{Path: "/email", Keyword: "format", Message: "must be a valid email"},
+				{Path: "/tags", Keyword: "maxItems", Message: "must have at most 1 items"},
+			},
+		},
+		{
+			Name:        "syntax error",
+			Ware:        httpio.ValidateInput,
+			ContentType: "application/json",
+			Body:        `{"name":`,
+			ExpErr:      "unexpected EOF",
+		},
+		{
+			Name:        "not json",
+			Ware:        httpio.ValidateInput,
+			ContentType: "application/x-www-form-urlencoded",
+			Body:        `name=a`,
+			ExpErr:      "httpio/ingress: unspported content type 'application/x-www-form-urlencoded'",
+		},
+		{
+			Name:        "explicit schema",
+			Ware:        httpio.ValidateSchema(v),
+			ContentType: "application/problem+json",
+			Body:        `{"name":"foo"}`,
+			ExpErr:      "jsonschema: (root): missing required property 'email'",
+			ExpViolations: []jsonschema.Violation{
+				{Path: "", Keyword: "required", Message: "missing required property 'email'"},
+			},
+		},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			j := &httpio.JSON{}
+			i := httpio.NewIngress(httpio.NewEgress(j), j)
+			i.UseRaw(c.Ware)
+
+			r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(c.Body))
+			r.Header.Set("Content-Type", c.ContentType)
+			in := &validateTestInput{}
+			err := i.Parse(r, in)
+			if c.ExpErr != "" {
+				if err == nil || err.Error() != c.ExpErr {
+					t.Fatalf("expected error '%s', got: %v", c.ExpErr, err)
+				}
+
+				var verr *jsonschema.ValidationError
+				if errors.As(err, &verr) && !reflect.DeepEqual(verr.Violations, c.ExpViolations) {
+					t.Fatalf("expected violations %v, got: %v", c.ExpViolations, verr.Violations)
+				}
+
+				if c.ExpViolations != nil && !httpio.IsDecodeErr(err) {
+					t.Fatalf("expected a decode error, got: %T", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(in, c.ExpInput) {
+				t.Fatalf("expected input %+v, got: %+v", c.ExpInput, in)
+			}
+		})
+	}
+	t.Run("body too large", func(t *testing.T) {
+		defer func(n int64) { httpio.MaxBufferedBodySize = n }(httpio.MaxBufferedBodySize)
+		httpio.MaxBufferedBodySize = 8
+
+		j := &httpio.JSON{}
+		i := httpio.NewIngress(httpio.NewEgress(j), j)
+		i.UseRaw(httpio.ValidateInput)
+
+		r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"foobar"}`))
+		r.Header.Set("Content-Type", "application/json")
+		err := i.Parse(r, &validateTestInput{})
+		if merr := (*http.MaxBytesError)(nil); !errors.As(err, &merr) || !httpio.IsDecodeErr(err) {
+			t.Fatalf("expected a max bytes decode error, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package httpio
+
+import (
+	"fmt"
+	"slices"
+)
+
+//RouteOption configures the view of the stacks that is used for a single route, see Ingress.With
+type RouteOption func(i *Ingress)
+
+//With returns a view of the ingress, and the egress it renders with, for a single route. The options can
+//restrict or extend the encodings, add transwares, limit the body size or set the default status while the
+//view shares everything else with the stacks it is created from. The stacks are not changed, nor does the
+//view see transwares that are added to them later, so it is best created at init time
+func (i *Ingress) With(opts ...RouteOption) *Ingress {
+	e := *i.egress
+	v := *i
+	v.egress = &e
+	for _, opt := range opts {
+		opt(&v)
+	}
+
+	v.index, e.index = v.decoders.index(), e.encoders.index()
+	return &v
+}
+
+//RouteAccept restricts the request bodies that are decoded to the media types, in order of preference. It
+//panics if the ingress has no decoder for one of them
+func RouteAccept(mediaTypes ...string) RouteOption {
+	return func(i *Ingress) {
+		decs := make(DecoderList, 0, len(mediaTypes))
+		for _, mt := range mediaTypes {
+			dec := i.decoders.Find(mt)
+			if dec == nil {
+				panic(fmt.Sprintf("httpio/route: cannot accept '%s', the ingress has no decoder for it", mt))
+			}
+
+			decs = append(decs, dec)
+		}
+
+		i.decoders = decs
+	}
+}
+
+//RouteProduce restricts the responses that are encoded to the media types, the first is the default. It
+//panics if the egress has no encoder for one of them
+func RouteProduce(mediaTypes ...string) RouteOption {
+	return func(i *Ingress) {
+		encs := make(EncoderList, 0, len(mediaTypes))
+		for _, mt := range mediaTypes {
+			enc := i.egress.encoders.Find(mt)
+			if enc == nil {
+				panic(fmt.Sprintf("httpio/route: cannot produce '%s', the egress has no encoder for it", mt))
+			}
+
+			encs = append(encs, enc)
+		}
+
+		i.egress.encoders = encs
+	}
+}
+
+//RouteDecoders adds decoders to the route, they replace decoders of the same media type and are otherwise
+//the least preferred
+func RouteDecoders(decs ...DecoderFactory) RouteOption {
+	return func(i *Ingress) {
+		list := append(DecoderList{}, i.decoders...)
+	outer:
+		for _, dec := range decs {
+			for j := range list {
+				if list[j].MimeType() == dec.MimeType() {
+					list[j] = dec
+					continue outer
+				}
+			}
+
+			list = append(list, dec)
+		}
+
+		i.decoders = list
+	}
+}
+
+//RouteEncoders adds encoders to the route, they replace encoders of the same media type and are otherwise
+//the least preferred
+func RouteEncoders(encs ...EncoderFactory) RouteOption {
+	return func(i *Ingress) {
+		list := append(EncoderList{}, i.egress.encoders...)
+	outer:
+		for _, enc := range encs {
+			for j := range list {
+				if list[j].MimeType() == enc.MimeType() {
+					list[j] = enc
+					continue outer
+				}
+			}
+
+			list = append(list, enc)
+		}
+
+		i.egress.encoders = list
+	}
+}
+
+//RouteUse appends the transware(s) to the parse chain of the route
+func RouteUse(wares ...Transware) RouteOption {
+	return func(i *Ingress) { i.wares = slices.Concat(i.wares, wares) }
+}
+
+//RouteUseRaw appends the transware(s) to the part of the parse chain of the route that runs before the
+//request body is decoded, see Ingress.UseRaw
+func RouteUseRaw(wares ...Transware) RouteOption {
+	return func(i *Ingress) { i.raw = slices.Concat(i.raw, wares) }
+}
+
+//RouteUseRender appends the transware(s) to the render chain of the route
+func RouteUseRender(wares ...Transware) RouteOption {
+	return func(i *Ingress) { i.egress.wares = slices.Concat(i.egress.wares, wares) }
+}
+
+//RouteMaxBodySize causes request bodies larger than 'n' bytes to be rejected with a decode error that
+//wraps a *http.MaxBytesError
+func RouteMaxBodySize(n int64) RouteOption { return func(i *Ingress) { i.maxBody = n } }
+
+//RouteStatus sets the status that outputs are rendered with when none is set with WithStatus, e.g: 201 for
+//a route that creates resources. It does not apply to outputs that are errors
+func RouteStatus(code int) RouteOption { return func(i *Ingress) { i.egress.status = code } }
@@ -0,0 +1,113 @@
+package httpio_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+type viewTestInput struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func viewTestWare(trace *[]string, name string) httpio.Transware {
+	return func(next httpio.Transformer) httpio.Transformer {
+		return httpio.TransFunc(func(a interface{}, r *http.Request, w http.ResponseWriter) error {
+			*trace = append(*trace, name)
+			return next.Transform(a, r, w)
+		})
+	}
+}
+
+func TestIngressWith(t *testing.T) {
+	var trace []string
+	e := httpio.NewEgress(&httpio.JSON{}, &httpio.XML{})
+	e.Use(viewTestWare(&trace, "render"))
+	i := httpio.NewIngress(e, &httpio.JSON{}, &httpio.XML{})
+	i.Use(viewTestWare(&trace, "parse"))
+
+	view := i.With(
+		httpio.RouteAccept("application/json"),
+		httpio.RouteProduce("application/xml"),
+		httpio.RouteUse(viewTestWare(&trace, "route parse")),
+		httpio.RouteUseRender(viewTestWare(&trace, "route render")),
+		httpio.RouteMaxBodySize(16),
+		httpio.RouteStatus(http.StatusCreated))
+
+	for _, c := range []struct {
+		Name     string
+		Ingress  *httpio.Ingress
+		Type     string
+		Body     string
+		ExpCode  int
+		ExpBody  string
+		ExpTrace string
+	}{
+		{"stack", i, "application/xml", `<x><name>a</name></x>`, http.StatusOK, `{"name":"a"}` + "\n", "parse,render"},
+		{"route", view, "application/json", `{"name":"a"}`, http.StatusCreated, `<viewTestInput><name>a</name></viewTestInput>`, "parse,route parse,render,route render"},
+		{"route rejects type", view, "application/xml", `<x/>`, http.StatusOK, `<error><message>httpio/ingress: unspported content type &#39;application/xml&#39;</message></error>`, "render,route render"},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			trace = nil
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(c.Body))
+			r.Header.Set("Content-Type", c.Type)
+			w := httptest.NewRecorder()
+			in := &viewTestInput{}
+			if render, ok := c.Ingress.Handle(w, r, in); ok {
+				render(in, nil)
+			}
+
+			if w.Code != c.ExpCode || w.Body.String() != c.ExpBody || strings.Join(trace, ",") != c.ExpTrace {
+				t.Fatalf("expected %d %q %s, got: %d %q %v", c.ExpCode, c.ExpBody, c.ExpTrace, w.Code, w.Body.String(), trace)
+			}
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"too long for the route"}`))
+	r.Header.Set("Content-Type", "application/json")
+	var mberr *http.MaxBytesError
+	if err := view.Parse(r, &viewTestInput{}); !httpio.IsDecodeErr(err) || !errors.As(err, &mberr) || mberr.Limit != 16 {
+		t.Fatalf("expected max bytes decode error, got: %v", err)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"too long for the route"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.ContentLength = -1
+	if err := view.Parse(r, &viewTestInput{}); !errors.As(err, &mberr) {
+		t.Fatalf("expected max bytes error while reading, got: %v", err)
+	}
+}
+
+func TestIngressWithExtend(t *testing.T) {
+	i := httpio.NewIngress(httpio.NewEgress(&httpio.JSON{}), &httpio.JSON{})
+	view := i.With(httpio.RouteEncoders(httpio.NewJSON(httpio.JSONIndent("", " ")), &httpio.XML{}), httpio.RouteDecoders(&httpio.XML{}))
+	if got := strings.Join(view.Egress().Encoders().Supported(), ","); got != "application/json,application/xml" {
+		t.Fatalf("unexpected encoders: %s", got)
+	}
+
+	if got := strings.Join(view.Decoders().Supported(), ","); got != "application/json,application/xml" {
+		t.Fatalf("unexpected decoders: %s", got)
+	}
+
+	if len(i.Decoders()) != 1 || len(i.Egress().Encoders()) != 1 {
+		t.Fatal("expected the stacks to be left as is")
+	}
+
+	w := httptest.NewRecorder()
+	view.Egress().MustRender(&viewTestInput{"a"}, w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if exp := "{\n \"name\": \"a\"\n}\n"; w.Body.String() != exp {
+		t.Fatalf("expected replaced encoder to render %q, got: %q", exp, w.Body.String())
+	}
+
+	defer func() {
+		if r := recover(); r != "httpio/route: cannot produce 'text/csv', the egress has no encoder for it" {
+			t.Fatalf("expected panic, got: %v", r)
+		}
+	}()
+
+	i.With(httpio.RouteProduce("text/csv"))
+}
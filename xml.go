@@ -1,8 +1,14 @@
 package httpio
 
 import (
+	"bytes"
 	"encoding/xml"
+	"fmt"
 	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
 )
 
 var (
@@ -10,14 +16,291 @@ var (
 	MediaTypeXML = "application/xml"
 )
 
-//XML allows encode and decode into JSOn
-type XML struct{}
+//XML allows encode and decode into XML. Slices, maps, anonymous structs and basic values are wrapped in a
+//root element, their items are named after their type if it is a named struct or else "item". Errors that
+//hold no exported fields are encoded as <error><message>...</message></error>. Maps are only supported
+//as outputs or inside other maps and slices, not as struct fields. Their entries are named after their keys,
+//keys that are not valid element names are encoded as <entry key="...">. The zero value writes no
+//declaration, use NewXML to configure the output
+type XML struct {
+	header         bool
+	root           string
+	namespace      string
+	prefix, indent string
+
+	lenient       bool
+	charsetReader func(charset string, input io.Reader) (io.Reader, error)
+}
+
+//XMLOption configures the XML encoding
+type XMLOption func(x *XML)
+
+//XMLHeader causes encoded documents to start with the <?xml ?> declaration
+func XMLHeader() XMLOption { return func(x *XML) { x.header = true } }
+
+//XMLRoot sets the name of the element that wraps outputs that have no name of their own, it defaults
+//to "response"
+func XMLRoot(name string) XMLOption { return func(x *XML) { x.root = name } }
+
+//XMLNamespace declares 'ns' as the default namespace of the root element, unless its name has a
+//namespace already
+func XMLNamespace(ns string) XMLOption { return func(x *XML) { x.namespace = ns } }
+
+//XMLIndent causes encoded values to be indented, see xml.Encoder.Indent
+func XMLIndent(prefix, indent string) XMLOption {
+	return func(x *XML) { x.prefix, x.indent = prefix, indent }
+}
+
+//XMLStrict configures whether decoding requires well-formed input, this is the default. When turned off
+//unclosed HTML elements and entities are accepted, see xml.Decoder.Strict
+func XMLStrict(on bool) XMLOption { return func(x *XML) { x.lenient = !on } }
+
+//XMLCharsetReader allows input in other charsets than UTF-8 to be decoded, see xml.Decoder.CharsetReader
+func XMLCharsetReader(fn func(charset string, input io.Reader) (io.Reader, error)) XMLOption {
+	return func(x *XML) { x.charsetReader = fn }
+}
+
+//NewXML creates an XML encoding with the provided options
+func NewXML(opts ...XMLOption) *XML {
+	x := &XML{}
+	for _, opt := range opts {
+		opt(x)
+	}
+
+	return x
+}
 
 //MimeType will report the EncodingMimeType
 func (e *XML) MimeType() string { return MediaTypeXML }
 
 //Encoder will create encoders
-func (e *XML) Encoder(w io.Writer) Encoder { return xml.NewEncoder(w) }
+func (e *XML) Encoder(w io.Writer) Encoder { return &xmlEncoder{e, w} }
 
 //Decoder will create decoders
-func (e *XML) Decoder(r io.Reader) Decoder { return xml.NewDecoder(r) }
+func (e *XML) Decoder(r io.Reader) Decoder {
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = e.charsetReader
+	if e.lenient {
+		dec.Strict = false
+		dec.AutoClose = xml.HTMLAutoClose
+		dec.Entity = xml.HTMLEntity
+	}
+
+	return dec
+}
+
+//xmlErr is what errors without exported fields are encoded as
+type xmlErr struct {
+	Message string `xml:"message"`
+}
+
+//xmlEncoder encodes into a buffer first such that a failing value doesn't result in half a document
+type xmlEncoder struct {
+	cfg *XML
+	w   io.Writer
+}
+
+func (e *xmlEncoder) Encode(v interface{}) (err error) {
+	buf := bytes.NewBuffer(nil)
+	if e.cfg.header {
+		buf.WriteString(xml.Header)
+	}
+
+	enc := xml.NewEncoder(buf)
+	enc.Indent(e.cfg.prefix, e.cfg.indent)
+
+	elem, start := e.cfg.element(v)
+	err = enc.EncodeElement(elem, start)
+	if err != nil {
+		return err
+	}
+
+	err = enc.Close()
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(buf.Bytes())
+	return err
+}
+
+//element returns the value to encode for output 'v' and the root element to encode it as
+func (e *XML) element(v interface{}) (interface{}, xml.StartElement) {
+	root := e.root
+	if root == "" {
+		root = "response"
+	}
+
+	start := xml.StartElement{Name: xml.Name{Space: e.namespace, Local: root}}
+	if err, ok := v.(error); ok && xmlOpaque(reflect.ValueOf(err)) {
+		start.Name.Local = "error"
+		return xmlErr{err.Error()}, start
+	}
+
+	rv := reflect.ValueOf(v)
+	if name, ok := xmlName(rv); ok {
+		if name.Space == "" {
+			name.Space = e.namespace
+		}
+
+		return v, xml.StartElement{Name: name}
+	}
+
+	return xmlItem(rv), start
+}
+
+//xmlOpaque reports whether an error value holds nothing to encode but its message
+func xmlOpaque(v reflect.Value) bool {
+	if _, ok := v.Interface().(xml.Marshaler); ok {
+		return false
+	}
+
+	return opaqueErr(v)
+}
+
+//opaqueErr reports whether an error value holds no exported fields, such that only its message can be
+//encoded
+func opaqueErr(v reflect.Value) bool {
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return true
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).IsExported() {
+			return false
+		}
+	}
+
+	return true
+}
+
+//xmlName returns the element name of named structs, taking their XMLName field into account
+func xmlName(v reflect.Value) (xml.Name, bool) {
+	for (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return xml.Name{}, false
+	}
+
+	if f, ok := v.Type().FieldByName("XMLName"); ok {
+		if tag, _, _ := strings.Cut(f.Tag.Get("xml"), ","); tag != "" {
+			if ns, local, found := strings.Cut(tag, " "); found {
+				return xml.Name{Space: ns, Local: local}, true
+			}
+
+			return xml.Name{Local: tag}, true
+		}
+
+		if name, ok := v.FieldByIndex(f.Index).Interface().(xml.Name); ok && name.Local != "" {
+			return name, true
+		}
+	}
+
+	if v.Type().Name() == "" {
+		return xml.Name{}, false
+	}
+
+	return xml.Name{Local: v.Type().Name()}, true
+}
+
+//xmlItem wraps maps and lists such that they can be encoded
+func xmlItem(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	if _, ok := v.Interface().(xml.Marshaler); ok {
+		return v.Interface()
+	}
+
+	for (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Kind() == reflect.Map:
+		return xmlMap{v}
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8:
+		return xmlList{v}
+	}
+
+	return v.Interface()
+}
+
+//xmlMap encodes map entries as elements named after their keys, in order of the keys. Keys that are not
+//valid names become an "entry" element with the key as attribute
+type xmlMap struct{ v reflect.Value }
+
+func (m xmlMap) MarshalXML(e *xml.Encoder, start xml.StartElement) (err error) {
+	keys := make([]string, 0, m.v.Len())
+	vals := make(map[string]reflect.Value, m.v.Len())
+	for iter := m.v.MapRange(); iter.Next(); {
+		key := fmt.Sprint(iter.Key().Interface())
+		keys, vals[key] = append(keys, key), iter.Value()
+	}
+
+	sort.Strings(keys)
+	err = e.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		el := xml.StartElement{Name: xml.Name{Local: key}}
+		if !xmlValidName(key) {
+			el = xml.StartElement{Name: xml.Name{Local: "entry"}, Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: key}}}
+		}
+
+		err = e.EncodeElement(xmlItem(vals[key]), el)
+		if err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+//xmlValidName reports whether 's' can be used as element name, names with a colon are not allowed as they
+//would refer to a namespace prefix
+func xmlValidName(s string) bool {
+	for i, r := range s {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+
+	return s != ""
+}
+
+//xmlList encodes list items as elements named after their type, or else as "item"
+type xmlList struct{ v reflect.Value }
+
+func (l xmlList) MarshalXML(e *xml.Encoder, start xml.StartElement) (err error) {
+	err = e.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < l.v.Len(); i++ {
+		name, ok := xmlName(l.v.Index(i))
+		if !ok {
+			name = xml.Name{Local: "item"}
+		}
+
+		err = e.EncodeElement(xmlItem(l.v.Index(i)), xml.StartElement{Name: name})
+		if err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
@@ -0,0 +1,128 @@
+package httpio_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	httpio "github.com/advanderveer/go-httpio"
+)
+
+type xmlTestItem struct {
+	Name  string `xml:"name"`
+	Price int    `xml:"price,attr"`
+}
+
+type xmlTestOrder struct {
+	XMLName xml.Name `xml:"order"`
+	ID      int      `xml:"id"`
+}
+
+type xmlTestErr struct {
+	Code string `xml:"code"`
+}
+
+func (e *xmlTestErr) Error() string { return e.Code }
+
+func TestXMLEncoding(t *testing.T) {
+	for _, c := range []struct {
+		Name   string
+		Opts   []httpio.XMLOption
+		Value  interface{}
+		Exp    string
+		ExpErr string
+	}{
+		{"named struct", nil, xmlTestItem{"a", 1}, `<xmlTestItem price="1"><name>a</name></xmlTestItem>`, ""},
+		{"xml name", nil, &xmlTestOrder{ID: 1}, `<order><id>1</id></order>`, ""},
+		{"header", []httpio.XMLOption{httpio.XMLHeader()}, &xmlTestOrder{ID: 1}, xml.Header + `<order><id>1</id></order>`, ""},
+		{"slice", nil, []xmlTestItem{{"a", 1}, {"b", 2}}, `<response><xmlTestItem price="1"><name>a</name></xmlTestItem><xmlTestItem price="2"><name>b</name></xmlTestItem></response>`, ""},
+		{"slice of basic values", []httpio.XMLOption{httpio.XMLRoot("list")}, []string{"a", "b"}, `<list><item>a</item><item>b</item></list>`, ""},
+		{"anonymous struct", nil, struct{ ID int }{1}, `<response><ID>1</ID></response>`, ""},
+		{"map", nil, map[string]interface{}{"b": []interface{}{1, map[string]int{"c": 2}}, "a": "x"}, `<response><a>x</a><b><item>1</item><item><c>2</c></item></b></response>`, ""},
+		{"map keys that are no names", nil, map[string]int{"a b": 1, "1st": 2, "x:y": 3, "ok": 4},
+			`<response><entry key="1st">2</entry><entry key="a b">1</entry><ok>4</ok><entry key="x:y">3</entry></response>`, ""},
+		{"namespace", []httpio.XMLOption{httpio.XMLNamespace("urn:shop")}, xmlTestItem{"a", 1}, `<xmlTestItem xmlns="urn:shop" price="1"><name>a</name></xmlTestItem>`, ""},
+		{"namespaced root", []httpio.XMLOption{httpio.XMLNamespace("urn:shop")}, []int{1}, `<response xmlns="urn:shop"><item>1</item></response>`, ""},
+		{"indent", []httpio.XMLOption{httpio.XMLIndent("", "  ")}, &xmlTestOrder{ID: 1}, "<order>\n  <id>1</id>\n</order>", ""},
+		{"error", nil, errors.New("oops <3"), `<error><message>oops &lt;3</message></error>`, ""},
+		{"error with fields", nil, &xmlTestErr{"E1"}, `<xmlTestErr><code>E1</code></xmlTestErr>`, ""},
+		{"unsupported", nil, struct{ C chan int }{}, "", "xml: unsupported type: chan int"},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			buf := bytes.NewBuffer(nil)
+			err := httpio.NewXML(c.Opts...).Encoder(buf).Encode(c.Value)
+			if c.ExpErr != "" {
+				if err == nil || err.Error() != c.ExpErr || buf.Len() != 0 {
+					t.Fatalf("expected error '%s' and no output, got: %v %q", c.ExpErr, err, buf.String())
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if buf.String() != c.Exp {
+				t.Fatalf("expected:\n%s\ngot:\n%s", c.Exp, buf.String())
+			}
+		})
+	}
+}
+
+func TestXMLDecoding(t *testing.T) {
+	for _, c := range []struct {
+		Name   string
+		Opts   []httpio.XMLOption
+		Input  string
+		Exp    xmlTestItem
+		ExpErr string
+	}{
+		{"strict", nil, `<item price="1"><name>a</name></item>`, xmlTestItem{"a", 1}, ""},
+		{"not well-formed", nil, `<item price=1><name>a&nbsp;</name></item>`, xmlTestItem{}, "XML syntax error on line 1: unquoted or missing attribute value in element"},
+		{"lenient", []httpio.XMLOption{httpio.XMLStrict(false)}, `<item price=1><name>a&amp;b</name></item>`, xmlTestItem{"a&b", 1}, ""},
+		{"charset without reader", nil, `<?xml version="1.0" encoding="ISO-8859-1"?><item><name>a</name></item>`, xmlTestItem{}, `xml: encoding "ISO-8859-1" declared but Decoder.CharsetReader is nil`},
+		{"charset", []httpio.XMLOption{httpio.XMLCharsetReader(func(charset string, r io.Reader) (io.Reader, error) {
+			data, err := io.ReadAll(r)
+			runes := make([]rune, len(data))
+			for i, b := range data {
+				runes[i] = rune(b) //latin-1 maps onto the first code points
+			}
+
+			return strings.NewReader(string(runes)), err
+		})}, "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><item><name>caf\xe9</name></item>", xmlTestItem{"café", 0}, ""},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			v := xmlTestItem{}
+			err := httpio.NewXML(c.Opts...).Decoder(strings.NewReader(c.Input)).Decode(&v)
+			if c.ExpErr != "" {
+				if err == nil || err.Error() != c.ExpErr {
+					t.Fatalf("expected error '%s', got: %v", c.ExpErr, err)
+				}
+
+				return
+			}
+
+			if err != nil || !reflect.DeepEqual(v, c.Exp) {
+				t.Fatalf("expected %+v, got: %+v %v", c.Exp, v, err)
+			}
+		})
+	}
+}
+
+func TestXMLRenderError(t *testing.T) {
+	e := httpio.NewEgress(&httpio.JSON{}, httpio.NewXML(httpio.XMLHeader()))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	e.MustRender(errors.New("oops"), w, r)
+	if exp := xml.Header + `<error><message>oops</message></error>`; w.Body.String() != exp || w.Header().Get("Content-Type") != "application/xml; charset=utf-8" {
+		t.Fatalf("expected %q, got: %s %q", exp, w.Header().Get("Content-Type"), w.Body.String())
+	}
+}
@@ -0,0 +1,234 @@
+//Package yaml provides an encoding factory for YAML documents, it is a separate package such that the core
+//remains free of dependencies
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	httpio "github.com/advanderveer/go-httpio"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+var (
+	//MediaTypeYAML identifies YAML content
+	MediaTypeYAML = "application/yaml"
+)
+
+//YAML allows encoding and decoding into YAML. Values are mapped through their JSON representation such
+//that the names in 'json' tags, json.Marshaler and json.Unmarshaler are honoured and the same structs can
+//be used for both formats. Duplicate mapping keys are always rejected, as required by the YAML
+//specification
+type YAML struct {
+	disallowUnknown bool
+	indent          int
+}
+
+//Option configures the YAML encoding
+type Option func(y *YAML)
+
+//DisallowUnknownFields causes decoding to fail on mapping keys that do not match a field of the destination
+func DisallowUnknownFields() Option { return func(y *YAML) { y.disallowUnknown = true } }
+
+//Strict combines all options that reject input that would otherwise be accepted silently
+func Strict() Option { return func(y *YAML) { y.disallowUnknown = true } }
+
+//Indent sets the number of spaces used for indentation of encoded documents, it defaults to 2
+func Indent(n int) Option { return func(y *YAML) { y.indent = n } }
+
+//New creates a YAML encoding with the provided options
+func New(opts ...Option) *YAML {
+	y := &YAML{}
+	for _, opt := range opts {
+		opt(y)
+	}
+
+	return y
+}
+
+//MimeType will report the EncodingMimeType
+func (e *YAML) MimeType() string { return MediaTypeYAML }
+
+//ContentType is sent without a charset, YAML media types do not define one (RFC 9512)
+func (e *YAML) ContentType() string { return MediaTypeYAML }
+
+//Encoder will create encoders, each value is written as a separate document
+func (e *YAML) Encoder(w io.Writer) httpio.Encoder { return &encoder{cfg: e, w: w} }
+
+//Decoder will create decoders, each call decodes the next document
+func (e *YAML) Decoder(r io.Reader) httpio.Decoder {
+	return &decoder{cfg: e, dec: yamlv3.NewDecoder(r)}
+}
+
+type encoder struct {
+	cfg  *YAML
+	w    io.Writer
+	docs int
+}
+
+func (e *encoder) Encode(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	node, err := toNode(dec)
+	if err != nil {
+		return err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if e.docs > 0 {
+		buf.WriteString("---\n")
+	}
+
+	enc := yamlv3.NewEncoder(buf)
+	indent := e.cfg.indent
+	if indent < 1 {
+		indent = 2
+	}
+
+	enc.SetIndent(indent)
+	err = enc.Encode(node)
+	if err != nil {
+		return err
+	}
+
+	err = enc.Close()
+	if err != nil {
+		return err
+	}
+
+	e.docs++
+	_, err = e.w.Write(buf.Bytes())
+	return err
+}
+
+//toNode builds a node from the JSON tokens of one value, unlike a map it keeps the order of the fields
+func toNode(dec *json.Decoder) (*yamlv3.Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		n := &yamlv3.Node{Kind: yamlv3.SequenceNode, Tag: "!!seq"}
+		if t == '{' {
+			n.Kind, n.Tag = yamlv3.MappingNode, "!!map"
+		}
+
+		for dec.More() {
+			if n.Kind == yamlv3.MappingNode {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+
+				n.Content = append(n.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+
+			val, err := toNode(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			n.Content = append(n.Content, val)
+		}
+
+		_, err = dec.Token() //closing delimiter
+		return n, err
+	case string:
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: t}, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(t.String(), ".eE") {
+			tag = "!!float"
+		}
+
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case bool:
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(t)}, nil
+	default:
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
+
+type decoder struct {
+	cfg *YAML
+	dec *yamlv3.Decoder
+}
+
+func (d *decoder) Decode(v interface{}) error {
+	var doc interface{}
+	err := d.dec.Decode(&doc)
+	if err != nil {
+		return err
+	}
+
+	doc, err = jsonValue(doc)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("httpio/yaml: %v", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if d.cfg.disallowUnknown {
+		dec.DisallowUnknownFields()
+	}
+
+	return dec.Decode(v)
+}
+
+//jsonValue converts mappings with non-string keys, which JSON cannot represent, into mappings keyed by the
+//text of their keys
+func jsonValue(v interface{}) (interface{}, error) {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for k, el := range vt {
+			el, err := jsonValue(el)
+			if err != nil {
+				return nil, err
+			}
+
+			vt[k] = el
+		}
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(vt))
+		for k, el := range vt {
+			switch k.(type) {
+			case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+				return nil, fmt.Errorf("httpio/yaml: cannot use a collection as mapping key")
+			}
+
+			el, err := jsonValue(el)
+			if err != nil {
+				return nil, err
+			}
+
+			m[fmt.Sprint(k)] = el
+		}
+
+		return m, nil
+	case []interface{}:
+		for i, el := range vt {
+			el, err := jsonValue(el)
+			if err != nil {
+				return nil, err
+			}
+
+			vt[i] = el
+		}
+	}
+
+	return v, nil
+}
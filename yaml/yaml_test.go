@@ -0,0 +1,145 @@
+package yaml_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	httpio "github.com/advanderveer/go-httpio"
+	"github.com/advanderveer/go-httpio/yaml"
+)
+
+type config struct {
+	Name     string            `json:"name"`
+	Replicas int               `json:"replicas,omitempty"`
+	Enabled  bool              `json:"enabled"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Ports    []int             `json:"ports"`
+	Timeout  time.Duration     `json:"timeout_ns"`
+	Parent   *config           `json:"parent,omitempty"`
+}
+
+func TestDecode(t *testing.T) {
+	for _, c := range []struct {
+		Name   string
+		Opts   []yaml.Option
+		Input  string
+		Exp    *config
+		ExpErr string
+	}{
+		{
+			Name:  "json names",
+			Input: "name: web\nreplicas: 3\nenabled: true\nports: [80, 443]\nlabels:\n  1: one\nunknown: x\n",
+			Exp:   &config{Name: "web", Replicas: 3, Enabled: true, Ports: []int{80, 443}, Labels: map[string]string{"1": "one"}},
+		},
+		{
+			Name:  "anchors",
+			Input: "base: &b\n  name: base\nname: web\nparent: *b\nenabled: true\n",
+			Exp:   &config{Name: "web", Enabled: true, Parent: &config{Name: "base"}},
+		},
+		{
+			Name:   "unknown field",
+			Opts:   []yaml.Option{yaml.Strict()},
+			Input:  "name: web\nunknown: x\n",
+			ExpErr: `json: unknown field "unknown"`,
+		},
+		{
+			Name:   "duplicate key",
+			Input:  "name: web\nname: api\n",
+			ExpErr: "yaml: unmarshal errors:\n  line 2: mapping key \"name\" already defined at line 1",
+		},
+		{
+			Name:   "type mismatch",
+			Input:  "replicas: many\n",
+			ExpErr: "json: cannot unmarshal string into Go struct field config.replicas of type int",
+		},
+	} {
+		t.Run(c.Name, func(t *testing.T) {
+			v := &config{}
+			err := yaml.New(c.Opts...).Decoder(strings.NewReader(c.Input)).Decode(v)
+			if c.ExpErr != "" {
+				if err == nil || err.Error() != c.ExpErr {
+					t.Fatalf("expected error '%s', got: %v", c.ExpErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(v, c.Exp) {
+				t.Fatalf("expected %+v, got: %+v", c.Exp, v)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	enc := yaml.New().Encoder(buf)
+	err := enc.Encode(&config{Name: "true", Replicas: 2, Ports: []int{80}, Timeout: time.Second, Parent: &config{Name: "base"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = enc.Encode([]interface{}{1.5, nil}); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `name: "true"
+replicas: 2
+enabled: false
+ports:
+  - 80
+timeout_ns: 1000000000
+parent:
+  name: base
+  enabled: false
+  ports: null
+  timeout_ns: 0
+---
+- 1.5
+- null
+`
+	if buf.String() != exp {
+		t.Fatalf("expected:\n%s\ngot:\n%s", exp, buf.String())
+	}
+
+	dec := yaml.New().Decoder(buf)
+	v := &config{}
+	if err = dec.Decode(v); err != nil || v.Name != "true" || v.Parent.Name != "base" {
+		t.Fatalf("expected first document to roundtrip, got: %+v %v", v, err)
+	}
+
+	var list []interface{}
+	if err = dec.Decode(&list); err != nil || !reflect.DeepEqual(list, []interface{}{1.5, nil}) {
+		t.Fatalf("expected second document to roundtrip, got: %v %v", list, err)
+	}
+}
+
+func TestNegotiation(t *testing.T) {
+	y := yaml.New(yaml.Strict())
+	e := httpio.NewEgress(&httpio.JSON{}, y)
+	i := httpio.NewIngress(e, &httpio.JSON{}, y)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := &config{}
+		if render, ok := i.Handle(w, r, in); ok {
+			render(in, nil)
+		}
+	})
+
+	r := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("name: web\nports: []\n"))
+	r.Header.Set("Content-Type", "application/yaml")
+	r.Header.Set("Accept", "application/yaml")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if exp := "name: web\nenabled: false\nports: []\ntimeout_ns: 0\n"; w.Body.String() != exp || w.Header().Get("Content-Type") != "application/yaml" {
+		t.Fatalf("expected %q, got: %s %q", exp, w.Header().Get("Content-Type"), w.Body.String())
+	}
+}